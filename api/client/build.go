@@ -20,6 +20,7 @@ import (
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/types"
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/archive"
@@ -69,6 +70,12 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	flBuildArg := opts.NewListOpts(opts.ValidateEnv)
 	cmd.Var(&flBuildArg, []string{"-build-arg"}, "Set build-time variables")
 	isolation := cmd.String([]string{"-isolation"}, "", "Container isolation level")
+	target := cmd.String([]string{"-target"}, "", "Set the target build stage to build")
+	validate := cmd.Bool([]string{"-validate"}, false, "Check the Dockerfile for problems without building it")
+	flCacheFrom := opts.NewListOpts(nil)
+	cmd.Var(&flCacheFrom, []string{"-cache-from"}, "Images to consider as cache sources")
+	flSecrets := opts.NewListOpts(opts.ValidateSecret)
+	cmd.Var(&flSecrets, []string{"-secret"}, "Secret file to expose to the RUN instructions (id=<id>,src=<path>)")
 
 	ulimits := make(map[string]*ulimit.Ulimit)
 	flUlimits := opts.NewUlimitOpt(&ulimits)
@@ -96,13 +103,17 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		contextDir    string
 		tempDir       string
 		relDockerfile string
+		gitRepo       *utils.GitRepo
 	)
 
 	switch {
 	case specifiedContext == "-":
 		tempDir, relDockerfile, err = getContextFromReader(cli.in, *dockerfileName)
 	case urlutil.IsGitURL(specifiedContext) && hasGit:
-		tempDir, relDockerfile, err = getContextFromGitURL(specifiedContext, *dockerfileName)
+		gitRepo, relDockerfile, err = getContextFromGitURL(specifiedContext, *dockerfileName)
+		if gitRepo != nil {
+			contextDir, tempDir = gitRepo.ContextDir, gitRepo.Root
+		}
 	case urlutil.IsURL(specifiedContext):
 		tempDir, relDockerfile, err = getContextFromURL(cli.out, specifiedContext, *dockerfileName)
 	default:
@@ -115,7 +126,13 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 
 	if tempDir != "" {
 		defer os.RemoveAll(tempDir)
-		contextDir = tempDir
+		if contextDir == "" {
+			contextDir = tempDir
+		}
+	}
+
+	if *validate {
+		return cli.validateDockerfile(filepath.Join(contextDir, relDockerfile), relDockerfile)
 	}
 
 	// Resolve the FROM lines in the Dockerfile to trusted digest references
@@ -242,6 +259,13 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		v.Set("isolation", *isolation)
 	}
 
+	if *target != "" {
+		v.Set("target", *target)
+	}
+
+	// The build steps are rendered from their progress records.
+	v.Set("progress", "json")
+
 	v.Set("cpusetcpus", *flCPUSetCpus)
 	v.Set("cpusetmems", *flCPUSetMems)
 	v.Set("cpushares", strconv.FormatInt(*flCPUShares, 10))
@@ -276,6 +300,22 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	}
 	v.Set("buildargs", string(buildArgsJSON))
 
+	if gitRepo != nil {
+		labelsJSON, err := json.Marshal(map[string]string{utils.GitCommitLabel: gitRepo.Commit})
+		if err != nil {
+			return err
+		}
+		v.Set("labels", string(labelsJSON))
+	}
+
+	if cacheFrom := flCacheFrom.GetAll(); len(cacheFrom) > 0 {
+		cacheFromJSON, err := json.Marshal(cacheFrom)
+		if err != nil {
+			return err
+		}
+		v.Set("cachefrom", string(cacheFromJSON))
+	}
+
 	headers := http.Header(make(map[string][]string))
 	buf, err := json.Marshal(cli.configFile.AuthConfigs)
 	if err != nil {
@@ -284,6 +324,14 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	headers.Add("X-Registry-Config", base64.URLEncoding.EncodeToString(buf))
 	headers.Set("Content-Type", "application/tar")
 
+	if flSecrets.Len() > 0 {
+		secrets, err := readSecrets(flSecrets.GetAll())
+		if err != nil {
+			return err
+		}
+		headers.Set("X-Build-Secrets", secrets)
+	}
+
 	sopts := &streamOpts{
 		rawTerminal: true,
 		in:          body,
@@ -326,6 +374,39 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	return nil
 }
 
+// validateDockerfile sends the Dockerfile to the daemon to be checked, and
+// prints the problems found in it. It fails if any of them would make the
+// build fail.
+func (cli *DockerCli) validateDockerfile(dockerfilePath, relDockerfile string) error {
+	f, err := os.Open(dockerfilePath)
+	if err != nil {
+		return fmt.Errorf("unable to read Dockerfile: %v", err)
+	}
+	defer f.Close()
+
+	headers := map[string][]string{"Content-Type": {"text/plain"}}
+	body, _, err := readBody(cli.clientRequest("POST", "/build/validate", f, headers))
+	if err != nil {
+		return err
+	}
+	var report types.BuildValidateReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return err
+	}
+
+	failed := false
+	for _, d := range report.Diagnostics {
+		fmt.Fprintf(cli.out, "%s:%d:%d: %s: %s (%s)\n", relDockerfile, d.Line, d.Column, d.Severity, d.Message, d.Code)
+		if d.Severity == "error" {
+			failed = true
+		}
+	}
+	if failed {
+		return Cli.StatusError{StatusCode: 1}
+	}
+	return nil
+}
+
 // validateTag checks if the given image name can be resolved.
 func validateTag(rawRepo string) (string, error) {
 	ref, err := reference.ParseNamed(rawRepo)
@@ -485,16 +566,20 @@ func getContextFromReader(r io.Reader, dockerfileName string) (absContextDir, re
 }
 
 // getContextFromGitURL uses a Git URL as context for a `docker build`. The
-// git repo is cloned into a temporary directory used as the context directory.
-// Returns the absolute path to the temporary context directory, the relative
-// path of the dockerfile in that context directory, and a non-nil error on
-// success.
-func getContextFromGitURL(gitURL, dockerfileName string) (absContextDir, relDockerfile string, err error) {
-	if absContextDir, err = utils.GitClone(gitURL); err != nil {
-		return "", "", fmt.Errorf("unable to 'git clone' to temporary context directory: %v", err)
+// git repo is cloned into a temporary directory, which or one of its
+// subdirectories is used as the context directory. Returns the clone, the
+// relative path of the dockerfile in the context directory, and a non-nil
+// error on success.
+func getContextFromGitURL(gitURL, dockerfileName string) (repo *utils.GitRepo, relDockerfile string, err error) {
+	if repo, err = utils.GitClone(gitURL); err != nil {
+		return nil, "", fmt.Errorf("unable to 'git clone' to temporary context directory: %v", err)
 	}
 
-	return getDockerfileRelPath(absContextDir, dockerfileName)
+	if repo.ContextDir, relDockerfile, err = getDockerfileRelPath(repo.ContextDir, dockerfileName); err != nil {
+		repo.Remove()
+		return nil, "", err
+	}
+	return repo, relDockerfile, nil
 }
 
 // getContextFromURL uses a remote URL as context for a `docker build`. The
@@ -699,3 +784,27 @@ func replaceDockerfileTarWrapper(inputTarStream io.ReadCloser, newDockerfile *tr
 
 	return pipeReader
 }
+
+// readSecrets reads the files of the build secrets and encodes them, by ID,
+// for the X-Build-Secrets header. The secrets are sent apart from the build
+// context so that ADD and COPY can't reach them.
+func readSecrets(values []string) (string, error) {
+	secrets := make(map[string][]byte)
+	for _, val := range values {
+		id, src, err := opts.ParseSecret(val)
+		if err != nil {
+			return "", err
+		}
+		if _, exists := secrets[id]; exists {
+			return "", fmt.Errorf("duplicate secret ID %q", id)
+		}
+		if secrets[id], err = ioutil.ReadFile(src); err != nil {
+			return "", fmt.Errorf("unable to read secret %s: %v", id, err)
+		}
+	}
+	buf, err := json.Marshal(secrets)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	flag "github.com/docker/docker/pkg/mflag"
+)
+
+// CmdCheckpoint is the parent subcommand for all checkpoint commands
+//
+// Usage: docker checkpoint <COMMAND> [OPTIONS]
+func (cli *DockerCli) CmdCheckpoint(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint", []string{"COMMAND [OPTIONS]"}, checkpointUsage(), false)
+	cmd.Require(flag.Min, 1)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdCheckpointCreate checkpoints the process running in a container
+//
+// Usage: docker checkpoint create [OPTIONS] CONTAINER CHECKPOINT
+func (cli *DockerCli) CmdCheckpointCreate(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint create", []string{"CONTAINER CHECKPOINT"}, "Create a checkpoint from a running container", false)
+	leaveRunning := cmd.Bool([]string{"-leave-running"}, false, "Leave the container running after checkpoint")
+	cmd.Require(flag.Exact, 2)
+	if err := cmd.ParseFlags(args, true); err != nil {
+		return err
+	}
+
+	options := types.CheckpointCreateOptions{
+		CheckpointID: cmd.Arg(1),
+		Exit:         !*leaveRunning,
+	}
+	if _, _, err := readBody(cli.call("POST", "/containers/"+cmd.Arg(0)+"/checkpoints", options, nil)); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", cmd.Arg(1))
+	return nil
+}
+
+// CmdCheckpointLs lists the checkpoints of a container
+//
+// Usage: docker checkpoint ls CONTAINER
+func (cli *DockerCli) CmdCheckpointLs(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint ls", []string{"CONTAINER"}, "List the checkpoints of a container", false)
+	cmd.Require(flag.Exact, 1)
+	if err := cmd.ParseFlags(args, true); err != nil {
+		return err
+	}
+
+	obj, _, err := readBody(cli.call("GET", "/containers/"+cmd.Arg(0)+"/checkpoints", nil, nil))
+	if err != nil {
+		return err
+	}
+
+	var checkpoints []types.Checkpoint
+	if err := json.Unmarshal(obj, &checkpoints); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "CHECKPOINT NAME")
+	for _, checkpoint := range checkpoints {
+		fmt.Fprintf(w, "%s\n", checkpoint.Name)
+	}
+	w.Flush()
+	return nil
+}
+
+// CmdCheckpointRm deletes checkpoints of a container
+//
+// Usage: docker checkpoint rm CONTAINER CHECKPOINT [CHECKPOINT...]
+func (cli *DockerCli) CmdCheckpointRm(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint rm", []string{"CONTAINER CHECKPOINT [CHECKPOINT...]"}, "Remove one or more checkpoints of a container", false)
+	cmd.Require(flag.Min, 2)
+	if err := cmd.ParseFlags(args, true); err != nil {
+		return err
+	}
+
+	container := cmd.Arg(0)
+	status := 0
+	for _, checkpoint := range cmd.Args()[1:] {
+		if _, _, err := readBody(cli.call("DELETE", "/containers/"+container+"/checkpoints/"+checkpoint, nil, nil)); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(cli.out, "%s\n", checkpoint)
+	}
+	if status != 0 {
+		return Cli.StatusError{StatusCode: status}
+	}
+	return nil
+}
+
+func checkpointUsage() string {
+	checkpointCommands := map[string]string{
+		"create": "Create a checkpoint from a running container",
+		"ls":     "List the checkpoints of a container",
+		"rm":     "Remove one or more checkpoints of a container",
+	}
+
+	help := "Commands:\n"
+
+	for cmd, description := range checkpointCommands {
+		help += fmt.Sprintf("  %-25.25s%s\n", cmd, description)
+	}
+
+	help += fmt.Sprintf("\nRun 'docker checkpoint COMMAND --help' for more information on a command.")
+	return help
+}
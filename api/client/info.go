@@ -57,6 +57,14 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 	}
 	fmt.Fprintf(cli.out, "\n")
 
+	if len(info.SecurityOptions) != 0 {
+		fmt.Fprintf(cli.out, "Security Options:")
+		for _, o := range info.SecurityOptions {
+			fmt.Fprintf(cli.out, " %s", o)
+		}
+		fmt.Fprintf(cli.out, "\n")
+	}
+
 	ioutils.FprintfIfNotEmpty(cli.out, "Kernel Version: %s\n", info.KernelVersion)
 	ioutils.FprintfIfNotEmpty(cli.out, "Operating System: %s\n", info.OperatingSystem)
 	ioutils.FprintfIfNotEmpty(cli.out, "OSType: %s\n", info.OSType)
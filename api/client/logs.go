@@ -2,7 +2,6 @@ package client
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"time"
 
@@ -12,11 +11,6 @@ import (
 	"github.com/docker/docker/pkg/timeutils"
 )
 
-var validDrivers = map[string]bool{
-	"json-file": true,
-	"journald":  true,
-}
-
 // CmdLogs fetches the logs of a given container.
 //
 // docker logs [OPTIONS] CONTAINER
@@ -42,10 +36,6 @@ func (cli *DockerCli) CmdLogs(args ...string) error {
 		return err
 	}
 
-	if !validDrivers[c.HostConfig.LogConfig.Type] {
-		return fmt.Errorf("\"logs\" command is supported only for \"json-file\" and \"journald\" logging drivers (got: %s)", c.HostConfig.LogConfig.Type)
-	}
-
 	v := url.Values{}
 	v.Set("stdout", "1")
 	v.Set("stderr", "1")
@@ -0,0 +1,325 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/units"
+)
+
+// CmdSystem is the parent subcommand for all system commands
+//
+// Usage: docker system <COMMAND> [OPTIONS]
+func (cli *DockerCli) CmdSystem(args ...string) error {
+	description := Cli.DockerCommands["system"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"df", "Show docker disk usage"},
+		{"prune", "Remove unused data"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker system COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("system", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdSystemDf shows the disk space used by the images, the containers and
+// the local volumes, and how much of it can be reclaimed.
+//
+// Usage: docker system df [OPTIONS]
+func (cli *DockerCli) CmdSystemDf(args ...string) error {
+	cmd := Cli.Subcmd("system df", nil, "Show docker disk usage", true)
+	verbose := cmd.Bool([]string{"v", "-verbose"}, false, "Show detailed information on space usage")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	body, _, err := readBody(cli.call("GET", "/system/df", nil, nil))
+	if err != nil {
+		return err
+	}
+	du := types.DiskUsage{}
+	if err := json.Unmarshal(body, &du); err != nil {
+		return err
+	}
+
+	if *verbose {
+		return printDiskUsageVerbose(cli.out, du)
+	}
+
+	var activeImages int
+	for _, img := range du.Images {
+		if img.Containers > 0 {
+			activeImages++
+		}
+	}
+
+	var (
+		activeContainers int
+		containersSize   int64
+		reclaimableRw    int64
+	)
+	for _, c := range du.Containers {
+		containersSize += c.SizeRw
+		if c.Running {
+			activeContainers++
+		} else {
+			reclaimableRw += c.SizeRw
+		}
+	}
+
+	var (
+		localVolumes      int
+		activeVolumes     int
+		volumesSize       int64
+		reclaimableVolume int64
+	)
+	for _, v := range du.Volumes {
+		if v.Size < 0 {
+			continue
+		}
+		localVolumes++
+		volumesSize += v.Size
+		if v.RefCount > 0 {
+			activeVolumes++
+		} else {
+			reclaimableVolume += v.Size
+		}
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	fmt.Fprintf(w, "Images\t%d\t%d\t%s\t%s\n", len(du.Images), activeImages, units.HumanSize(float64(du.LayersSize)), reclaimable(du.ReclaimableLayersSize, du.LayersSize))
+	fmt.Fprintf(w, "Containers\t%d\t%d\t%s\t%s\n", len(du.Containers), activeContainers, units.HumanSize(float64(containersSize)), reclaimable(reclaimableRw, containersSize))
+	fmt.Fprintf(w, "Local Volumes\t%d\t%d\t%s\t%s\n", localVolumes, activeVolumes, units.HumanSize(float64(volumesSize)), reclaimable(reclaimableVolume, volumesSize))
+	w.Flush()
+	return nil
+}
+
+// printDiskUsageVerbose prints the disk usage of each image, container and
+// local volume.
+func printDiskUsageVerbose(out io.Writer, du types.DiskUsage) error {
+	fmt.Fprintf(out, "Images space usage:\n\n")
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE\tSHARED SIZE\tUNIQUE SIZE\tCONTAINERS")
+	for _, img := range du.Images {
+		repoTags := img.RepoTags
+		if len(repoTags) == 0 {
+			repoTags = []string{"<none>:<none>"}
+		}
+		for _, repoTag := range repoTags {
+			repo, tag := "<none>", "<none>"
+			if repoTag != "<none>:<none>" {
+				ref, err := reference.ParseNamed(repoTag)
+				if err != nil {
+					return err
+				}
+				repo = ref.Name()
+				if tagged, ok := ref.(reference.Tagged); ok {
+					tag = tagged.Tag()
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t%s\t%d\n", repo, tag, stringid.TruncateID(img.ID),
+				units.HumanDuration(time.Now().UTC().Sub(time.Unix(img.Created, 0))),
+				units.HumanSize(float64(img.Size)), units.HumanSize(float64(img.SharedSize)),
+				units.HumanSize(float64(img.UniqueSize)), img.Containers)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nContainers space usage:\n\n")
+	w = tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCREATED\tSTATE\tSIZE\tNAMES")
+	for _, c := range du.Containers {
+		state := "stopped"
+		if c.Running {
+			state = "running"
+		}
+		var names []string
+		for _, name := range c.Names {
+			names = append(names, strings.TrimPrefix(name, "/"))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s ago\t%s\t%s\t%s\n", stringid.TruncateID(c.ID), c.Image,
+			units.HumanDuration(time.Now().UTC().Sub(time.Unix(c.Created, 0))), state,
+			units.HumanSize(float64(c.SizeRw)), strings.Join(names, ","))
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nLocal Volumes space usage:\n\n")
+	w = tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "VOLUME NAME\tLINKS\tSIZE")
+	for _, v := range du.Volumes {
+		if v.Size < 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", v.Name, v.RefCount, units.HumanSize(float64(v.Size)))
+	}
+	w.Flush()
+	return nil
+}
+
+// reclaimable formats the reclaimable space along with its share of the
+// total size.
+func reclaimable(size, total int64) string {
+	if total <= 0 {
+		return units.HumanSize(float64(size))
+	}
+	return fmt.Sprintf("%s (%d%%)", units.HumanSize(float64(size)), size*100/total)
+}
+
+// CmdSystemPrune removes the stopped containers, the networks that no
+// container uses, the dangling images and, optionally, the unused volumes.
+//
+// Usage: docker system prune [OPTIONS]
+func (cli *DockerCli) CmdSystemPrune(args ...string) error {
+	cmd := Cli.Subcmd("system prune", nil, "Remove unused data", true)
+	all := cmd.Bool([]string{"a", "-all"}, false, "Remove all unused images, not just dangling ones")
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	volumes := cmd.Bool([]string{"-volumes"}, false, "Prune volumes")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'label=<key>=<value>' or 'until=24h')")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	pruneFilters, err := parseFilters(flFilter.GetAll())
+	if err != nil {
+		return err
+	}
+
+	warning := "WARNING! This will remove:\n" +
+		"\t- all stopped containers\n" +
+		"\t- all networks not used by at least one container\n"
+	if *volumes {
+		warning += "\t- all volumes not used by at least one container\n"
+	}
+	if *all {
+		warning += "\t- all images without at least one container associated to them\n"
+	} else {
+		warning += "\t- all dangling images\n"
+	}
+	if !*force && !cli.confirm(warning+"Are you sure you want to continue?") {
+		return nil
+	}
+
+	var spaceReclaimed uint64
+
+	var containers types.ContainersPruneReport
+	if err := cli.prune("/containers/prune", pruneFilters, &containers); err != nil {
+		return err
+	}
+	printPruned(cli, "Deleted Containers", containers.ContainersDeleted)
+	spaceReclaimed += containers.SpaceReclaimed
+
+	var networks types.NetworksPruneReport
+	if err := cli.prune("/networks/prune", pruneFilters, &networks); err != nil {
+		return err
+	}
+	printPruned(cli, "Deleted Networks", networks.NetworksDeleted)
+
+	if *volumes {
+		var volumes types.VolumesPruneReport
+		if err := cli.prune("/volumes/prune", pruneFilters, &volumes); err != nil {
+			return err
+		}
+		printPruned(cli, "Deleted Volumes", volumes.VolumesDeleted)
+		spaceReclaimed += volumes.SpaceReclaimed
+	}
+
+	imageFilters, err := parseFilters(flFilter.GetAll())
+	if err != nil {
+		return err
+	}
+	if *all {
+		imageFilters.Add("dangling", "false")
+	}
+	var images types.ImagesPruneReport
+	if err := cli.prune("/images/prune", imageFilters, &images); err != nil {
+		return err
+	}
+	var deleted []string
+	for _, img := range images.ImagesDeleted {
+		if img.Untagged != "" {
+			deleted = append(deleted, "untagged: "+img.Untagged)
+		}
+		if img.Deleted != "" {
+			deleted = append(deleted, "deleted: "+img.Deleted)
+		}
+	}
+	printPruned(cli, "Deleted Images", deleted)
+	spaceReclaimed += images.SpaceReclaimed
+
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(spaceReclaimed)))
+	return nil
+}
+
+// prune calls the prune endpoint at path with the filters, and decodes the
+// report of the daemon into report.
+func (cli *DockerCli) prune(path string, pruneFilters filters.Args, report interface{}) error {
+	v := url.Values{}
+	if pruneFilters.Len() > 0 {
+		filterJSON, err := filters.ToParam(pruneFilters)
+		if err != nil {
+			return err
+		}
+		v.Set("filters", filterJSON)
+	}
+
+	body, _, err := readBody(cli.call("POST", path+"?"+v.Encode(), nil, nil))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, report)
+}
+
+// parseFilters parses the values of the --filter option.
+func parseFilters(values []string) (filters.Args, error) {
+	args := filters.NewArgs()
+	for _, f := range values {
+		var err error
+		args, err = filters.ParseFlag(f, args)
+		if err != nil {
+			return args, err
+		}
+	}
+	return args, nil
+}
+
+// confirm asks the user the question and returns whether the answer was yes.
+func (cli *DockerCli) confirm(question string) bool {
+	fmt.Fprintf(cli.out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(cli.in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func printPruned(cli *DockerCli, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(cli.out, "%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintln(cli.out, item)
+	}
+	fmt.Fprintln(cli.out)
+}
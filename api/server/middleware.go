@@ -13,6 +13,7 @@ import (
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/version"
 	"golang.org/x/net/context"
 )
@@ -86,6 +87,51 @@ func (s *Server) corsMiddleware(handler httputils.APIFunc) httputils.APIFunc {
 	}
 }
 
+// authorizationMiddleware validates the request and its response against the
+// configured authorization plugins. The user identity is taken from the TLS
+// client certificate when one was presented.
+func (s *Server) authorizationMiddleware(handler httputils.APIFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		var user, userAuthNMethod string
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			user = r.TLS.PeerCertificates[0].Subject.CommonName
+			userAuthNMethod = "TLS"
+		}
+
+		authCtx := authorization.NewCtx(s.authZPlugins, user, userAuthNMethod, r.Method, r.RequestURI)
+		if err := authCtx.AuthZRequest(w, r); err != nil {
+			logrus.Errorf("AuthZRequest for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return authorizationError(err)
+		}
+
+		rw := authorization.NewResponseModifier(w)
+		if err := handler(ctx, rw, r, vars); err != nil {
+			logrus.Errorf("Handler for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return err
+		}
+
+		if err := authCtx.AuthZResponse(rw, r); err != nil {
+			logrus.Errorf("AuthZResponse for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			if rw.Streamed() {
+				// The response already reached the client; all we can do is log.
+				return nil
+			}
+			return authorizationError(err)
+		}
+
+		return rw.FlushAll()
+	}
+}
+
+// authorizationError converts an error from the authorization plugins into
+// an API error carrying the appropriate status code.
+func authorizationError(err error) error {
+	if authorization.IsDenied(err) {
+		return errors.ErrorCodeAuthorizationDenied.WithArgs(err.Error())
+	}
+	return errors.ErrorCodeAuthorizationPlugin.WithArgs(err.Error())
+}
+
 // versionMiddleware checks the api version requirements before passing the request to the server handler.
 func versionMiddleware(handler httputils.APIFunc) httputils.APIFunc {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -128,6 +174,11 @@ func (s *Server) handleWithGlobalMiddlewares(handler httputils.APIFunc) httputil
 		s.userAgentMiddleware,
 	}
 
+	// Wrap the core middlewares so that denied requests never reach the handler.
+	if len(s.authZPlugins) > 0 {
+		middlewares = append(middlewares, s.authorizationMiddleware)
+	}
+
 	// Only want this on debug level
 	if s.cfg.Logging && logrus.GetLevel() == logrus.DebugLevel {
 		middlewares = append(middlewares, debugRequestMiddleware)
@@ -8,6 +8,7 @@ import (
 	"github.com/docker/distribution/registry/api/errcode"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/authorization"
 	"golang.org/x/net/context"
 )
 
@@ -55,3 +56,36 @@ func TestVersionMiddlewareWithErrors(t *testing.T) {
 		t.Fatalf("Expected ErrorCodeNewerClientVersion, got %v", err)
 	}
 }
+
+type denyAllPlugin struct{}
+
+func (denyAllPlugin) Name() string { return "deny-all" }
+
+func (denyAllPlugin) AuthZRequest(*authorization.Request) (*authorization.Response, error) {
+	return &authorization.Response{Allow: false, Msg: "denied"}, nil
+}
+
+func (denyAllPlugin) AuthZResponse(*authorization.Request) (*authorization.Response, error) {
+	return &authorization.Response{Allow: false, Msg: "denied"}, nil
+}
+
+func TestAuthorizationMiddlewareDenies(t *testing.T) {
+	called := false
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		called = true
+		return nil
+	}
+
+	srv := &Server{cfg: &Config{}, authZPlugins: []authorization.Plugin{denyAllPlugin{}}}
+	h := srv.authorizationMiddleware(handler)
+
+	req, _ := http.NewRequest("GET", "/containers/json", nil)
+	resp := httptest.NewRecorder()
+	err := h(context.Background(), resp, req, map[string]string{})
+	if derr, ok := err.(errcode.Error); !ok || derr.ErrorCode() != errors.ErrorCodeAuthorizationDenied {
+		t.Fatalf("Expected ErrorCodeAuthorizationDenied, got %v", err)
+	}
+	if called {
+		t.Fatal("Handler must not be called when the request is denied")
+	}
+}
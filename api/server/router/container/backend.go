@@ -8,6 +8,7 @@ import (
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/pkg/version"
 	"github.com/docker/docker/runconfig"
 )
@@ -39,9 +40,11 @@ type stateBackend interface {
 	ContainerResize(name string, height, width int) error
 	ContainerRestart(name string, seconds int) error
 	ContainerRm(name string, config *daemon.ContainerRmConfig) error
-	ContainerStart(name string, hostConfig *runconfig.HostConfig) error
+	ContainersPrune(pruneFilters filters.Args) (*types.ContainersPruneReport, error)
+	ContainerStart(name string, hostConfig *runconfig.HostConfig, checkpoint string) error
 	ContainerStop(name string, seconds int) error
 	ContainerUnpause(name string) error
+	ContainerUpdate(name string, hostConfig *runconfig.HostConfig) ([]string, error)
 	ContainerWait(name string, timeout time.Duration) (int, error)
 	Exists(id string) bool
 	IsPaused(id string) bool
@@ -51,7 +54,7 @@ type stateBackend interface {
 type monitorBackend interface {
 	ContainerChanges(name string) ([]archive.Change, error)
 	ContainerInspect(name string, size bool, version version.Version) (interface{}, error)
-	ContainerLogs(name string, config *daemon.ContainerLogsConfig) error
+	ContainerLogs(name string, config *daemon.ContainerLogsConfig, started chan struct{}) error
 	ContainerStats(name string, config *daemon.ContainerStatsConfig) error
 	ContainerTop(name string, psArgs string) (*types.ContainerProcessList, error)
 
@@ -64,6 +67,13 @@ type attachBackend interface {
 	ContainerWsAttachWithLogs(name string, c *daemon.ContainerWsAttachWithLogsConfig) error
 }
 
+// checkpointBackend includes functions to implement to provide container checkpoint functionality.
+type checkpointBackend interface {
+	CheckpointCreate(name string, config types.CheckpointCreateOptions) error
+	CheckpointDelete(name string, checkpoint string) error
+	CheckpointList(name string) ([]types.Checkpoint, error)
+}
+
 // Backend is all the methods that need to be implemented to provide container specific functionality.
 type Backend interface {
 	execBackend
@@ -71,4 +81,5 @@ type Backend interface {
 	stateBackend
 	monitorBackend
 	attachBackend
+	checkpointBackend
 }
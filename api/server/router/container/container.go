@@ -41,8 +41,10 @@ func (r *containerRouter) initRoutes() {
 		local.NewGetRoute("/containers/{name:.*}/attach/ws", r.wsContainersAttach),
 		local.NewGetRoute("/exec/{id:.*}/json", r.getExecByID),
 		local.NewGetRoute("/containers/{name:.*}/archive", r.getContainersArchive),
+		local.NewGetRoute("/containers/{name:.*}/checkpoints", r.getContainerCheckpoints),
 		// POST
 		local.NewPostRoute("/containers/create", r.postContainersCreate),
+		local.NewPostRoute("/containers/prune", r.postContainersPrune),
 		local.NewPostRoute("/containers/{name:.*}/kill", r.postContainersKill),
 		local.NewPostRoute("/containers/{name:.*}/pause", r.postContainersPause),
 		local.NewPostRoute("/containers/{name:.*}/unpause", r.postContainersUnpause),
@@ -57,9 +59,12 @@ func (r *containerRouter) initRoutes() {
 		local.NewPostRoute("/exec/{name:.*}/start", r.postContainerExecStart),
 		local.NewPostRoute("/exec/{name:.*}/resize", r.postContainerExecResize),
 		local.NewPostRoute("/containers/{name:.*}/rename", r.postContainerRename),
+		local.NewPostRoute("/containers/{name:.*}/update", r.postContainerUpdate),
+		local.NewPostRoute("/containers/{name:.*}/checkpoints", r.postContainerCheckpoint),
 		// PUT
 		local.NewPutRoute("/containers/{name:.*}/archive", r.putContainersArchive),
 		// DELETE
+		local.NewDeleteRoute("/containers/{name:.*}/checkpoints/{checkpoint:.*}", r.deleteContainerCheckpoint),
 		local.NewDeleteRoute("/containers/{name:.*}", r.deleteContainers),
 	}
 }
@@ -23,6 +23,8 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/pkg/progressreader"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/ulimit"
@@ -292,6 +294,23 @@ func (s *router) deleteImages(ctx context.Context, w http.ResponseWriter, r *htt
 	return httputils.WriteJSON(w, http.StatusOK, list)
 }
 
+func (s *router) postImagesPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	pruneReport, err := s.daemon.ImagesPrune(pruneFilters)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
+}
+
 func (s *router) getImagesByName(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	imageInspect, err := s.daemon.LookupImage(vars["name"])
 	if err != nil {
@@ -364,6 +383,12 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	buildConfig.CPUSetCpus = r.FormValue("cpusetcpus")
 	buildConfig.CPUSetMems = r.FormValue("cpusetmems")
 	buildConfig.CgroupParent = r.FormValue("cgroupparent")
+	buildConfig.Target = r.FormValue("target")
+
+	progress := r.FormValue("progress")
+	if progress != "" && progress != "plain" && progress != "json" {
+		return errf(fmt.Errorf("Unsupported progress mode: %q", progress))
+	}
 
 	if r.Form.Get("shmsize") != "" {
 		shmSize, err := strconv.ParseInt(r.Form.Get("shmsize"), 10, 64)
@@ -398,6 +423,31 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		buildConfig.BuildArgs = buildArgs
 	}
 
+	if cacheFromJSON := r.FormValue("cachefrom"); cacheFromJSON != "" {
+		var cacheFrom []string
+		if err := json.NewDecoder(strings.NewReader(cacheFromJSON)).Decode(&cacheFrom); err != nil {
+			return errf(err)
+		}
+		buildConfig.CacheFrom = cacheFrom
+	}
+
+	if labelsJSON := r.FormValue("labels"); labelsJSON != "" {
+		var labels map[string]string
+		if err := json.NewDecoder(strings.NewReader(labelsJSON)).Decode(&labels); err != nil {
+			return errf(err)
+		}
+		buildConfig.Labels = labels
+	}
+
+	if secretsEncoded := r.Header.Get("X-Build-Secrets"); secretsEncoded != "" {
+		var secrets map[string][]byte
+		secretsJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(secretsEncoded))
+		if err := json.NewDecoder(secretsJSON).Decode(&secrets); err != nil {
+			return errf(fmt.Errorf("Invalid build secrets: %v", err))
+		}
+		buildConfig.Secrets = secrets
+	}
+
 	remoteURL := r.FormValue("remote")
 
 	// Currently, only used if context is from a remote url.
@@ -416,8 +466,9 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	var (
 		context        builder.ModifiableContext
 		dockerfileName string
+		gitCommit      string
 	)
-	context, dockerfileName, err = daemonbuilder.DetectContextFromRemoteURL(r.Body, remoteURL, pReader)
+	context, dockerfileName, gitCommit, err = daemonbuilder.DetectContextFromRemoteURL(r.Body, remoteURL, pReader)
 	if err != nil {
 		return errf(err)
 	}
@@ -427,6 +478,13 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		}
 	}()
 
+	if gitCommit != "" {
+		if buildConfig.Labels == nil {
+			buildConfig.Labels = map[string]string{}
+		}
+		buildConfig.Labels[utils.GitCommitLabel] = gitCommit
+	}
+
 	uidMaps, gidMaps := s.daemon.GetUIDGIDMaps()
 	defaultArchiver := &archive.Archiver{
 		Untar:   chrootarchive.Untar,
@@ -446,6 +504,11 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	}
 	b.Stdout = &streamformatter.StdoutFormatter{Writer: output, StreamFormatter: sf}
 	b.Stderr = &streamformatter.StderrFormatter{Writer: output, StreamFormatter: sf}
+	if progress == "json" {
+		b.Progress = func(step *jsonmessage.JSONBuildStep) {
+			output.Write(sf.FormatBuildStep(step))
+		}
+	}
 
 	if closeNotifier, ok := w.(http.CloseNotifier); ok {
 		finished := make(chan struct{})
@@ -478,6 +541,20 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	return nil
 }
 
+// postBuildValidate reports the problems of the Dockerfile sent as the body
+// of the request, without building it.
+func (s *router) postBuildValidate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	diagnostics, err := dockerfile.Lint(r.Body)
+	if err != nil {
+		return err
+	}
+	report := types.BuildValidateReport{Diagnostics: diagnostics}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []types.BuildDiagnostic{}
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
 // sanitizeRepoAndTags parses the raw "t" parameter received from the client
 // to a slice of repoAndTag.
 // It also validates each repoName and tag.
@@ -10,12 +10,14 @@ import (
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/pkg/parsers/kernel"
 	"github.com/docker/docker/pkg/timeutils"
+	"github.com/docker/docker/pkg/version"
 	"github.com/docker/docker/utils"
 	"golang.org/x/net/context"
 )
@@ -53,6 +55,15 @@ func (s *router) getInfo(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return httputils.WriteJSON(w, http.StatusOK, info)
 }
 
+func (s *router) getDiskUsage(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	du, err := s.daemon.SystemDiskUsage()
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, du)
+}
+
 func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -66,10 +77,16 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 		return err
 	}
 
+	var sinceTime, untilTime time.Time
+	if since != -1 {
+		sinceTime = time.Unix(since, sinceNano)
+	}
+
 	timer := time.NewTimer(0)
 	timer.Stop()
 	if until > 0 || untilNano > 0 {
-		dur := time.Unix(until, untilNano).Sub(time.Now())
+		untilTime = time.Unix(until, untilNano)
+		dur := untilTime.Sub(time.Now())
 		timer = time.NewTimer(dur)
 	}
 
@@ -91,12 +108,13 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 	defer output.Close()
 
 	enc := json.NewEncoder(output)
+	version := httputils.VersionFromContext(ctx)
 
-	buffered, l := s.daemon.SubscribeToEvents(since, sinceNano, ef)
+	buffered, l := s.daemon.SubscribeToEvents(sinceTime, untilTime, ef)
 	defer s.daemon.UnsubscribeFromEvents(l)
 
 	for _, ev := range buffered {
-		if err := enc.Encode(ev); err != nil {
+		if err := encodeEvent(enc, ev, version); err != nil {
 			return err
 		}
 	}
@@ -109,11 +127,11 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 	for {
 		select {
 		case ev := <-l:
-			jev, ok := ev.(*jsonmessage.JSONMessage)
+			jev, ok := ev.(events.Message)
 			if !ok {
 				continue
 			}
-			if err := enc.Encode(jev); err != nil {
+			if err := encodeEvent(enc, jev, version); err != nil {
 				return err
 			}
 		case <-timer.C:
@@ -124,3 +142,21 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 		}
 	}
 }
+
+// encodeEvent writes ev to enc. Clients of API versions older than 1.22 only
+// know about container and image events, in the legacy format.
+func encodeEvent(enc *json.Encoder, ev events.Message, version version.Version) error {
+	if version.LessThan("1.22") {
+		if ev.Type != events.ContainerEventType && ev.Type != events.ImageEventType {
+			return nil
+		}
+		return enc.Encode(&jsonmessage.JSONMessage{
+			Status:   ev.Status,
+			ID:       ev.ID,
+			From:     ev.From,
+			Time:     ev.Time,
+			TimeNano: ev.TimeNano,
+		})
+	}
+	return enc.Encode(ev)
+}
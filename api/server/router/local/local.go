@@ -97,6 +97,7 @@ func (r *router) initRoutes() {
 		NewGetRoute("/_ping", pingHandler),
 		NewGetRoute("/events", r.getEvents),
 		NewGetRoute("/info", r.getInfo),
+		NewGetRoute("/system/df", r.getDiskUsage),
 		NewGetRoute("/version", r.getVersion),
 		NewGetRoute("/images/json", r.getImagesJSON),
 		NewGetRoute("/images/search", r.getImagesSearch),
@@ -108,8 +109,10 @@ func (r *router) initRoutes() {
 		NewPostRoute("/auth", r.postAuth),
 		NewPostRoute("/commit", r.postCommit),
 		NewPostRoute("/build", r.postBuild),
+		NewPostRoute("/build/validate", r.postBuildValidate),
 		NewPostRoute("/images/create", r.postImagesCreate),
 		NewPostRoute("/images/load", r.postImagesLoad),
+		NewPostRoute("/images/prune", r.postImagesPrune),
 		NewPostRoute("/images/{name:.*}/push", r.postImagesPush),
 		NewPostRoute("/images/{name:.*}/tag", r.postImagesTag),
 		// DELETE
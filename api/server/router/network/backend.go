@@ -1,9 +1,11 @@
 package network
 
 import (
+	"github.com/docker/docker/api/types"
 	// TODO: network config needs to be refactored out to a
 	// different location
 	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/pkg/parsers/filters"
 
 	"github.com/docker/libnetwork"
 )
@@ -16,8 +18,10 @@ type Backend interface {
 	GetNetworksByID(partialID string) []libnetwork.Network
 	CreateNetwork(name, driver string, ipam network.IPAM,
 		options map[string]string) (libnetwork.Network, error)
+	DeleteNetwork(networkID string) error
 	ConnectContainerToNetwork(containerName, networkName string) error
 	DisconnectContainerFromNetwork(containerName string,
 		network libnetwork.Network) error
 	NetworkControllerEnabled() bool
+	NetworksPrune(pruneFilters filters.Args) (*types.NetworksPruneReport, error)
 }
@@ -39,6 +39,8 @@ func (r *networkRouter) initRoutes() {
 		local.NewPostRoute("/networks/create", r.controllerEnabledMiddleware(r.postNetworkCreate)),
 		local.NewPostRoute("/networks/{id:.*}/connect", r.controllerEnabledMiddleware(r.postNetworkConnect)),
 		local.NewPostRoute("/networks/{id:.*}/disconnect", r.controllerEnabledMiddleware(r.postNetworkDisconnect)),
+		// Pruning reports nothing when the network controller is disabled.
+		local.NewPostRoute("/networks/prune", r.postNetworksPrune),
 		// DELETE
 		local.NewDeleteRoute("/networks/{id:.*}", r.controllerEnabledMiddleware(r.deleteNetwork)),
 	}
@@ -168,7 +168,7 @@ func (n *networkRouter) deleteNetwork(ctx context.Context, w http.ResponseWriter
 			fmt.Sprintf("%s is a pre-defined network and cannot be removed", nw.Name()))
 	}
 
-	return nw.Delete()
+	return n.backend.DeleteNetwork(nw.ID())
 }
 
 func buildNetworkResource(nw libnetwork.Network) *types.NetworkResource {
@@ -253,3 +253,20 @@ func buildEndpointResource(e libnetwork.Endpoint) types.EndpointResource {
 	}
 	return er
 }
+
+func (n *networkRouter) postNetworksPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	pruneReport, err := n.backend.NetworksPrune(pruneFilters)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
+}
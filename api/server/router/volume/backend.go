@@ -3,6 +3,7 @@ package volume
 import (
 	// TODO return types need to be refactored into pkg
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/parsers/filters"
 )
 
 // Backend is the methods that need to be implemented to provide
@@ -13,4 +14,5 @@ type Backend interface {
 	VolumeCreate(name, driverName string,
 		opts map[string]string) (*types.Volume, error)
 	VolumeRm(name string) error
+	VolumesPrune(pruneFilters filters.Args) (*types.VolumesPruneReport, error)
 }
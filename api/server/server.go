@@ -15,6 +15,7 @@ import (
 	"github.com/docker/docker/api/server/router/network"
 	"github.com/docker/docker/api/server/router/volume"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/sockets"
 	"github.com/docker/docker/utils"
 	"github.com/gorilla/mux"
@@ -34,13 +35,18 @@ type Config struct {
 	SocketGroup string
 	TLSConfig   *tls.Config
 	Addrs       []Addr
+
+	// AuthZPluginNames is the ordered list of authorization plugins
+	// consulted for every API request.
+	AuthZPluginNames []string
 }
 
 // Server contains instance details for the server
 type Server struct {
-	cfg     *Config
-	servers []*HTTPServer
-	routers []router.Router
+	cfg          *Config
+	servers      []*HTTPServer
+	routers      []router.Router
+	authZPlugins []authorization.Plugin
 }
 
 // Addr contains string representation of address and its protocol (tcp, unix...).
@@ -53,7 +59,8 @@ type Addr struct {
 // It allocates resources which will be needed for ServeAPI(ports, unix-sockets).
 func New(cfg *Config) (*Server, error) {
 	s := &Server{
-		cfg: cfg,
+		cfg:          cfg,
+		authZPlugins: authorization.NewPlugins(cfg.AuthZPluginNames),
 	}
 	for _, addr := range cfg.Addrs {
 		srv, err := s.newServer(addr.Proto, addr.Addr)
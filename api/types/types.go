@@ -21,6 +21,26 @@ type ContainerCreateResponse struct {
 	Warnings []string `json:"Warnings"`
 }
 
+// ContainerUpdateResponse contains the information returned to a client on the
+// update of a container.
+type ContainerUpdateResponse struct {
+	// Warnings are any warnings encountered during the update of the container.
+	Warnings []string `json:"Warnings"`
+}
+
+// CheckpointCreateOptions holds parameters to create a checkpoint from a container
+// POST "/containers/{name:.*}/checkpoints"
+type CheckpointCreateOptions struct {
+	CheckpointID string
+	Exit         bool
+}
+
+// Checkpoint contains the details of a checkpoint, returned by Remote API:
+// GET "/containers/{name:.*}/checkpoints"
+type Checkpoint struct {
+	Name string
+}
+
 // ContainerExecCreateResponse contains response of Remote API:
 // POST "/containers/{name:.*}/exec"
 type ContainerExecCreateResponse struct {
@@ -226,6 +246,7 @@ type Info struct {
 	ServerVersion      string
 	ClusterStore       string
 	ClusterAdvertise   string
+	SecurityOptions    []string
 }
 
 // PluginsInfo is temp struct holds Plugins name
@@ -260,6 +281,30 @@ type ContainerState struct {
 	Error      string
 	StartedAt  string
 	FinishedAt string
+	Health     *Health `json:",omitempty"`
+}
+
+// Health states
+const (
+	NoHealthcheck = "none"      // Indicates there is no healthcheck
+	Starting      = "starting"  // Starting indicates that the container is not yet ready
+	Healthy       = "healthy"   // Healthy indicates that the container is running correctly
+	Unhealthy     = "unhealthy" // Unhealthy indicates that the container has a problem
+)
+
+// Health stores information about the container's healthcheck results
+type Health struct {
+	Status        string               // Status is one of Starting, Healthy or Unhealthy
+	FailingStreak int                  // FailingStreak is the number of consecutive failures
+	Log           []*HealthcheckResult // Log contains the last few results (oldest first)
+}
+
+// HealthcheckResult stores information about a single run of a healthcheck probe
+type HealthcheckResult struct {
+	Start    time.Time // Start is the time this check started
+	End      time.Time // End is the time this check ended
+	ExitCode int       // ExitCode meanings: 0=healthy, 1=unhealthy, 2=reserved (considered unhealthy), else=error running probe
+	Output   string    // Output from last check
 }
 
 // ContainerJSONBase contains response of Remote API:
@@ -275,6 +320,7 @@ type ContainerJSONBase struct {
 	HostnamePath    string
 	HostsPath       string
 	LogPath         string
+	LogDropped      uint64
 	Name            string
 	RestartCount    int
 	Driver          string
@@ -405,3 +451,92 @@ type NetworkConnect struct {
 type NetworkDisconnect struct {
 	Container string
 }
+
+// ContainersPruneReport contains the response for the remote API:
+// POST "/containers/prune"
+type ContainersPruneReport struct {
+	ContainersDeleted []string // ContainersDeleted is the list of IDs of the removed containers
+	SpaceReclaimed    uint64   // SpaceReclaimed is the size of the writable layers of the removed containers
+}
+
+// ImagesPruneReport contains the response for the remote API:
+// POST "/images/prune"
+type ImagesPruneReport struct {
+	ImagesDeleted  []ImageDelete // ImagesDeleted lists the untagged references and the removed images and layers
+	SpaceReclaimed uint64        // SpaceReclaimed is the size of the removed layers
+}
+
+// VolumesPruneReport contains the response for the remote API:
+// POST "/volumes/prune"
+type VolumesPruneReport struct {
+	VolumesDeleted []string // VolumesDeleted is the list of names of the removed volumes
+	SpaceReclaimed uint64   // SpaceReclaimed is the size of the removed local volumes
+}
+
+// NetworksPruneReport contains the response for the remote API:
+// POST "/networks/prune"
+type NetworksPruneReport struct {
+	NetworksDeleted []string // NetworksDeleted is the list of names of the removed networks
+}
+
+// DiskUsage contains the response for the remote API:
+// GET "/system/df"
+type DiskUsage struct {
+	LayersSize            int64                 // LayersSize is the size of the image layers, counting each shared layer once
+	ReclaimableLayersSize int64                 // ReclaimableLayersSize is the size of the layers that no image used by a container references
+	Images                []*ImageDiskUsage     // Images is the disk usage of the images
+	Containers            []*ContainerDiskUsage // Containers is the disk usage of the containers
+	Volumes               []*VolumeDiskUsage    // Volumes is the disk usage of the volumes
+}
+
+// ImageDiskUsage is the disk usage of an image, as returned by the remote API:
+// GET "/system/df"
+type ImageDiskUsage struct {
+	ID         string `json:"Id"`
+	RepoTags   []string
+	Created    int64
+	Size       int64 // Size is the size of all the layers of the image
+	SharedSize int64 // SharedSize is the size of the layers shared with other images
+	UniqueSize int64 // UniqueSize is the size of the layers that only this image uses
+	Containers int   // Containers is the number of containers using the image
+}
+
+// ContainerDiskUsage is the disk usage of a container, as returned by the
+// remote API:
+// GET "/system/df"
+type ContainerDiskUsage struct {
+	ID         string `json:"Id"`
+	Names      []string
+	Image      string
+	Created    int64
+	Running    bool
+	SizeRw     int64 // SizeRw is the size of the writable layer of the container
+	SizeRootFs int64 // SizeRootFs is the size of the image layers and the writable layer
+}
+
+// VolumeDiskUsage is the disk usage of a volume, as returned by the remote API:
+// GET "/system/df"
+type VolumeDiskUsage struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Size       int64 // Size is the size of the volume, or -1 if the driver is not local
+	RefCount   int   // RefCount is the number of containers referencing the volume
+}
+
+// BuildValidateReport contains the response for the remote API:
+// POST "/build/validate"
+type BuildValidateReport struct {
+	Diagnostics []BuildDiagnostic // Diagnostics lists the problems found in the Dockerfile, by line
+}
+
+// BuildDiagnostic is a problem found in a Dockerfile, as returned by the
+// remote API:
+// POST "/build/validate"
+type BuildDiagnostic struct {
+	Line     int    // Line is the line of the problem, from 1
+	Column   int    // Column is the column of the problem in the line, from 1
+	Severity string // Severity is "error" if the build would fail, "warning" otherwise
+	Code     string // Code identifies the kind of problem, like "unknown-instruction"
+	Message  string
+}
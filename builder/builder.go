@@ -107,6 +107,13 @@ func (fi *HashedFileInfo) SetHash(h string) {
 	fi.FileHash = h
 }
 
+// Ownership is the owner of the files copied into a container, as seen from
+// inside the container.
+type Ownership struct {
+	UID int
+	GID int
+}
+
 // Docker abstracts calls to a Docker Daemon.
 type Docker interface {
 	// TODO: use digest reference instead of name
@@ -126,11 +133,12 @@ type Docker interface {
 	// Commit creates a new Docker image from an existing Docker container.
 	Commit(string, *daemon.ContainerCommitConfig) (string, error)
 	// Copy copies/extracts a source FileInfo to a destination path inside a container
-	// specified by a container object.
+	// specified by a container object. The copied files are owned by `owner`, or by
+	// the root user of the container if it is nil.
 	// TODO: make an Extract method instead of passing `decompress`
 	// TODO: do not pass a FileInfo, instead refactor the archive package to export a Walk function that can be used
 	// with Context.Walk
-	Copy(c *container.Container, destPath string, src FileInfo, decompress bool) error
+	Copy(c *container.Container, destPath string, src FileInfo, decompress bool, owner *Ownership) error
 
 	// Retain retains an image avoiding it to be removed or overwritten until a corresponding Release() call.
 	// TODO: remove
@@ -155,3 +163,12 @@ type ImageCache interface {
 	// and runconfig equals `cfg`. A cache miss is expected to return an empty ID and a nil error.
 	GetCachedImage(parentID string, cfg *runconfig.Config) (imageID string, err error)
 }
+
+// ImageCacheBuilder makes image caches that also look up the build steps in
+// the history of other images.
+type ImageCacheBuilder interface {
+	// MakeImageCache returns an ImageCache that, on top of the local build
+	// cache, looks up the build steps in the history of the images
+	// referenced by `cacheFrom`.
+	MakeImageCache(cacheFrom []string) ImageCache
+}
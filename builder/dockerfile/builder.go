@@ -6,13 +6,18 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/builder"
+	"github.com/docker/docker/builder/dockerfile/command"
 	"github.com/docker/docker/builder/dockerfile/parser"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/runconfig"
@@ -25,6 +30,7 @@ var validCommitCommands = map[string]bool{
 	"expose":     true,
 	"label":      true,
 	"onbuild":    true,
+	"shell":      true,
 	"user":       true,
 	"volume":     true,
 	"workdir":    true,
@@ -54,6 +60,10 @@ type Config struct {
 	Pull        bool
 	BuildArgs   map[string]string // build-time args received in build context for expansion/substitution and commands in 'run'.
 	Isolation   runconfig.IsolationLevel
+	Target      string            // name of the build stage to stop at, the last stage if empty
+	CacheFrom   []string          // images whose history can be used as build cache
+	Secrets     map[string][]byte // files only available to the RUN steps, by ID
+	Labels      map[string]string // labels added to the image after the last step
 
 	// resource constraints
 	// TODO: factor out to be reused with Run ?
@@ -78,8 +88,13 @@ type Builder struct {
 	Stdout io.Writer
 	Stderr io.Writer
 
-	docker  builder.Docker
-	context builder.Context
+	// Progress, if set, receives a record when each step of the Dockerfile
+	// starts and ends, in place of the step lines written to Stdout.
+	Progress func(*jsonmessage.JSONBuildStep)
+
+	docker     builder.Docker
+	context    builder.Context
+	imageCache builder.ImageCache
 
 	dockerfile       *parser.Node
 	runConfig        *runconfig.Config // runconfig for cmd, run, entrypoint etc.
@@ -93,7 +108,14 @@ type Builder struct {
 	cacheBusted      bool
 	cancelled        chan struct{}
 	cancelOnce       sync.Once
-	allowedBuildArgs map[string]bool // list of build-time args that are allowed for expansion/substitution and passing to commands in 'run'.
+	allowedBuildArgs map[string]bool   // list of build-time args that are allowed for expansion/substitution and passing to commands in 'run'.
+	stageStarted     bool              // whether a FROM started a build stage
+	stageName        string            // name of the current build stage, if any
+	stageNames       map[string]string // image IDs of the completed named build stages
+	stageImages      []string          // image IDs of the completed build stages, by index
+	secretsDir       string            // host directory of the secrets, mounted while a RUN step runs
+
+	step *jsonmessage.JSONBuildStep // record of the current step, if Progress is set
 
 	// TODO: remove once docker.Commit can receive a tag
 	id           string
@@ -121,6 +143,7 @@ func NewBuilder(config *Config, docker builder.Docker, context builder.Context,
 		cancelled:        make(chan struct{}),
 		id:               stringid.GenerateNonCryptoID(),
 		allowedBuildArgs: make(map[string]bool),
+		stageNames:       make(map[string]string),
 	}
 	if dockerfile != nil {
 		b.dockerfile, err = parser.Parse(dockerfile)
@@ -151,6 +174,12 @@ func (b *Builder) Build() (string, error) {
 		b.docker.Release(b.id, b.activeImages)
 	}()
 
+	if cb, ok := b.docker.(builder.ImageCacheBuilder); ok && len(b.CacheFrom) > 0 {
+		b.imageCache = cb.MakeImageCache(b.CacheFrom)
+	} else if c, ok := b.docker.(builder.ImageCache); ok {
+		b.imageCache = c
+	}
+
 	// If Dockerfile was not parsed yet, extract it from the Context
 	if b.dockerfile == nil {
 		if err := b.readDockerfile(); err != nil {
@@ -158,8 +187,24 @@ func (b *Builder) Build() (string, error) {
 		}
 	}
 
+	for id := range b.Secrets {
+		if err := opts.ValidateSecretID(id); err != nil {
+			return "", err
+		}
+	}
+
+	target := strings.ToLower(b.Target)
+	if target != "" && !hasStage(b.dockerfile, target) {
+		return "", fmt.Errorf("failed to reach build target %s in Dockerfile", b.Target)
+	}
+
 	var shortImgID string
+	var lastStep int
 	for i, n := range b.dockerfile.Children {
+		// A FROM ends the current stage, stop there if it is the target.
+		if n.Value == command.From && target != "" && b.stageStarted && b.stageName == target {
+			break
+		}
 		select {
 		case <-b.cancelled:
 			logrus.Debug("Builder: build cancelled!")
@@ -168,19 +213,45 @@ func (b *Builder) Build() (string, error) {
 		default:
 			// Not cancelled yet, keep going...
 		}
-		if err := b.dispatch(i, n); err != nil {
+		if b.Progress != nil {
+			b.step = &jsonmessage.JSONBuildStep{Step: i + 1, Instruction: n.Original}
+		}
+		lastStep = i + 1
+		start := time.Now()
+		err := b.dispatch(i, n)
+		b.endStep(time.Since(start), err)
+		if err != nil {
 			if b.ForceRemove {
 				b.clearTmp()
 			}
 			return "", err
 		}
 		shortImgID = stringid.TruncateID(b.image)
-		fmt.Fprintf(b.Stdout, " ---> %s\n", shortImgID)
+		if b.Progress == nil {
+			fmt.Fprintf(b.Stdout, " ---> %s\n", shortImgID)
+		}
 		if b.Remove {
 			b.clearTmp()
 		}
 	}
 
+	if len(b.Labels) > 0 && b.image != "" {
+		// The labels are reported as a step of their own after the last one.
+		if b.Progress != nil {
+			b.step = &jsonmessage.JSONBuildStep{Step: lastStep + 1}
+		}
+		start := time.Now()
+		err := b.addLabels()
+		b.endStep(time.Since(start), err)
+		if err != nil {
+			return "", err
+		}
+		shortImgID = stringid.TruncateID(b.image)
+		if b.Progress == nil {
+			fmt.Fprintf(b.Stdout, " ---> %s\n", shortImgID)
+		}
+	}
+
 	// check if there are any leftover build-args that were passed but not
 	// consumed during build. Return an error, if there are any.
 	leftoverArgs := []string{}
@@ -201,6 +272,71 @@ func (b *Builder) Build() (string, error) {
 	return b.image, nil
 }
 
+// startStep reports the start of the current step, described by msg.
+// Without progress records, or for the ONBUILD triggers run as part of a
+// FROM step, the step line is printed instead.
+func (b *Builder) startStep(stepN int, msg string) {
+	if b.step == nil {
+		fmt.Fprintf(b.Stdout, "Step %d : %s\n", stepN+1, msg)
+		return
+	}
+	b.step.Message = msg
+	step := *b.step
+	b.Progress(&step)
+}
+
+// endStep reports the end of the current step, which took d and failed with
+// err if not nil.
+func (b *Builder) endStep(d time.Duration, err error) {
+	if b.step == nil {
+		return
+	}
+	step := *b.step
+	b.step = nil
+	step.Done = true
+	step.Duration = int64(d)
+	if err != nil {
+		step.Error = err.Error()
+	} else {
+		step.ImageID = b.image
+	}
+	b.Progress(&step)
+}
+
+// addLabels adds the labels of the build to the image, as a LABEL
+// instruction after the last step would. The labels are not expanded like
+// the arguments of the instructions of the Dockerfile.
+func (b *Builder) addLabels() error {
+	keys := make([]string, 0, len(b.Labels))
+	for k := range b.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, k, b.Labels[k])
+	}
+	if b.step != nil {
+		b.startStep(b.step.Step-1, "LABEL "+strings.Join(args, " "))
+	}
+	b.flags = NewBFlags()
+	return label(b, args, nil, "")
+}
+
+// hasStage returns whether one of the FROM instructions of the Dockerfile
+// starts a build stage called name.
+func hasStage(dockerfile *parser.Node, name string) bool {
+	for _, n := range dockerfile.Children {
+		if n.Value != command.From || n.Next == nil || n.Next.Next == nil || n.Next.Next.Next == nil {
+			continue
+		}
+		if strings.EqualFold(n.Next.Next.Value, "as") && strings.ToLower(n.Next.Next.Next.Value) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Cancel cancels an ongoing Dockerfile build.
 func (b *Builder) Cancel() {
 	b.cancelOnce.Do(func() {
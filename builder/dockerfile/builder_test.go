@@ -0,0 +1,412 @@
+package dockerfile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/builder"
+	"github.com/docker/docker/builder/dockerfile/parser"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/streamformatter"
+)
+
+func TestHasStage(t *testing.T) {
+	dockerfile := "FROM busybox AS Build\nRUN true\nFROM busybox as test\nFROM scratch\n"
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"build", "test"} {
+		if !hasStage(ast, name) {
+			t.Fatalf("expected stage %s to be found", name)
+		}
+	}
+	for _, name := range []string{"busybox", "scratch", "as"} {
+		if hasStage(ast, name) {
+			t.Fatalf("expected stage %s not to be found", name)
+		}
+	}
+}
+
+func TestFromInvalidStageName(t *testing.T) {
+	cases := [][]string{
+		{"busybox", "AS"},
+		{"busybox", "TO", "build"},
+		{"busybox", "AS", "1build"},
+		{"busybox", "AS", "build!"},
+		{"busybox", "AS", "build", "extra"},
+	}
+	for _, args := range cases {
+		b, err := NewBuilder(nil, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.flags = NewBFlags()
+		if err := from(b, args, nil, ""); err == nil {
+			t.Fatalf("expected an error for FROM %s", strings.Join(args, " "))
+		}
+	}
+}
+
+func TestFromDuplicateStageName(t *testing.T) {
+	b, err := NewBuilder(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.flags = NewBFlags()
+	if err := from(b, []string{"scratch", "AS", "build"}, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	b.flags = NewBFlags()
+	if err := from(b, []string{"scratch", "as", "BUILD"}, nil, ""); err == nil {
+		t.Fatal("expected an error for a duplicate stage name")
+	}
+}
+
+func TestSecretsMountPoint(t *testing.T) {
+	rootfs, err := ioutil.TempDir("", "builder-rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootfs)
+
+	if err := os.Mkdir(filepath.Join(rootfs, "run"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	dirs := missingDirs(rootfs, secretsPath)
+	if len(dirs) != 1 || dirs[0] != filepath.Join(rootfs, "run", "secrets") {
+		t.Fatalf("expected only the secrets directory to be missing, got %v", dirs)
+	}
+
+	// The mount point is removed, but not the existing directories.
+	if err := os.MkdirAll(dirs[0], 0755); err != nil {
+		t.Fatal(err)
+	}
+	removeMountPoint(dirs)
+	if _, err := os.Stat(dirs[0]); !os.IsNotExist(err) {
+		t.Fatalf("expected the mount point to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootfs, "run")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Directories the step added files to are kept.
+	if err := os.RemoveAll(filepath.Join(rootfs, "run")); err != nil {
+		t.Fatal(err)
+	}
+	dirs = missingDirs(rootfs, secretsPath)
+	if len(dirs) != 2 {
+		t.Fatalf("expected two missing directories, got %v", dirs)
+	}
+	if err := os.MkdirAll(dirs[0], 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(rootfs, "run", "app.pid"), []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	removeMountPoint(dirs)
+	if _, err := os.Stat(dirs[0]); !os.IsNotExist(err) {
+		t.Fatalf("expected the mount point to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootfs, "run", "app.pid")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShell(t *testing.T) {
+	b, err := NewBuilder(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.disableCommit = true
+
+	b.flags = NewBFlags()
+	if err := shell(b, []string{"/bin/bash", "-c"}, nil, ""); err == nil {
+		t.Fatal("expected an error for SHELL not in JSON form")
+	}
+	b.flags = NewBFlags()
+	if err := shell(b, []string{}, map[string]bool{"json": true}, ""); err == nil {
+		t.Fatal("expected an error for SHELL without arguments")
+	}
+
+	b.flags = NewBFlags()
+	if err := shell(b, []string{"/bin/bash", "-c"}, map[string]bool{"json": true}, ""); err != nil {
+		t.Fatal(err)
+	}
+	b.flags = NewBFlags()
+	if err := entrypoint(b, []string{"echo hi"}, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/bin/bash", "-c", "echo hi"}
+	actual := b.runConfig.Entrypoint.Slice()
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected the entrypoint %v, got %v", expected, actual)
+	}
+	if shell := b.runConfig.Shell.Slice(); len(shell) != 2 || shell[0] != "/bin/bash" {
+		t.Fatalf("expected the shell to be kept in the config, got %v", shell)
+	}
+}
+
+func TestLookupOwnership(t *testing.T) {
+	rootfs, err := ioutil.TempDir("", "builder-rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootfs)
+
+	if err := os.Mkdir(filepath.Join(rootfs, "etc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	passwd := "root:x:0:0:root:/root:/bin/sh\napp:x:1000:1000::/home/app:/bin/sh\n"
+	if err := ioutil.WriteFile(filepath.Join(rootfs, "etc", "passwd"), []byte(passwd), 0644); err != nil {
+		t.Fatal(err)
+	}
+	group := "root:x:0:\nstaff:x:50:app\n"
+	if err := ioutil.WriteFile(filepath.Join(rootfs, "etc", "group"), []byte(group), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := map[string][2]int{
+		"app":        {1000, 1000},
+		"app:staff":  {1000, 50},
+		"app:0":      {1000, 0},
+		"1001":       {1001, 1001},
+		"1001:staff": {1001, 50},
+		"1001:1002":  {1001, 1002},
+	}
+	for chown, expected := range valid {
+		owner, err := lookupOwnership(rootfs, chown)
+		if err != nil {
+			t.Fatalf("expected --chown=%s to resolve, got %v", chown, err)
+		}
+		if owner.UID != expected[0] || owner.GID != expected[1] {
+			t.Fatalf("expected %d:%d for --chown=%s, got %d:%d", expected[0], expected[1], chown, owner.UID, owner.GID)
+		}
+	}
+
+	for _, chown := range []string{"", ":staff", "app:", "nobody", "app:wheel", "-1"} {
+		if _, err := lookupOwnership(rootfs, chown); err == nil {
+			t.Fatalf("expected an error for --chown=%s", chown)
+		}
+	}
+}
+
+func TestBuildStepProgress(t *testing.T) {
+	ast, err := parser.Parse(strings.NewReader("label  a=b\nlabel c=d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBuilder(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	b.Stdout = &out
+	b.disableCommit = true
+	b.image = "sha256:0123456789abcdef"
+
+	var steps []jsonmessage.JSONBuildStep
+	b.Progress = func(step *jsonmessage.JSONBuildStep) {
+		steps = append(steps, *step)
+	}
+	b.step = &jsonmessage.JSONBuildStep{Step: 1, Instruction: ast.Children[0].Original}
+	err = b.dispatch(0, ast.Children[0])
+	b.endStep(time.Second, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(steps) != 2 {
+		t.Fatalf("expected a start and an end record, got %v", steps)
+	}
+	if s := steps[0]; s.Done || s.Step != 1 || s.Instruction != "label  a=b" || s.Message != "LABEL a b" {
+		t.Fatalf("unexpected start record %+v", s)
+	}
+	if s := steps[1]; !s.Done || s.ImageID != b.image || s.Duration != int64(time.Second) || s.Error != "" {
+		t.Fatalf("unexpected end record %+v", s)
+	}
+	if b.step != nil {
+		t.Fatal("expected the current step to be reset")
+	}
+
+	// Steps dispatched outside of a reported step, such as the ONBUILD
+	// triggers, are printed.
+	if err := b.dispatch(1, ast.Children[1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || out.String() != "Step 2 : LABEL c d\n" {
+		t.Fatalf("expected the step to be printed, got %q and %v", out.String(), steps)
+	}
+}
+
+func TestAddLabels(t *testing.T) {
+	b, err := NewBuilder(&Config{Labels: map[string]string{"commit": "$HOME", "b": "c\\"}}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.disableCommit = true
+	b.runConfig.Labels = map[string]string{"a": "b"}
+
+	if err := b.addLabels(); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"a": "b", "b": "c\\", "commit": "$HOME"}
+	if !reflect.DeepEqual(b.runConfig.Labels, expected) {
+		t.Fatalf("expected the labels %v, got %v", expected, b.runConfig.Labels)
+	}
+}
+
+func TestAddLabelsProgress(t *testing.T) {
+	b, err := NewBuilder(&Config{Labels: map[string]string{"commit": "0123"}}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	b.Stdout = &out
+	b.disableCommit = true
+	b.image = "sha256:0123456789abcdef"
+
+	var steps []jsonmessage.JSONBuildStep
+	b.Progress = func(step *jsonmessage.JSONBuildStep) {
+		steps = append(steps, *step)
+	}
+	b.step = &jsonmessage.JSONBuildStep{Step: 3}
+	err = b.addLabels()
+	b.endStep(time.Second, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(steps) != 2 {
+		t.Fatalf("expected a start and an end record, got %v", steps)
+	}
+	if s := steps[0]; s.Done || s.Step != 3 || s.Message != "LABEL commit 0123" {
+		t.Fatalf("unexpected start record %+v", s)
+	}
+	if s := steps[1]; !s.Done || s.ImageID != b.image {
+		t.Fatalf("unexpected end record %+v", s)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing to be printed, got %q", out.String())
+	}
+}
+
+func TestRemoteFileCache(t *testing.T) {
+	c := newRemoteFileCache(2)
+	c.add("http://a", remoteFile{etag: "a"})
+	c.add("http://b", remoteFile{etag: "b"})
+	if _, ok := c.get("http://a"); !ok {
+		t.Fatal("expected http://a to be cached")
+	}
+	// http://b is the least recently used.
+	c.add("http://c", remoteFile{etag: "c"})
+	if _, ok := c.get("http://b"); ok {
+		t.Fatal("expected http://b to be evicted")
+	}
+	c.add("http://a", remoteFile{etag: "a2"})
+	for url, etag := range map[string]string{"http://a": "a2", "http://c": "c"} {
+		if f, ok := c.get(url); !ok || f.etag != etag {
+			t.Fatalf("expected %s to be cached with the ETag %s, got %+v", url, etag, f)
+		}
+	}
+	if c.lru.Len() != 2 || len(c.files) != 2 {
+		t.Fatalf("expected 2 cached files, got %d and %d", c.lru.Len(), len(c.files))
+	}
+}
+
+func TestDownloadConditional(t *testing.T) {
+	var downloads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"hello"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"hello"`)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	srcURL := server.URL + "/file"
+
+	b, err := NewBuilder(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Stdout = &streamformatter.StdoutFormatter{Writer: ioutil.Discard, StreamFormatter: streamformatter.NewStreamFormatter()}
+
+	checksum := "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	remoteFiles.add(srcURL, remoteFile{etag: `"hello"`, digest: digest.Digest(checksum), hash: "tarsum+sha256:0123"})
+
+	// Unchanged, the file is known by its hash without being downloaded.
+	for _, sum := range []string{"", checksum} {
+		fi, err := b.download(srcURL, sum, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Path() != "" || fi.Name() != "file" || fi.(builder.Hashed).Hash() != "tarsum+sha256:0123" || downloads != 0 {
+			t.Fatalf("expected the file not to be downloaded again, got %q after %d downloads", fi.Path(), downloads)
+		}
+	}
+
+	// Another checksum than the one of the previous download is verified.
+	sum := "sha256:" + strings.Repeat("0", 64)
+	if _, err := b.download(srcURL, sum, true); err == nil || !strings.Contains(err.Error(), "Checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if downloads != 1 {
+		t.Fatalf("expected the file to be downloaded, got %d downloads", downloads)
+	}
+}
+
+func TestLint(t *testing.T) {
+	dockerfile := `FROM busybox
+MAINTAINER someone
+ONBUILD RUN true
+FROM busybox
+ARG VERSION
+ENV HOME=/root
+WORKDIR /$VERSION/$HOME/${OTHER:-default}/\$ESCAPED/$UNDEFINED
+COPY --form=build /a /b
+FETCH http://example.com /
+CMD ["echo", "hi]
+SHELL /bin/bash -c
+ONBUILD ONBUILD RUN true
+ONBUILD FROM busybox
+ENV A
+`
+	diagnostics, err := Lint(strings.NewReader(dockerfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, fmt.Sprintf("%d:%d %s %s", d.Line, d.Column, d.Severity, d.Code))
+	}
+	expected := []string{
+		"2:1 warning deprecated-instruction",
+		"3:1 warning unreachable-onbuild",
+		"7:53 warning undefined-arg",
+		"8:6 error invalid-flag",
+		"9:1 error unknown-instruction",
+		"10:5 warning invalid-json",
+		"11:1 error invalid-json",
+		"12:1 error invalid-onbuild",
+		"13:1 error invalid-onbuild",
+		"14:1 error parse-error",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected diagnostics %q, got %q", expected, got)
+	}
+}
@@ -15,16 +15,19 @@ import (
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
 	derr "github.com/docker/docker/errors"
-	"github.com/docker/docker/image"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/nat"
 	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/stringutils"
 	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/pkg/urlutil"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -34,6 +37,9 @@ const (
 	NoBaseImageSpecifier string = "scratch"
 )
 
+// validStageName matches the names that FROM ... AS accepts for build stages.
+var validStageName = regexp.MustCompile(`^[a-z][a-z0-9-_\.]*$`)
+
 // dispatch with no layer / parsing. This is effectively not a command.
 func nullDispatch(b *Builder, args []string, attributes map[string]bool, original string) error {
 	return nil
@@ -151,52 +157,93 @@ func label(b *Builder, args []string, attributes map[string]bool, original strin
 	return b.commit("", b.runConfig.Cmd, commitStr)
 }
 
-// ADD foo /path
+// ADD [--chown=user:group] foo /path
 //
 // Add the file 'foo' to '/path'. Tarball and Remote URL (git, http) handling
 // exist here. If you do not wish to have this automatic handling, use COPY.
+// With --chown, the added files are owned by the given user and group instead
+// of root.
 //
 func add(b *Builder, args []string, attributes map[string]bool, original string) error {
 	if len(args) < 2 {
 		return derr.ErrorCodeAtLeastTwoArgs.WithArgs("ADD")
 	}
 
+	flChown := b.flags.AddString("chown", "")
+	flChecksum := b.flags.AddString("checksum", "")
+
 	if err := b.flags.Parse(); err != nil {
 		return err
 	}
 
-	return b.runContextCommand(args, true, true, "ADD")
+	if flChecksum.Value != "" {
+		if len(args) != 2 || !urlutil.IsURL(args[0]) {
+			return fmt.Errorf("ADD --checksum requires a single URL source")
+		}
+		if dgst, err := digest.ParseDigest(flChecksum.Value); err != nil || dgst.Algorithm() != digest.SHA256 {
+			return fmt.Errorf("Invalid checksum %q, expected sha256:<hex>", flChecksum.Value)
+		}
+	}
+
+	return b.runContextCommand(args, true, true, "ADD", nil, flChown.Value, flChecksum.Value)
 }
 
-// COPY foo /path
+// COPY [--from=stage] [--chown=user:group] foo /path
 //
-// Same as 'ADD' but without the tar and remote url handling.
+// Same as 'ADD' but without the tar and remote url handling. With --from,
+// the files are copied from a previous build stage or from an image instead
+// of the build context.
 //
 func dispatchCopy(b *Builder, args []string, attributes map[string]bool, original string) error {
 	if len(args) < 2 {
 		return derr.ErrorCodeAtLeastTwoArgs.WithArgs("COPY")
 	}
 
+	flFrom := b.flags.AddString("from", "")
+	flChown := b.flags.AddString("chown", "")
+
 	if err := b.flags.Parse(); err != nil {
 		return err
 	}
 
-	return b.runContextCommand(args, false, false, "COPY")
+	if flFrom.Value != "" {
+		return b.runCopyFrom(args, flFrom.Value, flChown.Value)
+	}
+	return b.runContextCommand(args, false, false, "COPY", nil, flChown.Value, "")
 }
 
-// FROM imagename
+// FROM imagename [AS stagename]
 //
-// This sets the image the dockerfile will build on top of.
+// This sets the image the dockerfile will build on top of. Every FROM but
+// the first one ends the current build stage and starts a new one, which can
+// be named so that later stages can refer to it.
 //
 func from(b *Builder, args []string, attributes map[string]bool, original string) error {
-	if len(args) != 1 {
-		return derr.ErrorCodeExactlyOneArg.WithArgs("FROM")
+	var stageName string
+	switch {
+	case len(args) == 1:
+	case len(args) == 3 && strings.EqualFold(args[1], "as"):
+		stageName = strings.ToLower(args[2])
+		if !validStageName.MatchString(stageName) {
+			return fmt.Errorf("invalid name for build stage: %q, name can't start with a number or contain symbols", args[2])
+		}
+		if _, ok := b.stageNames[stageName]; ok || stageName == b.stageName {
+			return fmt.Errorf("duplicate name for build stage: %q", args[2])
+		}
+	default:
+		return fmt.Errorf("FROM requires either one argument, or three: FROM <image> AS <name>")
 	}
 
 	if err := b.flags.Parse(); err != nil {
 		return err
 	}
 
+	if b.stageStarted {
+		b.endStage()
+	}
+	b.stageStarted = true
+	b.stageName = stageName
+
 	name := args[0]
 
 	// Windows cannot support a container with no base image.
@@ -209,20 +256,9 @@ func from(b *Builder, args []string, attributes map[string]bool, original string
 		return nil
 	}
 
-	var (
-		image *image.Image
-		err   error
-	)
-	// TODO: don't use `name`, instead resolve it to a digest
-	if !b.Pull {
-		image, err = b.docker.LookupImage(name)
-		// TODO: shouldn't we error out if error is different from "not found" ?
-	}
-	if image == nil {
-		image, err = b.docker.Pull(name)
-		if err != nil {
-			return err
-		}
+	image, err := b.stageOrImage(name)
+	if err != nil {
+		return err
 	}
 	return b.processImageFrom(image)
 }
@@ -308,11 +344,7 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 	args = handleJSONArgs(args, attributes)
 
 	if !attributes["json"] {
-		if runtime.GOOS != "windows" {
-			args = append([]string{"/bin/sh", "-c"}, args...)
-		} else {
-			args = append([]string{"cmd", "/S", "/C"}, args...)
-		}
+		args = append(b.runConfig.GetShell(), args...)
 	}
 
 	runCmd := flag.NewFlagSet("run", flag.ContinueOnError)
@@ -394,6 +426,16 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 
 	logrus.Debugf("[BUILDER] Command to be executed: %v", b.runConfig.Cmd)
 
+	// The secrets are not part of the run config, so that they are neither
+	// used to look up the cache nor recorded in the image.
+	if len(b.Secrets) > 0 {
+		unmount, err := b.mountSecrets()
+		if err != nil {
+			return err
+		}
+		defer unmount()
+	}
+
 	c, err := b.create()
 	if err != nil {
 		return err
@@ -404,11 +446,19 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 	b.docker.Mount(c)
 	defer b.docker.Unmount(c)
 
+	var mountPoint []string
+	if len(b.Secrets) > 0 {
+		mountPoint = missingDirs(c.BaseFS, secretsPath)
+	}
+
 	err = b.run(c)
 	if err != nil {
 		return err
 	}
 
+	// Leave no trace of the secrets in the layer of the step.
+	removeMountPoint(mountPoint)
+
 	// revert to original config environment and set the command string to
 	// have the build-time env vars in it (if any) so that future cache look-ups
 	// properly match it.
@@ -434,11 +484,7 @@ func cmd(b *Builder, args []string, attributes map[string]bool, original string)
 	cmdSlice := handleJSONArgs(args, attributes)
 
 	if !attributes["json"] {
-		if runtime.GOOS != "windows" {
-			cmdSlice = append([]string{"/bin/sh", "-c"}, cmdSlice...)
-		} else {
-			cmdSlice = append([]string{"cmd", "/S", "/C"}, cmdSlice...)
-		}
+		cmdSlice = append(b.runConfig.GetShell(), cmdSlice...)
 	}
 
 	b.runConfig.Cmd = stringutils.NewStrSlice(cmdSlice...)
@@ -456,8 +502,8 @@ func cmd(b *Builder, args []string, attributes map[string]bool, original string)
 
 // ENTRYPOINT /usr/sbin/nginx
 //
-// Set the entrypoint (which defaults to sh -c on linux, or cmd /S /C on Windows) to
-// /usr/sbin/nginx. Will accept the CMD as the arguments to /usr/sbin/nginx.
+// Set the entrypoint (which defaults to sh -c on linux, or cmd /S /C on Windows,
+// unless SHELL changed it) to /usr/sbin/nginx. Will accept the CMD as the arguments to /usr/sbin/nginx.
 //
 // Handles command processing similar to CMD and RUN, only b.runConfig.Entrypoint
 // is initialized at NewBuilder time instead of through argument parsing.
@@ -478,11 +524,7 @@ func entrypoint(b *Builder, args []string, attributes map[string]bool, original
 		b.runConfig.Entrypoint = nil
 	default:
 		// ENTRYPOINT echo hi
-		if runtime.GOOS != "windows" {
-			b.runConfig.Entrypoint = stringutils.NewStrSlice("/bin/sh", "-c", parsed[0])
-		} else {
-			b.runConfig.Entrypoint = stringutils.NewStrSlice("cmd", "/S", "/C", parsed[0])
-		}
+		b.runConfig.Entrypoint = stringutils.NewStrSlice(append(b.runConfig.GetShell(), parsed[0])...)
 	}
 
 	// when setting the entrypoint if a CMD was not explicitly set then
@@ -604,6 +646,110 @@ func stopSignal(b *Builder, args []string, attributes map[string]bool, original
 	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("STOPSIGNAL %v", args))
 }
 
+// HEALTHCHECK foo
+//
+// Set the default healthcheck command to run in the container (which may be empty).
+// Argument handling is the same as RUN.
+//
+func healthcheck(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("HEALTHCHECK requires an argument")
+	}
+	typ := strings.ToUpper(args[0])
+	args = args[1:]
+	if typ == "NONE" {
+		if len(args) != 0 {
+			return fmt.Errorf("HEALTHCHECK NONE takes no arguments")
+		}
+		if err := b.flags.Parse(); err != nil {
+			return err
+		}
+		b.runConfig.Healthcheck = &runconfig.HealthConfig{
+			Test: []string{typ},
+		}
+	} else {
+		if b.runConfig.Healthcheck != nil {
+			oldCmd := b.runConfig.Healthcheck.Test
+			if len(oldCmd) > 0 && oldCmd[0] != "NONE" {
+				fmt.Fprintf(b.Stdout, "Note: overriding previous HEALTHCHECK: %v\n", oldCmd)
+			}
+		}
+
+		healthcheck := runconfig.HealthConfig{}
+
+		flInterval := b.flags.AddString("interval", "")
+		flTimeout := b.flags.AddString("timeout", "")
+		flRetries := b.flags.AddString("retries", "")
+
+		if err := b.flags.Parse(); err != nil {
+			return err
+		}
+
+		switch typ {
+		case "CMD":
+			cmdSlice := handleJSONArgs(args, attributes)
+			if len(cmdSlice) == 0 {
+				return fmt.Errorf("Missing command after HEALTHCHECK CMD")
+			}
+
+			if !attributes["json"] {
+				typ = "CMD-SHELL"
+			}
+
+			healthcheck.Test = append([]string{typ}, cmdSlice...)
+		default:
+			return fmt.Errorf("Unknown type %#v in HEALTHCHECK (try CMD)", typ)
+		}
+
+		interval, err := parseOptInterval(flInterval)
+		if err != nil {
+			return err
+		}
+		healthcheck.Interval = interval
+
+		timeout, err := parseOptInterval(flTimeout)
+		if err != nil {
+			return err
+		}
+		healthcheck.Timeout = timeout
+
+		if flRetries.Value != "" {
+			retries, err := strconv.ParseInt(flRetries.Value, 10, 32)
+			if err != nil {
+				return err
+			}
+			if retries < 1 {
+				return fmt.Errorf("--retries must be at least 1 (not %d)", retries)
+			}
+			healthcheck.Retries = int(retries)
+		} else {
+			healthcheck.Retries = 0
+		}
+
+		b.runConfig.Healthcheck = &healthcheck
+	}
+
+	hc := b.runConfig.Healthcheck
+	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("HEALTHCHECK --interval=%v --timeout=%v --retries=%d %q", hc.Interval, hc.Timeout, hc.Retries, hc.Test))
+}
+
+// parseOptInterval parses the duration given to a HEALTHCHECK flag. An empty
+// value means that the default should be inherited.
+func parseOptInterval(f *Flag) (time.Duration, error) {
+	s := f.Value
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("Interval %#v must be positive", f.name)
+	}
+	return d, nil
+}
+
 // ARG name[=value]
 //
 // Adds the variable foo to the trusted list of variables that can be passed
@@ -647,3 +793,25 @@ func arg(b *Builder, args []string, attributes map[string]bool, original string)
 
 	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("ARG %s", arg))
 }
+
+// SHELL ["/bin/bash", "-c"]
+//
+// Set the shell the shell form of RUN, CMD, ENTRYPOINT and HEALTHCHECK runs
+// with. It is stored in the image configuration, so it is inherited by the
+// images built from it.
+func shell(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if err := b.flags.Parse(); err != nil {
+		return err
+	}
+
+	shellSlice := handleJSONArgs(args, attributes)
+	switch {
+	case len(shellSlice) == 0:
+		return fmt.Errorf("SHELL requires at least one argument")
+	case !attributes["json"]:
+		return fmt.Errorf("SHELL requires the arguments to be in JSON form")
+	}
+
+	b.runConfig.Shell = stringutils.NewStrSlice(shellSlice...)
+	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("SHELL %q", shellSlice))
+}
@@ -4,6 +4,7 @@ package dockerfile
 // non-contiguous functionality. Please read the comments.
 
 import (
+	"container/list"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -15,10 +16,13 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/builder/dockerfile/parser"
@@ -26,17 +30,18 @@ import (
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
-	"github.com/docker/docker/pkg/httputils"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/progressreader"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/pkg/urlutil"
 	"github.com/docker/docker/runconfig"
+	lcUser "github.com/opencontainers/runc/libcontainer/user"
 )
 
 func (b *Builder) commit(id string, autoCmd *stringutils.StrSlice, comment string) error {
@@ -97,12 +102,182 @@ func (b *Builder) commit(id string, autoCmd *stringutils.StrSlice, comment strin
 type copyInfo struct {
 	builder.FileInfo
 	decompress bool
+	remote     string // URL the file still has to be downloaded from, if any
 }
 
-func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalDecompression bool, cmdName string) error {
-	if b.context == nil {
+// lookupOwnership resolves the user and group of --chown, in the form
+// user[:group], against the /etc/passwd and /etc/group files of the root
+// filesystem rootfs. Numeric IDs are used as is. Without a group, the group ID
+// is the user ID.
+func lookupOwnership(rootfs, chown string) (*builder.Ownership, error) {
+	parts := strings.SplitN(chown, ":", 2)
+	if parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+		return nil, fmt.Errorf("Invalid --chown value %q, expected user[:group]", chown)
+	}
+
+	uid, err := lookupID(rootfs, "/etc/passwd", parts[0], func(r io.Reader, name string) (int, bool, error) {
+		users, err := lcUser.ParsePasswdFilter(r, func(u lcUser.User) bool { return u.Name == name })
+		if err != nil || len(users) == 0 {
+			return 0, false, err
+		}
+		return users[0].Uid, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gid := uid
+	if len(parts) == 2 {
+		gid, err = lookupID(rootfs, "/etc/group", parts[1], func(r io.Reader, name string) (int, bool, error) {
+			groups, err := lcUser.ParseGroupFilter(r, func(g lcUser.Group) bool { return g.Name == name })
+			if err != nil || len(groups) == 0 {
+				return 0, false, err
+			}
+			return groups[0].Gid, true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &builder.Ownership{UID: uid, GID: gid}, nil
+}
+
+// lookupID returns the numeric ID of name, either name itself if it is a
+// number or the ID find looks up in the file at path in rootfs.
+func lookupID(rootfs, path, name string, find func(r io.Reader, name string) (int, bool, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		if id < 0 {
+			return 0, fmt.Errorf("Invalid ID %d for --chown", id)
+		}
+		return id, nil
+	}
+
+	fullPath, err := symlink.FollowSymlinkInScope(filepath.Join(rootfs, path), rootfs)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to look up %s for --chown: %v", name, err)
+	}
+	defer f.Close()
+
+	id, found, err := find(f, name)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to look up %s for --chown: %v", name, err)
+	}
+	if !found {
+		return 0, fmt.Errorf("Unable to find %s in %s for --chown", name, path)
+	}
+	return id, nil
+}
+
+// secretsPath is the directory of the build secrets in the containers of the
+// RUN steps.
+const secretsPath = "/run/secrets"
+
+// copySource is the root filesystem of an image that COPY --from reads
+// from instead of the build context.
+type copySource struct {
+	context builder.Context
+	imageID string
+}
+
+// endStage records the image of the current build stage for the stages
+// that follow, and resets the builder for the next stage.
+func (b *Builder) endStage() {
+	b.stageImages = append(b.stageImages, b.image)
+	if b.stageName != "" {
+		b.stageNames[b.stageName] = b.image
+	}
+	b.runConfig = new(runconfig.Config)
+	b.image = ""
+	b.noBaseImage = false
+	b.maintainer = ""
+	b.cmdSet = false
+	b.cacheBusted = false
+}
+
+// stageOrImage returns the image of the completed build stage called name,
+// or with name as index, and otherwise the image called name, which is
+// pulled if needed.
+func (b *Builder) stageOrImage(name string) (*image.Image, error) {
+	id, ok := b.stageNames[strings.ToLower(name)]
+	if !ok {
+		if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(b.stageImages) {
+			id, ok = b.stageImages[i], true
+		}
+	}
+	if ok {
+		if id == "" {
+			return nil, fmt.Errorf("build stage %s has no image", name)
+		}
+		return b.docker.LookupImage(id)
+	}
+
+	var (
+		img *image.Image
+		err error
+	)
+	// TODO: don't use `name`, instead resolve it to a digest
+	if !b.Pull {
+		img, err = b.docker.LookupImage(name)
+		// TODO: shouldn't we error out if error is different from "not found" ?
+	}
+	if img == nil {
+		img, err = b.docker.Pull(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// runCopyFrom runs COPY --from, reading the files from the root filesystem
+// of a previous build stage or of an image.
+func (b *Builder) runCopyFrom(args []string, from, chown string) error {
+	img, err := b.stageOrImage(from)
+	if err != nil {
+		return err
+	}
+	imageID := img.ID().String()
+
+	// The container is never started, it only gives access to the root
+	// filesystem of the image.
+	config := &runconfig.Config{Image: imageID}
+	if runtime.GOOS != "windows" {
+		config.Cmd = stringutils.NewStrSlice("/bin/sh", "-c", "#(nop) COPY --from="+from)
+	} else {
+		config.Cmd = stringutils.NewStrSlice("cmd", "/S", "/C", "REM (nop) COPY --from="+from)
+	}
+	c, _, err := b.docker.Create(config, nil)
+	if err != nil {
+		return err
+	}
+	defer b.removeContainer(c.ID)
+	defer b.docker.Unmount(c)
+
+	source := &copySource{
+		context: builder.MakeRootFSContext(c.BaseFS),
+		imageID: imageID,
+	}
+	return b.runContextCommand(args, false, false, "COPY", source, chown, "")
+}
+
+// runContextCommand runs ADD or COPY, which copy files from the build
+// context, or from source if it isn't nil. The copied files are owned by the
+// user and group of chown, if it is set.
+func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalDecompression bool, cmdName string, source *copySource, chown, checksum string) error {
+	context := b.context
+	if source != nil {
+		context = source.context
+	}
+	if context == nil {
 		return fmt.Errorf("No context given. Impossible to use %s", cmdName)
 	}
+	if chown != "" && runtime.GOOS == "windows" {
+		return fmt.Errorf("%s --chown is not supported on Windows", cmdName)
+	}
 
 	if len(args) < 2 {
 		return fmt.Errorf("Invalid %s format - at least two arguments required", cmdName)
@@ -126,17 +301,23 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 			if !allowRemote {
 				return fmt.Errorf("Source can't be a URL for %s", cmdName)
 			}
-			fi, err = b.download(orig)
+			fi, err = b.download(orig, checksum, true)
 			if err != nil {
 				return err
 			}
+			if fi.Path() == "" {
+				// Not modified, the file is only downloaded if the step
+				// isn't cached.
+				infos = append(infos, copyInfo{fi, false, orig})
+				continue
+			}
 			defer os.RemoveAll(filepath.Dir(fi.Path()))
 			decompress = false
-			infos = append(infos, copyInfo{fi, decompress})
+			infos = append(infos, copyInfo{fi, decompress, ""})
 			continue
 		}
 		// not a URL
-		subInfos, err := b.calcCopyInfo(cmdName, orig, allowLocalDecompression, true)
+		subInfos, err := b.calcCopyInfo(context, cmdName, orig, allowLocalDecompression, true)
 		if err != nil {
 			return err
 		}
@@ -177,6 +358,17 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 		srcHash = "multi:" + hex.EncodeToString(hasher.Sum(nil))
 		origPaths = strings.Join(origs, " ")
 	}
+	if source != nil {
+		// The files of an image are only identified by their path, which
+		// is enough along with the ID of the image.
+		srcHash = "from:" + source.imageID + ":" + srcHash
+	}
+
+	// The ownership is part of the cache key. The names it is given with
+	// always resolve to the same IDs on top of the same image.
+	if chown != "" {
+		cmdName += " --chown=" + chown
+	}
 
 	cmd := b.runConfig.Cmd
 	if runtime.GOOS != "windows" {
@@ -192,12 +384,37 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 		return nil
 	}
 
+	for i, info := range infos {
+		if info.remote == "" {
+			continue
+		}
+		fi, err := b.download(info.remote, checksum, false)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(filepath.Dir(fi.Path()))
+		if fi.(builder.Hashed).Hash() != info.FileInfo.(builder.Hashed).Hash() {
+			return fmt.Errorf("%s changed while building the step, try again", info.remote)
+		}
+		infos[i] = copyInfo{fi, false, ""}
+	}
+
 	container, _, err := b.docker.Create(b.runConfig, nil)
 	if err != nil {
 		return err
 	}
 	defer b.docker.Unmount(container)
 	b.tmpContainers[container.ID] = struct{}{}
+	if b.step != nil {
+		b.step.ContainerID = container.ID
+	}
+
+	var owner *builder.Ownership
+	if chown != "" {
+		if owner, err = lookupOwnership(container.BaseFS, chown); err != nil {
+			return err
+		}
+	}
 
 	comment := fmt.Sprintf("%s %s in %s", cmdName, origPaths, dest)
 
@@ -214,7 +431,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 	}
 
 	for _, info := range infos {
-		if err := b.docker.Copy(container, dest, info.FileInfo, info.decompress); err != nil {
+		if err := b.docker.Copy(container, dest, info.FileInfo, info.decompress, owner); err != nil {
 			return err
 		}
 	}
@@ -225,7 +442,77 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 	return nil
 }
 
-func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
+// remoteFile records a file downloaded by ADD, to look up the cache of later
+// steps adding it without downloading it again as long as the validators of
+// the response show that it didn't change.
+type remoteFile struct {
+	etag         string
+	lastModified string
+	digest       digest.Digest // digest of the content
+	hash         string        // hash of the file as the source of a step
+}
+
+// maxRemoteFiles is the number of downloaded files remembered by
+// remoteFiles.
+const maxRemoteFiles = 1024
+
+// remoteFiles are the files last downloaded by ADD, by URL.
+var remoteFiles = newRemoteFileCache(maxRemoteFiles)
+
+// remoteFileCache remembers the most recently used downloaded files, up to a
+// maximum number of files.
+type remoteFileCache struct {
+	sync.Mutex
+	max   int
+	lru   *list.List // URLs, the most recently used first
+	files map[string]*list.Element
+}
+
+type remoteFileEntry struct {
+	url  string
+	file remoteFile
+}
+
+func newRemoteFileCache(max int) *remoteFileCache {
+	return &remoteFileCache{max: max, lru: list.New(), files: make(map[string]*list.Element)}
+}
+
+// get returns the file downloaded from srcURL, if any.
+func (c *remoteFileCache) get(srcURL string) (remoteFile, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.files[srcURL]
+	if !ok {
+		return remoteFile{}, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*remoteFileEntry).file, true
+}
+
+// add records the file downloaded from srcURL, forgetting the least recently
+// used file if there are too many.
+func (c *remoteFileCache) add(srcURL string, f remoteFile) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.files[srcURL]; ok {
+		e.Value.(*remoteFileEntry).file = f
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.files[srcURL] = c.lru.PushFront(&remoteFileEntry{url: srcURL, file: f})
+	if c.lru.Len() > c.max {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.files, e.Value.(*remoteFileEntry).url)
+	}
+}
+
+// download downloads srcURL into a temporary directory. If checksum is set,
+// the content must have that digest. Unless conditional is false, the request
+// is conditional on the file having changed since a previous download, in
+// which case the returned file is only known by its hash and still has to be
+// downloaded to be copied.
+func (b *Builder) download(srcURL, checksum string, conditional bool) (fi builder.FileInfo, err error) {
 	// get filename from URL
 	u, err := url.Parse(srcURL)
 	if err != nil {
@@ -242,11 +529,34 @@ func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
 		return
 	}
 
+	req, err := http.NewRequest("GET", srcURL, nil)
+	if err != nil {
+		return
+	}
+	previous, downloaded := remoteFiles.get(srcURL)
+	if conditional && downloaded && (checksum == "" || previous.digest == digest.Digest(checksum)) {
+		if previous.etag != "" {
+			req.Header.Set("If-None-Match", previous.etag)
+		}
+		if previous.lastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.lastModified)
+		}
+	}
+
 	// Initiate the download
-	resp, err := httputils.Download(srcURL)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return
 	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		logrus.Debugf("[BUILDER] %s not modified since the last download", srcURL)
+		return &builder.HashedFileInfo{FileInfo: builder.PathFileInfo{FileName: filename}, FileHash: previous.hash}, nil
+	}
+	if resp.StatusCode >= 400 {
+		err = fmt.Errorf("Got HTTP status code >= 400: %s", resp.Status)
+		return
+	}
 
 	// Prepare file in a tmp dir
 	tmpDir, err := ioutils.TempDir("", "docker-remote")
@@ -264,8 +574,10 @@ func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
 		return
 	}
 
-	// Download and dump result to tmp file
-	if _, err = io.Copy(tmpFile, progressreader.New(progressreader.Config{
+	// Download and dump result to tmp file, computing the digest of the
+	// content on the way
+	digester := digest.Canonical.New()
+	if _, err = io.Copy(io.MultiWriter(tmpFile, digester.Hash()), progressreader.New(progressreader.Config{
 		In: resp.Body,
 		// TODO: make progressreader streamformatter agnostic
 		Out:       b.Stdout.(*streamformatter.StdoutFormatter).Writer,
@@ -279,6 +591,11 @@ func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
 		return
 	}
 	fmt.Fprintln(b.Stdout)
+	if checksum != "" && digester.Digest() != digest.Digest(checksum) {
+		tmpFile.Close()
+		err = fmt.Errorf("Checksum mismatch for %s: expected %s, got %s", srcURL, checksum, digester.Digest())
+		return
+	}
 	// ignoring error because the file was already opened successfully
 	tmpFileSt, err := tmpFile.Stat()
 	if err != nil {
@@ -317,10 +634,14 @@ func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
 	}
 	hash := tarSum.Sum(nil)
 	r.Close()
+
+	if etag := resp.Header.Get("ETag"); etag != "" || lastMod != "" {
+		remoteFiles.add(srcURL, remoteFile{etag: etag, lastModified: lastMod, digest: digester.Digest(), hash: hash})
+	}
 	return &builder.HashedFileInfo{FileInfo: builder.PathFileInfo{FileInfo: tmpFileSt, FilePath: tmpFileName}, FileHash: hash}, nil
 }
 
-func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression, allowWildcards bool) ([]copyInfo, error) {
+func (b *Builder) calcCopyInfo(context builder.Context, cmdName, origPath string, allowLocalDecompression, allowWildcards bool) ([]copyInfo, error) {
 
 	// Work in daemon-specific OS filepath semantics
 	origPath = filepath.FromSlash(origPath)
@@ -333,7 +654,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 	// Deal with wildcards
 	if allowWildcards && containsWildcards(origPath) {
 		var copyInfos []copyInfo
-		if err := b.context.Walk("", func(path string, info builder.FileInfo, err error) error {
+		if err := context.Walk("", func(path string, info builder.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -347,7 +668,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 
 			// Note we set allowWildcards to false in case the name has
 			// a * in it
-			subInfos, err := b.calcCopyInfo(cmdName, path, allowLocalDecompression, false)
+			subInfos, err := b.calcCopyInfo(context, cmdName, path, allowLocalDecompression, false)
 			if err != nil {
 				return err
 			}
@@ -361,7 +682,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 
 	// Must be a dir or a file
 
-	statPath, fi, err := b.context.Stat(origPath)
+	statPath, fi, err := context.Stat(origPath)
 	if err != nil {
 		return nil, err
 	}
@@ -380,7 +701,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 	}
 	// Must be a dir
 	var subfiles []string
-	err = b.context.Walk(statPath, func(path string, info builder.FileInfo, err error) error {
+	err = context.Walk(statPath, func(path string, info builder.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -437,6 +758,11 @@ func (b *Builder) processImageFrom(img *image.Image) error {
 	onBuildTriggers := b.runConfig.OnBuild
 	b.runConfig.OnBuild = []string{}
 
+	// The triggers are reported as part of the FROM step.
+	current := b.step
+	b.step = nil
+	defer func() { b.step = current }()
+
 	// parse the ONBUILD triggers by invoking the parser
 	for _, step := range onBuildTriggers {
 		ast, err := parser.Parse(strings.NewReader(step))
@@ -461,18 +787,21 @@ func (b *Builder) processImageFrom(img *image.Image) error {
 	return nil
 }
 
-// probeCache checks if `b.docker` implements builder.ImageCache and image-caching
-// is enabled (`b.UseCache`).
-// If so attempts to look up the current `b.image` and `b.runConfig` pair with `b.docker`.
+// probeCache checks if the builder has an image cache, from `b.docker`
+// implementing builder.ImageCache or builder.ImageCacheBuilder, and
+// image-caching is enabled (`b.UseCache`).
+// If so attempts to look up the current `b.image` and `b.runConfig` pair in the cache.
 // If an image is found, probeCache returns `(true, nil)`.
 // If no image is found, it returns `(false, nil)`.
 // If there is any error, it returns `(false, err)`.
 func (b *Builder) probeCache() (bool, error) {
-	c, ok := b.docker.(builder.ImageCache)
-	if !ok || !b.UseCache || b.cacheBusted {
+	if b.step != nil {
+		b.step.Cache = "miss"
+	}
+	if b.imageCache == nil || !b.UseCache || b.cacheBusted {
 		return false, nil
 	}
-	cache, err := c.GetCachedImage(b.image, b.runConfig)
+	cache, err := b.imageCache.GetCachedImage(b.image, b.runConfig)
 	if err != nil {
 		return false, err
 	}
@@ -482,6 +811,10 @@ func (b *Builder) probeCache() (bool, error) {
 		return false, nil
 	}
 
+	if b.step != nil {
+		b.step.Cache = "hit"
+	}
+
 	fmt.Fprintf(b.Stdout, " ---> Using cache\n")
 	logrus.Debugf("[BUILDER] Use cached version: %s", b.runConfig.Cmd)
 	b.image = string(cache)
@@ -517,6 +850,9 @@ func (b *Builder) create() (*container.Container, error) {
 		ShmSize:   b.ShmSize,
 		Resources: resources,
 	}
+	if b.secretsDir != "" {
+		hostConfig.Binds = []string{b.secretsDir + ":" + secretsPath + ":ro"}
+	}
 
 	config := *b.runConfig
 
@@ -531,6 +867,9 @@ func (b *Builder) create() (*container.Container, error) {
 	}
 
 	b.tmpContainers[c.ID] = struct{}{}
+	if b.step != nil {
+		b.step.ContainerID = c.ID
+	}
 	fmt.Fprintf(b.Stdout, " ---> Running in %s\n", stringid.TruncateID(c.ID))
 
 	if config.Cmd.Len() > 0 {
@@ -574,7 +913,11 @@ func (b *Builder) run(c *container.Container) error {
 	}
 
 	// Wait for it to finish
-	if ret, _ := c.WaitStop(-1 * time.Second); ret != 0 {
+	ret, _ := c.WaitStop(-1 * time.Second)
+	if b.step != nil {
+		b.step.ExitCode = &ret
+	}
+	if ret != 0 {
 		// TODO: change error type, because jsonmessage.JSONError assumes HTTP
 		return &jsonmessage.JSONError{
 			Message: fmt.Sprintf("The command '%s' returned a non-zero code: %d", b.runConfig.Cmd.ToString(), ret),
@@ -585,6 +928,52 @@ func (b *Builder) run(c *container.Container) error {
 	return nil
 }
 
+// mountSecrets makes the secrets available to the containers created until
+// the returned function is called. The secrets are written to a tmpfs on the
+// host which is bind mounted into the containers, so that they neither end up
+// on disk nor in the layer of the step.
+func (b *Builder) mountSecrets() (func(), error) {
+	dir, err := mountSecrets(b.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	b.secretsDir = dir
+	return func() {
+		b.secretsDir = ""
+		if err := unmountSecrets(dir); err != nil {
+			logrus.Warnf("Failed to unmount the build secrets at %s: %v", dir, err)
+		}
+	}, nil
+}
+
+// missingDirs returns the directories of path that don't exist in the root
+// filesystem rootfs, the deepest first. They are those that mounting a volume
+// at path creates in the container layer.
+func missingDirs(rootfs, path string) []string {
+	var missing []string
+	for p := path; p != filepath.Dir(p); p = filepath.Dir(p) {
+		dir, err := symlink.FollowSymlinkInScope(filepath.Join(rootfs, p), rootfs)
+		if err != nil {
+			break
+		}
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	return missing
+}
+
+// removeMountPoint removes the directories created for a mount point, as
+// returned by missingDirs, unless the step added files to them.
+func removeMountPoint(dirs []string) {
+	for _, dir := range dirs {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
 func (b *Builder) removeContainer(c string) error {
 	rmConfig := &daemon.ContainerRmConfig{
 		ForceRemove:  true,
@@ -3,8 +3,11 @@
 package dockerfile
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+
+	"github.com/docker/docker/pkg/mount"
 )
 
 func fixPermissions(source, destination string, uid, gid int, destExisted bool) error {
@@ -38,3 +41,43 @@ func fixPermissions(source, destination string, uid, gid int, destExisted bool)
 		return os.Lchown(fullpath, uid, gid)
 	})
 }
+
+// mountSecrets writes the secrets, by ID, to a new tmpfs and returns the
+// directory it is mounted on. The secrets are readable by any user, so that
+// the user of the RUN instruction can read them whatever its UID, and
+// remapped root as well. The tmpfs is mounted in a directory only root can
+// access, which keeps the other users of the host away from them.
+func mountSecrets(secrets map[string][]byte) (string, error) {
+	root, err := ioutil.TempDir("", "docker-build-secrets")
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(root, "secrets")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		os.Remove(root)
+		return "", err
+	}
+	if err := mount.Mount("tmpfs", dir, "tmpfs", "nodev,nosuid,noexec,mode=0755"); err != nil {
+		os.Remove(dir)
+		os.Remove(root)
+		return "", err
+	}
+	for id, data := range secrets {
+		if err := ioutil.WriteFile(filepath.Join(dir, id), data, 0444); err != nil {
+			unmountSecrets(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// unmountSecrets unmounts the tmpfs of the secrets and removes its directory.
+func unmountSecrets(dir string) error {
+	if err := mount.Unmount(dir); err != nil {
+		return err
+	}
+	if err := os.Remove(dir); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Dir(dir))
+}
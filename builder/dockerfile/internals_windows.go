@@ -2,7 +2,18 @@
 
 package dockerfile
 
+import "fmt"
+
 func fixPermissions(source, destination string, uid, gid int, destExisted bool) error {
 	// chown is not supported on Windows
 	return nil
 }
+
+// mountSecrets is not supported on Windows, which has no tmpfs.
+func mountSecrets(secrets map[string][]byte) (string, error) {
+	return "", fmt.Errorf("Build secrets are not supported on Windows")
+}
+
+func unmountSecrets(dir string) error {
+	return nil
+}
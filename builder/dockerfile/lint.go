@@ -0,0 +1,255 @@
+package dockerfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/builder/dockerfile/command"
+	"github.com/docker/docker/builder/dockerfile/parser"
+)
+
+// Severities of the diagnostics of Lint.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// lintFlags are the flags the dispatchers declare, by instruction. The
+// instructions that aren't listed take no flag.
+var lintFlags = map[string][]string{
+	command.Add:         {"chown", "checksum"},
+	command.Copy:        {"from", "chown"},
+	command.Healthcheck: {"interval", "timeout", "retries"},
+}
+
+// jsonForms are the instructions whose arguments may be a JSON array.
+var jsonForms = map[string]bool{
+	command.Add:        true,
+	command.Copy:       true,
+	command.Run:        true,
+	command.Cmd:        true,
+	command.Entrypoint: true,
+	command.Volume:     true,
+	command.Shell:      true,
+}
+
+// variableRef matches the references to variables that the instructions
+// replace, as `$name` or `${name}`, along with the modifier of the latter.
+var variableRef = regexp.MustCompile(`\\?\$(?:([a-zA-Z_][a-zA-Z0-9_]*)|\{([a-zA-Z_][a-zA-Z0-9_]*)(:[-+])?)`)
+
+// Lint parses a Dockerfile and reports the problems found in it, without
+// running any instruction. The error is only set if the Dockerfile can't be
+// read.
+func Lint(dockerfile io.Reader) ([]types.BuildDiagnostic, error) {
+	content, err := ioutil.ReadAll(dockerfile)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{
+		lines:      strings.Split(string(content), "\n"),
+		args:       make(map[string]bool),
+		envs:       make(map[string]bool),
+		referenced: make(map[string]bool),
+	}
+
+	ast, errs := parser.ParseAll(bytes.NewReader(content))
+	for _, err := range errs {
+		l.report(&parser.Node{StartLine: err.Line, EndLine: err.Line}, "", SeverityError, "parse-error", err.Err.Error())
+	}
+	for _, n := range ast.Children {
+		l.lint(n)
+	}
+	l.lintOnbuildReachability()
+
+	sort.Sort(diagnosticsByPosition(l.diagnostics))
+	return l.diagnostics, nil
+}
+
+// linter holds the state of Lint while it goes through the instructions.
+type linter struct {
+	lines       []string
+	diagnostics []types.BuildDiagnostic
+
+	args       map[string]bool // names of the ARGs declared so far
+	envs       map[string]bool // names of the variables set by ENV in the current stage
+	stages     []*lintStage
+	referenced map[string]bool // stages used by FROM, by lower-cased name or index
+}
+
+// lintStage is a build stage, started by a FROM.
+type lintStage struct {
+	name     string
+	onbuilds []*parser.Node
+}
+
+func (l *linter) lint(n *parser.Node) {
+	if !l.lintInstruction(n) {
+		return
+	}
+
+	switch n.Value {
+	case command.From:
+		stage := &lintStage{}
+		if n.Next != nil {
+			l.referenced[strings.ToLower(n.Next.Value)] = true
+			if n.Next.Next != nil && n.Next.Next.Next != nil {
+				stage.name = strings.ToLower(n.Next.Next.Next.Value)
+			}
+		}
+		l.stages = append(l.stages, stage)
+		l.envs = make(map[string]bool)
+	case command.Arg:
+		if n.Next != nil {
+			l.args[strings.SplitN(n.Next.Value, "=", 2)[0]] = true
+		}
+	case command.Env:
+		for a := n.Next; a != nil && a.Next != nil; a = a.Next.Next {
+			l.envs[a.Value] = true
+		}
+	case command.Maintainer:
+		l.report(n, "", SeverityWarning, "deprecated-instruction", "MAINTAINER is deprecated, use a LABEL instead, like LABEL maintainer=\"name\"")
+	case command.Onbuild:
+		l.lintOnbuild(n)
+	}
+}
+
+// lintInstruction reports the problems of an instruction on its own, and
+// returns whether it is known.
+func (l *linter) lintInstruction(n *parser.Node) bool {
+	upperCasedCmd := strings.ToUpper(n.Value)
+	if _, ok := evaluateTable[n.Value]; !ok {
+		l.report(n, n.Value, SeverityError, "unknown-instruction", fmt.Sprintf("Unknown instruction: %s", upperCasedCmd))
+		return false
+	}
+
+	flags := NewBFlags()
+	flags.Args = n.Flags
+	for _, name := range lintFlags[n.Value] {
+		flags.AddString(name, "")
+	}
+	if err := flags.Parse(); err != nil {
+		token := ""
+		if len(n.Flags) > 0 {
+			token = n.Flags[0]
+		}
+		for _, f := range n.Flags {
+			if strings.Contains(err.Error(), strings.SplitN(strings.TrimLeft(f, "-"), "=", 2)[0]) {
+				token = f
+				break
+			}
+		}
+		l.report(n, token, SeverityError, "invalid-flag", fmt.Sprintf("%s: %v", upperCasedCmd, err))
+	}
+
+	if jsonForms[n.Value] && !n.Attributes["json"] {
+		if n.Value == command.Shell {
+			l.report(n, "", SeverityError, "invalid-json", "SHELL requires the arguments to be in JSON form")
+		} else if n.Next != nil && strings.HasPrefix(n.Next.Value, "[") {
+			l.report(n, n.Next.Value, SeverityWarning, "invalid-json", fmt.Sprintf("The arguments of %s are not a valid JSON array, they are used as a string", upperCasedCmd))
+		}
+	}
+
+	if replaceEnvAllowed[n.Value] {
+		l.lintVariables(n)
+	}
+	return true
+}
+
+// lintVariables reports the variables that the arguments of an instruction
+// reference, but that no ARG declared and no ENV of the stage set. They may
+// still be set by the base image.
+func (l *linter) lintVariables(n *parser.Node) {
+	for a := n.Next; a != nil; a = a.Next {
+		for _, m := range variableRef.FindAllStringSubmatch(a.Value, -1) {
+			if strings.HasPrefix(m[0], `\`) || m[3] != "" {
+				// Escaped, or with a default value.
+				continue
+			}
+			name := m[1] + m[2]
+			if l.args[name] || l.envs[name] || BuiltinAllowedBuildArgs[name] {
+				continue
+			}
+			l.report(n, strings.TrimPrefix(m[0], `\`), SeverityWarning, "undefined-arg", fmt.Sprintf("%s is not declared by an ARG or set by an ENV of the build stage", name))
+		}
+	}
+}
+
+// lintOnbuild reports the ONBUILD triggers that would fail when they run.
+func (l *linter) lintOnbuild(n *parser.Node) {
+	if n.Next == nil || len(n.Next.Children) == 0 {
+		l.report(n, "", SeverityError, "invalid-onbuild", "ONBUILD requires at least one argument")
+		return
+	}
+	if len(l.stages) > 0 {
+		stage := l.stages[len(l.stages)-1]
+		stage.onbuilds = append(stage.onbuilds, n)
+	}
+
+	trigger := n.Next.Children[0]
+	switch trigger.Value {
+	case command.Onbuild:
+		l.report(n, "", SeverityError, "invalid-onbuild", "Chaining ONBUILD via `ONBUILD ONBUILD` isn't allowed")
+	case command.From, command.Maintainer:
+		l.report(n, "", SeverityError, "invalid-onbuild", fmt.Sprintf("%s isn't allowed as an ONBUILD trigger", strings.ToUpper(trigger.Value)))
+	default:
+		if _, ok := evaluateTable[trigger.Value]; !ok {
+			l.report(n, trigger.Value, SeverityError, "unknown-instruction", fmt.Sprintf("Unknown instruction: %s", strings.ToUpper(trigger.Value)))
+		}
+	}
+}
+
+// lintOnbuildReachability reports the ONBUILD triggers of the intermediate
+// stages that no FROM uses, and that can't be the target of the build as
+// they have no name. Their triggers never run.
+func (l *linter) lintOnbuildReachability() {
+	for i, stage := range l.stages {
+		if i == len(l.stages)-1 || stage.name != "" || l.referenced[strconv.Itoa(i)] {
+			continue
+		}
+		for _, n := range stage.onbuilds {
+			l.report(n, "", SeverityWarning, "unreachable-onbuild", "ONBUILD in a build stage that no FROM uses, the trigger never runs")
+		}
+	}
+}
+
+// report adds a diagnostic for the instruction n, at the first occurrence of
+// token in its lines, or at the start of the instruction.
+func (l *linter) report(n *parser.Node, token, severity, code, message string) {
+	line, column := n.StartLine, 0
+	if token != "" {
+		for i := n.StartLine; i <= n.EndLine && i <= len(l.lines); i++ {
+			if idx := strings.Index(strings.ToLower(l.lines[i-1]), strings.ToLower(token)); idx >= 0 {
+				line, column = i, idx+1
+				break
+			}
+		}
+	}
+	if column == 0 && line >= 1 && line <= len(l.lines) {
+		column = len(l.lines[line-1]) - len(strings.TrimLeft(l.lines[line-1], " \t")) + 1
+	}
+	l.diagnostics = append(l.diagnostics, types.BuildDiagnostic{
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+type diagnosticsByPosition []types.BuildDiagnostic
+
+func (d diagnosticsByPosition) Len() int      { return len(d) }
+func (d diagnosticsByPosition) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d diagnosticsByPosition) Less(i, j int) bool {
+	if d[i].Line != d[j].Line {
+		return d[i].Line < d[j].Line
+	}
+	return d[i].Column < d[j].Column
+}
@@ -3,6 +3,7 @@ package parser
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"regexp"
 	"strings"
@@ -49,22 +50,24 @@ func init() {
 	// functions. Errors are propagated up by Parse() and the resulting AST can
 	// be incorporated directly into the existing AST as a next.
 	dispatch = map[string]func(string) (*Node, map[string]bool, error){
-		command.User:       parseString,
-		command.Onbuild:    parseSubCommand,
-		command.Workdir:    parseString,
-		command.Env:        parseEnv,
-		command.Label:      parseLabel,
-		command.Maintainer: parseString,
-		command.From:       parseString,
-		command.Add:        parseMaybeJSONToList,
-		command.Copy:       parseMaybeJSONToList,
-		command.Run:        parseMaybeJSON,
-		command.Cmd:        parseMaybeJSON,
-		command.Entrypoint: parseMaybeJSON,
-		command.Expose:     parseStringsWhitespaceDelimited,
-		command.Volume:     parseMaybeJSONToList,
-		command.StopSignal: parseString,
-		command.Arg:        parseNameOrNameVal,
+		command.User:        parseString,
+		command.Onbuild:     parseSubCommand,
+		command.Workdir:     parseString,
+		command.Env:         parseEnv,
+		command.Label:       parseLabel,
+		command.Maintainer:  parseString,
+		command.From:        parseStringsWhitespaceDelimited,
+		command.Add:         parseMaybeJSONToList,
+		command.Copy:        parseMaybeJSONToList,
+		command.Run:         parseMaybeJSON,
+		command.Cmd:         parseMaybeJSON,
+		command.Entrypoint:  parseMaybeJSON,
+		command.Expose:      parseStringsWhitespaceDelimited,
+		command.Volume:      parseMaybeJSONToList,
+		command.StopSignal:  parseString,
+		command.Arg:         parseNameOrNameVal,
+		command.Healthcheck: parseHealthConfig,
+		command.Shell:       parseMaybeJSON,
 	}
 }
 
@@ -100,9 +103,40 @@ func parseLine(line string) (string, *Node, error) {
 	return "", node, nil
 }
 
+// LineError is an error parsing an instruction of a Dockerfile.
+type LineError struct {
+	Line int // the line in the original dockerfile where the instruction begins
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
 // Parse is the main parse routine.
 // It handles an io.ReadWriteCloser and returns the root of the AST.
 func Parse(rwc io.Reader) (*Node, error) {
+	return parse(rwc, func(line int, err error) error {
+		return err
+	})
+}
+
+// ParseAll parses the Dockerfile like Parse, except that the instructions
+// that fail to parse are left out of the AST instead of ending the parse.
+// Their errors are returned along with the AST.
+func ParseAll(rwc io.Reader) (*Node, []*LineError) {
+	var errs []*LineError
+	root, _ := parse(rwc, func(line int, err error) error {
+		errs = append(errs, &LineError{Line: line, Err: err})
+		return nil
+	})
+	return root, errs
+}
+
+// parse parses the Dockerfile, passing the error of each instruction that
+// fails to parse to onError. The instruction is skipped, unless onError
+// returns an error, which ends the parse.
+func parse(rwc io.Reader, onError func(line int, err error) error) (*Node, error) {
 	currentLine := 0
 	root := &Node{}
 	root.StartLine = -1
@@ -112,12 +146,9 @@ func Parse(rwc io.Reader) (*Node, error) {
 		scannedLine := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
 		currentLine++
 		line, child, err := parseLine(scannedLine)
-		if err != nil {
-			return nil, err
-		}
 		startLine := currentLine
 
-		if line != "" && child == nil {
+		if err == nil && line != "" && child == nil {
 			for scanner.Scan() {
 				newline := scanner.Text()
 				currentLine++
@@ -127,22 +158,22 @@ func Parse(rwc io.Reader) (*Node, error) {
 				}
 
 				line, child, err = parseLine(line + newline)
-				if err != nil {
-					return nil, err
-				}
-
-				if child != nil {
+				if err != nil || child != nil {
 					break
 				}
 			}
-			if child == nil && line != "" {
+			if err == nil && child == nil && line != "" {
 				line, child, err = parseLine(line)
-				if err != nil {
-					return nil, err
-				}
 			}
 		}
 
+		if err != nil {
+			if err := onError(startLine, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		if child != nil {
 			// Update the line information for the current child.
 			child.StartLine = startLine
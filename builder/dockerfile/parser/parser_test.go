@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -152,3 +153,26 @@ func TestLineInformation(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAll(t *testing.T) {
+	dockerfile := "FROM busybox\nENV A\nRUN [\"echo\", 1]\nRUN echo \\\n  hi\nLABEL a=b c\n"
+	ast, errs := ParseAll(strings.NewReader(dockerfile))
+
+	if len(ast.Children) != 2 || ast.Children[0].Value != "from" || ast.Children[1].Value != "run" {
+		t.Fatalf("Expected the FROM and the second RUN to be parsed, got %v", ast.Dump())
+	}
+	if ast.Children[1].StartLine != 4 || ast.Children[1].EndLine != 5 {
+		t.Fatalf("Wrong line information for the second RUN: %d-%d", ast.Children[1].StartLine, ast.Children[1].EndLine)
+	}
+	lines := []int{}
+	for _, err := range errs {
+		lines = append(lines, err.Line)
+	}
+	if fmt.Sprint(lines) != "[2 3 6]" {
+		t.Fatalf("Expected errors on lines 2, 3 and 6, got %v", errs)
+	}
+
+	if _, err := Parse(strings.NewReader(dockerfile)); err == nil {
+		t.Fatal("Expected Parse to fail")
+	}
+}
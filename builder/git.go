@@ -1,28 +1,32 @@
 package builder
 
 import (
-	"os"
-
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/utils"
 )
 
-// MakeGitContext returns a Context from gitURL that is cloned in a temporary directory.
-func MakeGitContext(gitURL string) (ModifiableContext, error) {
-	root, err := utils.GitClone(gitURL)
+// MakeGitContext returns a Context from gitURL that is cloned in a temporary directory,
+// along with the commit checked out.
+func MakeGitContext(gitURL string) (ModifiableContext, string, error) {
+	repo, err := utils.GitClone(gitURL)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	c, err := archive.Tar(root, archive.Uncompressed)
+	c, err := archive.Tar(repo.ContextDir, archive.Uncompressed)
 	if err != nil {
-		return nil, err
+		repo.Remove()
+		return nil, "", err
 	}
 
 	defer func() {
 		// TODO: print errors?
 		c.Close()
-		os.RemoveAll(root)
+		repo.Remove()
 	}()
-	return MakeTarSumContext(c)
+	ctx, err := MakeTarSumContext(c)
+	if err != nil {
+		return nil, "", err
+	}
+	return ctx, repo.Commit, nil
 }
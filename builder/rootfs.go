@@ -0,0 +1,19 @@
+package builder
+
+// rootFSContext is a Context over the root filesystem of a container. The
+// files are identified by their path only, the callers are expected to know
+// the content of the root filesystem from the image it comes from.
+type rootFSContext struct {
+	tarSumContext
+}
+
+// MakeRootFSContext returns a build Context reading from the root
+// filesystem of a container mounted at root. Unlike the Contexts made from a
+// tar stream, closing it leaves root in place.
+func MakeRootFSContext(root string) Context {
+	return &rootFSContext{tarSumContext{root: root}}
+}
+
+func (c *rootFSContext) Close() error {
+	return nil
+}
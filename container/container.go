@@ -15,6 +15,8 @@ import (
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/daemon/logger/local"
+	"github.com/docker/docker/daemon/logger/loggerutils/cache"
 	"github.com/docker/docker/daemon/network"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/image"
@@ -63,6 +65,10 @@ type CommonContainer struct {
 	// logDriver for closing
 	LogDriver logger.Logger  `json:"-"`
 	LogCopier *logger.Copier `json:"-"`
+
+	// LogDropped is the number of log messages dropped by the non-blocking
+	// log mode during the last run of the container.
+	LogDropped uint64
 }
 
 // NewBaseContainer creates a new container with its
@@ -228,6 +234,19 @@ func (container *Container) ExitOnNext() {
 	container.monitor.ExitOnNext()
 }
 
+// HoldExitOnNext signals to the monitor that it should not restart the
+// container if it exits, until CancelExitOnNext is called.
+func (container *Container) HoldExitOnNext() {
+	container.monitor.HoldExitOnNext()
+}
+
+// CancelExitOnNext signals to the monitor that the restart policy applies
+// again, after a stop that did not happen. A stop or kill requested with
+// ExitOnNext in the meantime is not cancelled.
+func (container *Container) CancelExitOnNext() {
+	container.monitor.CancelExitOnNext()
+}
+
 // Resize changes the TTY of the process running inside the container
 // to the given height and width. The container must be running.
 func (container *Container) Resize(h, w int) error {
@@ -247,6 +266,11 @@ func (container *Container) ConfigPath() (string, error) {
 	return container.GetRootResourcePath(configFileName)
 }
 
+// CheckpointDir returns the directory the container's checkpoints are stored in
+func (container *Container) CheckpointDir() (string, error) {
+	return container.GetRootResourcePath("checkpoints")
+}
+
 func validateID(id string) error {
 	if id == "" {
 		return derr.ErrorCodeEmptyID
@@ -279,6 +303,69 @@ func (container *Container) StartLogger(cfg runconfig.LogConfig) (logger.Logger,
 	if err != nil {
 		return nil, derr.ErrorCodeLoggingFactory.WithArgs(err)
 	}
+	ctx, err := container.loggerContext(cfg)
+	if err != nil {
+		return nil, err
+	}
+	l, err := c(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep a local copy of the logs for the drivers that can't read them
+	// back, so that `docker logs` works with them.
+	cachePath, err := container.GetRootResourcePath(fmt.Sprintf("%s-cache.log", container.ID))
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	cl, err := cache.WithLocalCache(l, ctx, cachePath)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	if cfg.Config[logger.ModeOpt] == logger.ModeNonBlocking {
+		maxSize, err := logger.MaxBufferSize(cfg.Config)
+		if err != nil {
+			cl.Close()
+			return nil, err
+		}
+		cl = logger.NewRingLogger(cl, maxSize)
+	}
+	return cl, nil
+}
+
+// StartLogReader starts a logger to read the logs of the container while it
+// is stopped. The logs cached for a logging driver that can't read them back
+// are read without starting that driver, and logging driver plugins are not
+// asked to start logging.
+func (container *Container) StartLogReader(cfg runconfig.LogConfig) (logger.Logger, error) {
+	ctx, err := container.loggerContext(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cachePath, err := container.GetRootResourcePath(fmt.Sprintf("%s-cache.log", container.ID))
+	if err != nil {
+		return nil, err
+	}
+	l, ok, err := cache.NewReader(ctx, cachePath)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return l, nil
+	}
+
+	c, err := logger.GetLogReader(cfg.Type)
+	if err != nil {
+		return nil, derr.ErrorCodeLoggingFactory.WithArgs(err)
+	}
+	return c(ctx)
+}
+
+// loggerContext returns the context of the logger of the container.
+func (container *Container) loggerContext(cfg runconfig.LogConfig) (logger.Context, error) {
 	ctx := logger.Context{
 		Config:              cfg.Config,
 		ContainerID:         container.ID,
@@ -292,14 +379,32 @@ func (container *Container) StartLogger(cfg runconfig.LogConfig) (logger.Logger,
 		ContainerLabels:     container.Config.Labels,
 	}
 
+	var err error
 	// Set logging file for "json-logger"
 	if cfg.Type == jsonfilelog.Name {
 		ctx.LogPath, err = container.GetRootResourcePath(fmt.Sprintf("%s-json.log", container.ID))
 		if err != nil {
-			return nil, err
+			return ctx, err
 		}
 	}
-	return c(ctx)
+	// Set logging file for the local driver
+	if cfg.Type == local.Name {
+		ctx.LogPath, err = container.GetRootResourcePath(fmt.Sprintf("%s-local.log", container.ID))
+		if err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// LogDroppedMessages returns the number of log messages dropped by the
+// non-blocking log mode since the container started, or during its last run
+// if it isn't running.
+func (container *Container) LogDroppedMessages() uint64 {
+	if container.LogDriver != nil {
+		return logger.DroppedMessages(container.LogDriver)
+	}
+	return container.LogDropped
 }
 
 // GetProcessLabel returns the process label for the container.
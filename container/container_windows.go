@@ -4,6 +4,7 @@ package container
 
 import (
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/volume"
 )
 
@@ -46,6 +47,15 @@ func (container *Container) UnmountVolumes(forceSyscall bool) error {
 	return nil
 }
 
+// UpdateContainer updates resources of a container. Only CPU shares are
+// supported on Windows. The container must be locked, and the caller saves
+// the new configuration on disk.
+func (container *Container) UpdateContainer(hostConfig *runconfig.HostConfig) {
+	if hostConfig.CPUShares != 0 {
+		container.HostConfig.CPUShares = hostConfig.CPUShares
+	}
+}
+
 // TmpfsMounts returns the list of tmpfs mounts
 func (container *Container) TmpfsMounts() []execdriver.Mount {
 	return nil
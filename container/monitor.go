@@ -10,6 +10,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/logger"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/promise"
 	"github.com/docker/docker/pkg/stringid"
@@ -32,6 +33,10 @@ type supervisor interface {
 	StartLogging(*Container) error
 	// Run starts a container
 	Run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error)
+	// Restore restores a container from the checkpoint in checkpointDir
+	Restore(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback, checkpointDir string) (execdriver.ExitStatus, error)
+	// Reattach resumes monitoring a container that kept running while the daemon restarted
+	Reattach(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error)
 	// IsShuttingDown tells whether the supervisor is shutting down or not
 	IsShuttingDown() bool
 }
@@ -61,6 +66,10 @@ type containerMonitor struct {
 	// either because docker or the user asked for the container to be stopped
 	shouldStop bool
 
+	// stopRequested is set when docker or the user asked for the container
+	// to be stopped, as opposed to holding its restart for a while
+	stopRequested bool
+
 	// startSignal is a channel that is closes after the container initially starts
 	startSignal chan struct{}
 
@@ -75,12 +84,37 @@ type containerMonitor struct {
 
 	// lastStartTime is the time which the monitor last exec'd the container's process
 	lastStartTime time.Time
+
+	// checkpointDir is the checkpoint the container's process is restored from
+	// on the first start instead of being exec'd. Restarts always exec.
+	checkpointDir string
+
+	// reattach is set when the container's process is already running and
+	// the monitor only needs to pick it up again on the first start.
+	reattach bool
 }
 
 // StartMonitor initializes a containerMonitor for this container with the provided supervisor and restart policy
-// and starts the container's process.
-func (container *Container) StartMonitor(s supervisor, policy runconfig.RestartPolicy) error {
-	container.monitor = &containerMonitor{
+// and starts the container's process. If checkpointDir is not empty the process is
+// restored from that checkpoint instead.
+func (container *Container) StartMonitor(s supervisor, policy runconfig.RestartPolicy, checkpointDir string) error {
+	container.monitor = newContainerMonitor(s, container, policy)
+	container.monitor.checkpointDir = checkpointDir
+
+	return container.monitor.wait()
+}
+
+// ReattachMonitor initializes a containerMonitor for a container whose process kept
+// running while the daemon was restarted, and attaches it to that process.
+func (container *Container) ReattachMonitor(s supervisor, policy runconfig.RestartPolicy) error {
+	container.monitor = newContainerMonitor(s, container, policy)
+	container.monitor.reattach = true
+
+	return container.monitor.wait()
+}
+
+func newContainerMonitor(s supervisor, container *Container, policy runconfig.RestartPolicy) *containerMonitor {
+	return &containerMonitor{
 		supervisor:    s,
 		container:     container,
 		restartPolicy: policy,
@@ -88,8 +122,6 @@ func (container *Container) StartMonitor(s supervisor, policy runconfig.RestartP
 		stopChan:      make(chan struct{}),
 		startSignal:   make(chan struct{}),
 	}
-
-	return container.monitor.wait()
 }
 
 // wait starts the container and wait until
@@ -109,15 +141,38 @@ func (m *containerMonitor) wait() error {
 // for exits the next time the process dies
 func (m *containerMonitor) ExitOnNext() {
 	m.mux.Lock()
+	m.stopRequested = true
+	m.exitOnNext()
+	m.mux.Unlock()
+}
 
+// HoldExitOnNext makes the monitor not restart the container the next time
+// the process dies, like ExitOnNext, until CancelExitOnNext is called.
+func (m *containerMonitor) HoldExitOnNext() {
+	m.mux.Lock()
+	m.exitOnNext()
+	m.mux.Unlock()
+}
+
+// CancelExitOnNext undoes HoldExitOnNext, so that the restart policy applies
+// again the next time the process dies, unless ExitOnNext was called.
+func (m *containerMonitor) CancelExitOnNext() {
+	m.mux.Lock()
+	if m.shouldStop && !m.stopRequested {
+		m.shouldStop = false
+		m.stopChan = make(chan struct{})
+	}
+	m.mux.Unlock()
+}
+
+// exitOnNext must be called with m.mux held.
+func (m *containerMonitor) exitOnNext() {
 	// we need to protect having a double close of the channel when stop is called
 	// twice or else we will get a panic
 	if !m.shouldStop {
 		m.shouldStop = true
 		close(m.stopChan)
 	}
-
-	m.mux.Unlock()
 }
 
 // Close closes the container's resources such as networking allocations and
@@ -162,8 +217,12 @@ func (m *containerMonitor) start() error {
 		m.container.HasBeenManuallyStopped = false
 	}
 
-	// reset the restart count
-	m.container.RestartCount = -1
+	// reset the restart count, unless the process is only being picked up again
+	if m.reattach {
+		m.container.RestartCount--
+	} else {
+		m.container.RestartCount = -1
+	}
 
 	for {
 		m.container.RestartCount++
@@ -176,11 +235,22 @@ func (m *containerMonitor) start() error {
 
 		pipes := execdriver.NewPipes(m.container.Stdin(), m.container.Stdout(), m.container.Stderr(), m.container.Config.OpenStdin)
 
-		m.logEvent("start")
-
 		m.lastStartTime = time.Now()
 
-		if exitStatus, err = m.supervisor.Run(m.container, pipes, m.callback); err != nil {
+		switch {
+		case m.reattach:
+			exitStatus, err = m.supervisor.Reattach(m.container, pipes, m.callback)
+			m.reattach = false
+		case m.checkpointDir != "":
+			m.logEvent("restore")
+			exitStatus, err = m.supervisor.Restore(m.container, pipes, m.callback, m.checkpointDir)
+			m.checkpointDir = ""
+		default:
+			m.logEvent("start")
+			exitStatus, err = m.supervisor.Run(m.container, pipes, m.callback)
+		}
+
+		if err != nil {
 			// if we receive an internal error from the initial start of a container then lets
 			// return it instead of entering the restart loop
 			// set to 127 for container cmd not found/does not exist)
@@ -265,9 +335,13 @@ func (m *containerMonitor) resetMonitor(successful bool) {
 // waitForNextRestart waits with the default time increment to restart the container unless
 // a user or docker asks for the container to be stopped
 func (m *containerMonitor) waitForNextRestart() {
+	m.mux.Lock()
+	stopChan := m.stopChan
+	m.mux.Unlock()
+
 	select {
 	case <-time.After(time.Duration(m.timeIncrement) * time.Millisecond):
-	case <-m.stopChan:
+	case <-stopChan:
 	}
 }
 
@@ -319,7 +393,12 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 		}
 	}
 
+	startedAt := m.container.StartedAt
 	m.container.SetRunning(pid)
+	if m.reattach {
+		// the process has been running since it was first started
+		m.container.StartedAt = startedAt
+	}
 
 	// signal that the process has started
 	// close channel only if not closed
@@ -374,6 +453,7 @@ func (m *containerMonitor) resetContainer(lock bool) {
 			}
 		}
 		container.LogDriver.Close()
+		container.LogDropped = logger.DroppedMessages(container.LogDriver)
 		container.LogCopier = nil
 		container.LogDriver = nil
 	}
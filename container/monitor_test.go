@@ -0,0 +1,61 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/docker/docker/runconfig"
+)
+
+type testSupervisor struct {
+	supervisor
+}
+
+func (s *testSupervisor) IsShuttingDown() bool {
+	return false
+}
+
+func TestMonitorCancelExitOnNext(t *testing.T) {
+	c := &Container{}
+	m := newContainerMonitor(&testSupervisor{}, c, runconfig.RestartPolicy{Name: "always"})
+
+	m.HoldExitOnNext()
+	if m.shouldRestart(1) {
+		t.Fatal("Expected the container not to be restarted after HoldExitOnNext")
+	}
+
+	m.CancelExitOnNext()
+	if !m.shouldRestart(1) {
+		t.Fatal("Expected the container to be restarted after CancelExitOnNext")
+	}
+	select {
+	case <-m.stopChan:
+		t.Fatal("Expected the stop channel to be open after CancelExitOnNext")
+	default:
+	}
+
+	// The restart can be held again.
+	m.HoldExitOnNext()
+	if m.shouldRestart(1) {
+		t.Fatal("Expected the container not to be restarted after HoldExitOnNext")
+	}
+}
+
+func TestMonitorCancelExitOnNextKeepsStop(t *testing.T) {
+	c := &Container{}
+
+	// A stop before or while the restart is held isn't cancelled.
+	for _, stopFirst := range []bool{true, false} {
+		m := newContainerMonitor(&testSupervisor{}, c, runconfig.RestartPolicy{Name: "always"})
+		if stopFirst {
+			m.ExitOnNext()
+			m.HoldExitOnNext()
+		} else {
+			m.HoldExitOnNext()
+			m.ExitOnNext()
+		}
+		m.CancelExitOnNext()
+		if m.shouldRestart(1) {
+			t.Fatalf("Expected the stopped container not to be restarted (stop first: %v)", stopFirst)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package daemon
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/runconfig"
+)
+
+// ImageCache looks up the images that cache the steps of a build, first in
+// the local build cache and then in the history of the images given as cache
+// sources, which don't need to have been built locally.
+type ImageCache struct {
+	daemon  *Daemon
+	sources []*image.Image
+}
+
+// MakeImageCache returns an ImageCache using the images referenced by
+// sourceRefs as cache sources. The images must be available locally, the
+// ones that aren't are skipped.
+func (daemon *Daemon) MakeImageCache(sourceRefs []string) *ImageCache {
+	cache := &ImageCache{daemon: daemon}
+	for _, ref := range sourceRefs {
+		img, err := daemon.GetImage(ref)
+		if err != nil {
+			logrus.Warnf("Could not look up %s for cache resolution, skipping: %v", ref, err)
+			continue
+		}
+		cache.sources = append(cache.sources, img)
+	}
+	return cache
+}
+
+// GetCachedImage returns the image that caches the build step running config
+// on top of the image parentID, or nil if there is none. When the step is
+// found in the history of a cache source, the image of the step is recreated
+// from the layers of the source, as a child of parentID.
+func (ic *ImageCache) GetCachedImage(parentID image.ID, config *runconfig.Config) (*image.Image, error) {
+	if img, err := ic.daemon.ImageGetCached(parentID, config); err != nil || img != nil {
+		return img, err
+	}
+
+	var parent *image.Image
+	if parentID != "" {
+		var err error
+		if parent, err = ic.daemon.imageStore.Get(parentID); err != nil {
+			return nil, err
+		}
+	}
+
+	createdBy := strings.Join(config.Cmd.Slice(), " ")
+	for _, source := range ic.sources {
+		if !isHistoryPrefix(parent, source) {
+			continue
+		}
+		n := 0
+		if parent != nil {
+			n = len(parent.History)
+		}
+		if source.History[n].CreatedBy != createdBy {
+			continue
+		}
+		id, err := ic.restoreCachedImage(parent, source, config)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Debugf("[BUILDER] Restored cached image %s from %s", id, source.ID())
+		return ic.daemon.imageStore.Get(id)
+	}
+	return nil, nil
+}
+
+// restoreCachedImage creates the image of the build step that follows parent
+// in the history of source. The image is made of the layers of parent and,
+// unless the step didn't change the filesystem, of the next layer of source.
+func (ic *ImageCache) restoreCachedImage(parent, source *image.Image, config *runconfig.Config) (image.ID, error) {
+	var history []image.History
+	rootFS := image.NewRootFS()
+	if parent != nil {
+		history = append(history, parent.History...)
+		r := *parent.RootFS
+		r.DiffIDs = append([]layer.DiffID(nil), r.DiffIDs...)
+		rootFS = &r
+	} else if source.RootFS != nil {
+		// Keep the base layer of the source, if the platform has one.
+		r := *source.RootFS
+		r.DiffIDs = nil
+		rootFS = &r
+	}
+
+	h := source.History[len(history)]
+	history = append(history, h)
+	if !h.EmptyLayer {
+		rootFS.Append(source.RootFS.DiffIDs[len(rootFS.DiffIDs)])
+	}
+
+	// The last step gets the configuration of the source, the others the
+	// configuration the builder looked up.
+	imgConfig := config
+	if len(history) == len(source.History) {
+		imgConfig = source.Config
+	}
+
+	b, err := json.Marshal(&image.Image{
+		V1Image: image.V1Image{
+			DockerVersion:   dockerversion.Version,
+			Config:          imgConfig,
+			ContainerConfig: *config,
+			Architecture:    source.Architecture,
+			OS:              source.OS,
+			Author:          h.Author,
+			Created:         h.Created,
+		},
+		RootFS:  rootFS,
+		History: history,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, err := ic.daemon.imageStore.Create(b)
+	if err != nil {
+		return "", err
+	}
+	if parent != nil {
+		if err := ic.daemon.imageStore.SetParent(id, parent.ID()); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// isHistoryPrefix returns whether the history and the layers of parent are
+// the first ones of source, and source has one more step to restore. A nil
+// parent, for FROM scratch, is a prefix of any image.
+func isHistoryPrefix(parent, source *image.Image) bool {
+	if source.RootFS == nil {
+		return false
+	}
+
+	var history []image.History
+	var diffIDs []layer.DiffID
+	if parent != nil {
+		if parent.RootFS == nil {
+			return false
+		}
+		history, diffIDs = parent.History, parent.RootFS.DiffIDs
+	}
+	if len(history) >= len(source.History) || len(diffIDs) > len(source.RootFS.DiffIDs) {
+		return false
+	}
+	for i, h := range history {
+		s := source.History[i]
+		if !h.Created.Equal(s.Created) || h.Author != s.Author || h.CreatedBy != s.CreatedBy || h.Comment != s.Comment || h.EmptyLayer != s.EmptyLayer {
+			return false
+		}
+	}
+	for i, diffID := range diffIDs {
+		if source.RootFS.DiffIDs[i] != diffID {
+			return false
+		}
+	}
+
+	// The next step must have a layer to restore, if it created one.
+	return source.History[len(history)].EmptyLayer || len(diffIDs) < len(source.RootFS.DiffIDs)
+}
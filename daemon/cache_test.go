@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+)
+
+func testCacheImage(diffIDs []layer.DiffID, history ...image.History) *image.Image {
+	rootFS := image.NewRootFS()
+	rootFS.DiffIDs = diffIDs
+	return &image.Image{RootFS: rootFS, History: history}
+}
+
+func TestIsHistoryPrefix(t *testing.T) {
+	created := time.Unix(1455000000, 0).UTC()
+	base := image.History{Created: created, CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /"}
+	env := image.History{Created: created, CreatedBy: "/bin/sh -c #(nop) ENV A=b", EmptyLayer: true}
+	run := image.History{Created: created, CreatedBy: "/bin/sh -c make"}
+	other := image.History{Created: created, CreatedBy: "/bin/sh -c #(nop) ADD file:def in /"}
+
+	source := testCacheImage([]layer.DiffID{"sha256:1", "sha256:2"}, base, env, run)
+
+	cases := []struct {
+		parent   *image.Image
+		expected bool
+	}{
+		{nil, true},
+		{testCacheImage([]layer.DiffID{"sha256:1"}, base), true},
+		{testCacheImage([]layer.DiffID{"sha256:1"}, base, env), true},
+		// No step left to restore.
+		{testCacheImage([]layer.DiffID{"sha256:1", "sha256:2"}, base, env, run), false},
+		// Different history.
+		{testCacheImage([]layer.DiffID{"sha256:1"}, other), false},
+		// Different layers.
+		{testCacheImage([]layer.DiffID{"sha256:3"}, base), false},
+	}
+	for i, c := range cases {
+		if actual := isHistoryPrefix(c.parent, source); actual != c.expected {
+			t.Fatalf("case %d: expected %v, got %v", i, c.expected, actual)
+		}
+	}
+
+	// The layer of the next step is missing from the source.
+	broken := testCacheImage([]layer.DiffID{"sha256:1"}, base, run)
+	if isHistoryPrefix(testCacheImage([]layer.DiffID{"sha256:1"}, base), broken) {
+		t.Fatal("expected a source without the layer of the next step not to match")
+	}
+}
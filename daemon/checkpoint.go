@@ -0,0 +1,135 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/utils"
+)
+
+var validCheckpointNamePattern = regexp.MustCompile(`^` + utils.RestrictedNameChars + `+$`)
+
+// CheckpointCreate checkpoints the process running in a container with CRIU
+func (daemon *Daemon) CheckpointCreate(name string, config types.CheckpointCreateOptions) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if !container.IsRunning() {
+		return derr.ErrorCodeNotRunning.WithArgs(name)
+	}
+	if container.Config.Tty {
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, "containers with a TTY can not be checkpointed")
+	}
+	if err := verifyCheckpointNetwork(container); err != nil {
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, err.Error())
+	}
+
+	dir, err := checkpointPath(container, config.CheckpointID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return derr.ErrorCodeCheckpointExists.WithArgs(config.CheckpointID, name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if config.Exit {
+		// Make sure the restart policy does not bring the container
+		// back up once CRIU has killed its process.
+		container.HoldExitOnNext()
+	}
+	opts := &execdriver.CheckpointOpts{
+		ImagesDirectory: dir,
+		WorkDirectory:   dir,
+		LeaveRunning:    !config.Exit,
+	}
+	if err := daemon.execDriver.Checkpoint(container.Command, opts); err != nil {
+		if config.Exit {
+			container.CancelExitOnNext()
+		}
+		os.RemoveAll(dir)
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, utils.GetErrorMessage(err))
+	}
+
+	daemon.LogContainerEvent(container, "checkpoint")
+	return nil
+}
+
+// CheckpointList lists the checkpoints of a container
+func (daemon *Daemon) CheckpointList(name string) ([]types.Checkpoint, error) {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := container.CheckpointDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := ioutil.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.Checkpoint{}, nil
+		}
+		return nil, err
+	}
+
+	checkpoints := []types.Checkpoint{}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		checkpoints = append(checkpoints, types.Checkpoint{Name: d.Name()})
+	}
+	return checkpoints, nil
+}
+
+// CheckpointDelete deletes a checkpoint of a container
+func (daemon *Daemon) CheckpointDelete(name string, checkpoint string) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+
+	dir, err := existingCheckpointPath(container, checkpoint)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// checkpointPath returns the directory a checkpoint with the given name
+// is stored in, after validating the name.
+func checkpointPath(container *container.Container, checkpoint string) (string, error) {
+	if !validCheckpointNamePattern.MatchString(checkpoint) {
+		return "", derr.ErrorCodeCheckpointName.WithArgs(checkpoint, utils.RestrictedNameChars)
+	}
+	root, err := container.CheckpointDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, checkpoint), nil
+}
+
+// existingCheckpointPath is like checkpointPath but fails if the
+// checkpoint does not exist.
+func existingCheckpointPath(container *container.Container, checkpoint string) (string, error) {
+	dir, err := checkpointPath(container, checkpoint)
+	if err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", derr.ErrorCodeNoSuchCheckpoint.WithArgs(checkpoint)
+	}
+	return dir, nil
+}
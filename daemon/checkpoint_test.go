@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/container"
+)
+
+func TestCheckpointPathValidatesName(t *testing.T) {
+	c := newTestContainer(t, "checkpoint-test")
+	defer os.RemoveAll(c.Root)
+
+	for _, name := range []string{"", "../escape", "a/b", "/abs", ".hidden"} {
+		if _, err := checkpointPath(c, name); err == nil {
+			t.Fatalf("Expected checkpoint name %q to be rejected", name)
+		}
+	}
+
+	dir, err := checkpointPath(c, "cp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(c.Root, "checkpoints", "cp1"); dir != expected {
+		t.Fatalf("Expected checkpoint path %s, got %s", expected, dir)
+	}
+}
+
+func TestCheckpointListAndDelete(t *testing.T) {
+	c := newTestContainer(t, "checkpoint-test")
+	defer os.RemoveAll(c.Root)
+	daemon := &Daemon{containers: &contStore{s: map[string]*container.Container{c.ID: c}}}
+
+	checkpoints, err := daemon.CheckpointList(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 0 {
+		t.Fatalf("Expected no checkpoints, got %v", checkpoints)
+	}
+
+	for _, name := range []string{"cp1", "cp2"} {
+		if err := os.MkdirAll(filepath.Join(c.Root, "checkpoints", name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkpoints, err = daemon.CheckpointList(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 2 || checkpoints[0].Name != "cp1" || checkpoints[1].Name != "cp2" {
+		t.Fatalf("Expected checkpoints cp1 and cp2, got %v", checkpoints)
+	}
+
+	if err := daemon.CheckpointDelete(c.ID, "cp1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.CheckpointDelete(c.ID, "cp1"); err == nil {
+		t.Fatal("Expected deleting a missing checkpoint to fail")
+	}
+	checkpoints, err = daemon.CheckpointList(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].Name != "cp2" {
+		t.Fatalf("Expected only checkpoint cp2, got %v", checkpoints)
+	}
+}
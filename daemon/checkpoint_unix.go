@@ -0,0 +1,19 @@
+// +build !windows
+
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/container"
+)
+
+// verifyCheckpointNetwork checks that the network of the container can be
+// restored by CRIU. Network endpoints managed by libnetwork are recreated on
+// start and can't be matched with the checkpointed interfaces.
+func verifyCheckpointNetwork(container *container.Container) error {
+	if mode := container.HostConfig.NetworkMode; !mode.IsHost() && !mode.IsNone() {
+		return fmt.Errorf("only containers using the host or none network can be checkpointed")
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/container"
+)
+
+// verifyCheckpointNetwork checks that the network of the container can be
+// restored. Checkpoints are not supported on Windows.
+func verifyCheckpointNetwork(container *container.Container) error {
+	return fmt.Errorf("checkpoints are not supported on Windows")
+}
@@ -1,8 +1,17 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -14,21 +23,26 @@ const (
 // CommonConfig defines the configuration of a docker daemon which are
 // common across platforms.
 type CommonConfig struct {
+	AuthZPlugins  []string // AuthZPlugins holds list of authorization plugins
 	AutoRestart   bool
 	Bridge        bridgeConfig // Bridge holds bridge network specific configuration.
+	ConfigFile    string       // ConfigFile is the path of the JSON daemon configuration file.
 	Context       map[string][]string
+	Debug         bool // Debug is set from the global --debug flag.
 	DisableBridge bool
 	DNS           []string
 	DNSOptions    []string
 	DNSSearch     []string
 	ExecOptions   []string
 	ExecRoot      string
+	EventsJournal eventsJournalConfig // EventsJournal holds the configuration of the on-disk events journal.
 	GraphDriver   string
 	GraphOptions  []string
 	Labels        []string
 	LogConfig     runconfig.LogConfig
 	Mtu           int
 	Pidfile       string
+	Registry      registry.Options // Registry holds the registry mirrors and insecure registries.
 	RemappedRoot  string
 	Root          string
 	TrustKeyPath  string
@@ -46,6 +60,9 @@ type CommonConfig struct {
 	// discovery. This should be a 'host:port' combination on which that daemon instance is
 	// reachable by other hosts.
 	ClusterAdvertise string
+
+	// reloadable holds the options set by ReloadConfiguration.
+	reloadable map[string]bool
 }
 
 // InstallCommonFlags adds command-line options to the top-level flag parser for
@@ -65,6 +82,24 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.Var(opts.NewListOptsRef(&config.DNS, opts.ValidateIPAddress), []string{"#dns", "-dns"}, usageFn("DNS server to use"))
 	cmd.Var(opts.NewListOptsRef(&config.DNSOptions, nil), []string{"-dns-opt"}, usageFn("DNS options to use"))
 	cmd.Var(opts.NewListOptsRef(&config.DNSSearch, opts.ValidateDNSSearch), []string{"-dns-search"}, usageFn("DNS search domains to use"))
+	cmd.Var(opts.NewListOptsRef(&config.AuthZPlugins, nil), []string{"-authorization-plugin"}, usageFn("List authorization plugins in order from first evaluator"))
+	cmd.StringVar(&config.ConfigFile, []string{"-config-file"}, defaultConfigFile, usageFn("Daemon configuration file"))
+	cmd.BoolVar(&config.EventsJournal.Enabled, []string{"-events-journal"}, false, usageFn("Record events in a journal under the root of the Docker runtime"))
+	cmd.StringVar(&config.EventsJournal.MaxSize, []string{"-events-journal-max-size"}, "10m", usageFn("Maximum size of each events journal file"))
+	cmd.IntVar(&config.EventsJournal.MaxFiles, []string{"-events-journal-max-file"}, 10, usageFn("Maximum number of events journal files"))
+	config.installReloadableFlags(cmd, usageFn)
+}
+
+// eventsJournalConfig holds the options of the events journal.
+type eventsJournalConfig struct {
+	Enabled  bool
+	MaxSize  string
+	MaxFiles int
+}
+
+// installReloadableFlags adds the options that can be changed at runtime by
+// reloading the configuration file.
+func (config *Config) installReloadableFlags(cmd *flag.FlagSet, usageFn func(string) string) {
 	cmd.Var(opts.NewListOptsRef(&config.Labels, opts.ValidateLabel), []string{"-label"}, usageFn("Set key=value labels to the daemon"))
 	cmd.StringVar(&config.LogConfig.Type, []string{"-log-driver"}, "json-file", usageFn("Default driver for container logs"))
 	cmd.Var(opts.NewMapOpts(config.LogConfig.Config, nil), []string{"-log-opt"}, usageFn("Set log driver options"))
@@ -72,3 +107,185 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.StringVar(&config.ClusterStore, []string{"-cluster-store"}, "", usageFn("Set the cluster store"))
 	cmd.Var(opts.NewMapOpts(config.ClusterOpts, nil), []string{"-cluster-store-opt"}, usageFn("Set cluster store options"))
 }
+
+// MergeConfigurationFile reads the JSON configuration file and applies its
+// values to the matching flags. The keys of the file are the long names of the
+// flags, without the leading dashes. Lists and maps take a JSON array or
+// object. It is an error to use a key that doesn't match any flag or to set an
+// option both in the file and on the command line. A missing file is ignored
+// unless it was given explicitly with --config-file.
+func MergeConfigurationFile(configFile string, flags *flag.FlagSet) error {
+	values, err := readConfigurationFile(configFile, flags)
+	if err != nil {
+		if os.IsNotExist(err) && !flags.IsSet("-config-file") {
+			return nil
+		}
+		return err
+	}
+	for _, key := range sortedKeys(values) {
+		for _, v := range values[key] {
+			// Set the value without marking the flag as set, so that the
+			// flags keep telling what came from the command line.
+			if err := flags.Lookup("-" + key).Value.Set(v); err != nil {
+				return fmt.Errorf("invalid value %q for %s in %s: %v", v, key, configFile, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ReloadConfiguration reads the configuration file again and calls reload
+// with a configuration holding the options that can be changed at runtime.
+// flags are the command line flags the daemon was started with; the options
+// they set aren't reloaded. Reloadable options missing from the file are
+// reset to their default value.
+func ReloadConfiguration(configFile string, flags *flag.FlagSet, reload func(*Config)) error {
+	values, err := readConfigurationFile(configFile, flags)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	config := &Config{}
+	config.LogConfig.Config = make(map[string]string)
+	config.ClusterOpts = make(map[string]string)
+	reloadFlags := flag.NewFlagSet("reload", flag.ContinueOnError)
+	config.installReloadableFlags(reloadFlags, func(string) string { return "" })
+	reloadFlags.BoolVar(&config.Debug, []string{"D", "-debug"}, false, "")
+	config.Registry.Mirrors = opts.NewListOpts(registry.ValidateMirror)
+	reloadFlags.Var(&config.Registry.Mirrors, []string{"-registry-mirror"}, "")
+	config.Registry.InsecureRegistries = opts.NewListOpts(registry.ValidateIndexName)
+	reloadFlags.Var(&config.Registry.InsecureRegistries, []string{"-insecure-registry"}, "")
+
+	for _, key := range sortedKeys(values) {
+		if reloadFlags.Lookup("-"+key) == nil {
+			continue
+		}
+		for _, v := range values[key] {
+			if err := reloadFlags.Set("-"+key, v); err != nil {
+				return fmt.Errorf("invalid value %q for %s in %s: %v", v, key, configFile, err)
+			}
+		}
+	}
+
+	config.reloadable = make(map[string]bool)
+	reloadFlags.VisitAll(func(f *flag.Flag) {
+		if !isFlagSet(flags, f.Names) {
+			config.reloadable[longFlagName(f.Names)] = true
+		}
+	})
+	reload(config)
+	return nil
+}
+
+// isReloadable tells whether the option with the given key was set by
+// ReloadConfiguration, either from the file or to its default value.
+func (config *Config) isReloadable(key string) bool {
+	return config.reloadable[key]
+}
+
+// readConfigurationFile parses the JSON configuration file into the string
+// values to set on each flag. It checks that every key matches one of flags
+// and that none of them was also set on the command line.
+func readConfigurationFile(configFile string, flags *flag.FlagSet) (map[string][]string, error) {
+	b, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", configFile, err)
+	}
+
+	var unknown, conflicts []string
+	values := make(map[string][]string, len(raw))
+	for key, value := range raw {
+		f := flags.Lookup("-" + key)
+		if f == nil {
+			unknown = append(unknown, key)
+			continue
+		}
+		if isFlagSet(flags, f.Names) {
+			conflicts = append(conflicts, key)
+			continue
+		}
+		v, err := configValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s in %s: %v", key, configFile, err)
+		}
+		values[key] = v
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("the following directives in %s don't match any option: %s", configFile, strings.Join(unknown, ", "))
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("the following directives are specified both as a flag and in %s: %s", configFile, strings.Join(conflicts, ", "))
+	}
+	return values, nil
+}
+
+// configValues converts a value of the configuration file into the strings
+// to pass to the flag, one for each element of a list or map.
+func configValues(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool, json.Number:
+		return []string{fmt.Sprint(v)}, nil
+	case []interface{}:
+		var values []string
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings")
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	case map[string]interface{}:
+		var values []string
+		for k, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a map of strings")
+			}
+			values = append(values, k+"="+s)
+		}
+		sort.Strings(values)
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported value %v", value)
+}
+
+// isFlagSet tells whether any of the names of a flag was set in flags.
+func isFlagSet(flags *flag.FlagSet, names []string) bool {
+	for _, name := range names {
+		if flags.IsSet(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// longFlagName returns the name of a flag as used in the configuration file.
+func longFlagName(names []string) string {
+	for _, name := range names {
+		if strings.HasPrefix(name, "-") {
+			return name[1:]
+		}
+	}
+	return names[0]
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
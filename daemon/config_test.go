@@ -0,0 +1,316 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
+)
+
+func writeConfigFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "docker-config-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "daemon.json")
+	if err := ioutil.WriteFile(configFile, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return configFile, func() { os.RemoveAll(dir) }
+}
+
+func newTestFlags(args ...string) (*Config, *flag.FlagSet, error) {
+	config := &Config{}
+	config.LogConfig.Config = make(map[string]string)
+	config.ClusterOpts = make(map[string]string)
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	config.InstallFlags(flags, func(string) string { return "" })
+	config.Registry.InstallFlags(flags, func(string) string { return "" })
+	return config, flags, flags.Parse(args)
+}
+
+func TestMergeConfigurationFile(t *testing.T) {
+	configFile, cleanup := writeConfigFile(t, `{
+		"label": ["foo=bar", "one=two"],
+		"log-opt": {"max-size": "10m"},
+		"mtu": 1400,
+		"registry-mirror": ["https://mirror.example.com"]
+	}`)
+	defer cleanup()
+
+	config, flags, err := newTestFlags("--log-driver", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MergeConfigurationFile(configFile, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(config.Labels, []string{"foo=bar", "one=two"}) {
+		t.Fatalf("expected labels from the file, got %v", config.Labels)
+	}
+	if config.LogConfig.Type != "syslog" || config.LogConfig.Config["max-size"] != "10m" {
+		t.Fatalf("expected log config from the file and the flags, got %+v", config.LogConfig)
+	}
+	if config.Mtu != 1400 {
+		t.Fatalf("expected mtu 1400, got %d", config.Mtu)
+	}
+	if mirrors := config.Registry.Mirrors.GetAll(); len(mirrors) != 1 || mirrors[0] != "https://mirror.example.com/" {
+		t.Fatalf("expected the registry mirror from the file, got %v", mirrors)
+	}
+	if flags.IsSet("-label") {
+		t.Fatal("values from the file must not be reported as set on the command line")
+	}
+}
+
+func TestMergeConfigurationFileConflicts(t *testing.T) {
+	configFile, cleanup := writeConfigFile(t, `{"label": ["foo=bar"], "log-driver": "syslog"}`)
+	defer cleanup()
+
+	_, flags, err := newTestFlags("--label", "one=two", "--log-driver", "json-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = MergeConfigurationFile(configFile, flags)
+	if err == nil || !strings.Contains(err.Error(), "both as a flag and in") || !strings.Contains(err.Error(), "label, log-driver") {
+		t.Fatalf("expected a conflict error for label and log-driver, got %v", err)
+	}
+}
+
+func TestMergeConfigurationFileInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"unknown-option": true}`,
+		`{"D": true}`,
+		`{"label": ["invalid"]}`,
+		`{"label": [1]}`,
+		`not json`,
+	} {
+		configFile, cleanup := writeConfigFile(t, content)
+		_, flags, err := newTestFlags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := MergeConfigurationFile(configFile, flags); err == nil {
+			t.Fatalf("expected an error for %s", content)
+		}
+		cleanup()
+	}
+}
+
+func TestMergeConfigurationFileMissing(t *testing.T) {
+	_, flags, err := newTestFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MergeConfigurationFile("/nonexistent/daemon.json", flags); err != nil {
+		t.Fatalf("a missing default configuration file must be ignored, got %v", err)
+	}
+
+	_, flags, err = newTestFlags("--config-file", "/nonexistent/daemon.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MergeConfigurationFile("/nonexistent/daemon.json", flags); err == nil {
+		t.Fatal("expected an error for a missing configuration file given on the command line")
+	}
+}
+
+func TestReloadConfiguration(t *testing.T) {
+	configFile, cleanup := writeConfigFile(t, `{"label": ["foo=bar"], "mtu": 1400}`)
+	defer cleanup()
+
+	_, flags, err := newTestFlags("--log-driver", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded *Config
+	if err := ReloadConfiguration(configFile, flags, func(c *Config) { reloaded = c }); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == nil {
+		t.Fatal("expected the reload callback to be called")
+	}
+	if !reflect.DeepEqual(reloaded.Labels, []string{"foo=bar"}) {
+		t.Fatalf("expected labels from the file, got %v", reloaded.Labels)
+	}
+	if reloaded.Mtu != 0 {
+		t.Fatalf("options that can't be reloaded must be ignored, got mtu %d", reloaded.Mtu)
+	}
+	if !reloaded.isReloadable("label") || !reloaded.isReloadable("cluster-store") {
+		t.Fatal("expected label and cluster-store to be reloadable")
+	}
+	if reloaded.isReloadable("log-driver") {
+		t.Fatal("options set on the command line must not be reloadable")
+	}
+}
+
+func TestDaemonReload(t *testing.T) {
+	daemon := &Daemon{
+		configStore: &Config{
+			CommonConfig: CommonConfig{
+				Labels:    []string{"foo=bar"},
+				LogConfig: runconfig.LogConfig{Type: "json-file"},
+			},
+		},
+		defaultLogConfig: runconfig.LogConfig{Type: "json-file"},
+		EventsService:    events.New(),
+		RegistryService:  registry.NewService(nil),
+	}
+	daemon.configStore.Registry.Mirrors = registryListOpts()
+	daemon.configStore.Registry.InsecureRegistries = registryListOpts()
+
+	config := &Config{
+		CommonConfig: CommonConfig{
+			Labels:    []string{"foo=baz"},
+			LogConfig: runconfig.LogConfig{Type: "json-file", Config: map[string]string{"max-size": "10m"}},
+		},
+	}
+	config.Registry.Mirrors = registryListOpts("https://mirror.example.com/")
+	config.Registry.InsecureRegistries = registryListOpts()
+	config.reloadable = map[string]bool{"label": true, "log-opt": true, "registry-mirror": true, "insecure-registry": true}
+
+	if err := daemon.Reload(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(daemon.configStore.Labels, []string{"foo=baz"}) {
+		t.Fatalf("expected labels to be reloaded, got %v", daemon.configStore.Labels)
+	}
+	if daemon.defaultLogConfig.Config["max-size"] != "10m" {
+		t.Fatalf("expected the default log options to be reloaded, got %v", daemon.defaultLogConfig)
+	}
+	if mirrors := daemon.RegistryService.ServiceConfig().Mirrors; len(mirrors) != 1 || mirrors[0] != "https://mirror.example.com/" {
+		t.Fatalf("expected the registry mirrors to be reloaded, got %v", mirrors)
+	}
+
+	evts, _, cancel := daemon.EventsService.Subscribe()
+	defer cancel()
+	if len(evts) != 1 || evts[0].Type != "daemon" || evts[0].Action != "reload" {
+		t.Fatalf("expected a daemon reload event, got %+v", evts)
+	}
+	attributes := evts[0].Actor.Attributes
+	if attributes["label"] != "foo=baz" || attributes["log-opt"] != "max-size=10m" || attributes["registry-mirror"] != "https://mirror.example.com/" {
+		t.Fatalf("expected the changed options in the event attributes, got %v", attributes)
+	}
+	if _, ok := attributes["debug"]; ok {
+		t.Fatalf("unchanged options must not be in the event attributes, got %v", attributes)
+	}
+}
+
+func TestDaemonReloadKeepsContainerLogConfig(t *testing.T) {
+	daemon := &Daemon{
+		configStore: &Config{
+			CommonConfig: CommonConfig{
+				LogConfig: runconfig.LogConfig{Type: "json-file"},
+			},
+		},
+		defaultLogConfig: runconfig.LogConfig{Type: "json-file", Config: map[string]string{"max-size": "1m"}},
+		EventsService:    events.New(),
+		RegistryService:  registry.NewService(nil),
+	}
+	daemon.configStore.Registry.Mirrors = registryListOpts()
+	daemon.configStore.Registry.InsecureRegistries = registryListOpts()
+
+	before := &runconfig.HostConfig{}
+	daemon.setDefaultLogConfig(before)
+	own := &runconfig.HostConfig{LogConfig: runconfig.LogConfig{Type: "none"}}
+	daemon.setDefaultLogConfig(own)
+
+	config := &Config{
+		CommonConfig: CommonConfig{
+			LogConfig: runconfig.LogConfig{Type: "syslog", Config: map[string]string{"tag": "app"}},
+		},
+	}
+	config.Registry.Mirrors = registryListOpts()
+	config.Registry.InsecureRegistries = registryListOpts()
+	config.reloadable = map[string]bool{"log-driver": true, "log-opt": true}
+	if err := daemon.Reload(config); err != nil {
+		t.Fatal(err)
+	}
+
+	after := &runconfig.HostConfig{}
+	daemon.setDefaultLogConfig(after)
+
+	for _, c := range []struct {
+		hostConfig *runconfig.HostConfig
+		expected   runconfig.LogConfig
+	}{
+		{before, runconfig.LogConfig{Type: "json-file", Config: map[string]string{"max-size": "1m"}}},
+		{own, runconfig.LogConfig{Type: "none"}},
+		{after, runconfig.LogConfig{Type: "syslog", Config: map[string]string{"tag": "app"}}},
+	} {
+		if !reflect.DeepEqual(c.hostConfig.LogConfig, c.expected) {
+			t.Fatalf("expected the log config %+v, got %+v", c.expected, c.hostConfig.LogConfig)
+		}
+	}
+}
+
+func TestDaemonReloadConcurrentReads(t *testing.T) {
+	daemon := &Daemon{
+		configStore: &Config{
+			CommonConfig: CommonConfig{
+				LogConfig: runconfig.LogConfig{Type: "json-file"},
+			},
+		},
+		defaultLogConfig: runconfig.LogConfig{Type: "json-file"},
+		EventsService:    events.New(),
+		RegistryService:  registry.NewService(nil),
+	}
+	daemon.configStore.Registry.Mirrors = registryListOpts()
+	daemon.configStore.Registry.InsecureRegistries = registryListOpts()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			daemon.getDefaultLogConfig()
+			daemon.RegistryService.ServiceConfig()
+			daemon.configLock.RLock()
+			_ = daemon.configStore.Labels
+			daemon.configLock.RUnlock()
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		config := &Config{
+			CommonConfig: CommonConfig{
+				Labels:    []string{fmt.Sprintf("foo=%d", i)},
+				LogConfig: runconfig.LogConfig{Type: "json-file", Config: map[string]string{"max-size": fmt.Sprintf("%dm", i+1)}},
+			},
+		}
+		config.Registry.Mirrors = registryListOpts(fmt.Sprintf("https://mirror%d.example.com/", i))
+		config.Registry.InsecureRegistries = registryListOpts()
+		config.reloadable = map[string]bool{"label": true, "log-opt": true, "registry-mirror": true}
+		if err := daemon.Reload(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	<-done
+}
+
+func registryListOpts(values ...string) opts.ListOpts {
+	l := opts.NewListOpts(nil)
+	for _, v := range values {
+		l.Set(v)
+	}
+	return l
+}
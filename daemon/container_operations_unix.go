@@ -229,6 +229,7 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 		CapAdd:             c.HostConfig.CapAdd.Slice(),
 		CapDrop:            c.HostConfig.CapDrop.Slice(),
 		CgroupParent:       c.HostConfig.CgroupParent,
+		Detach:             daemon.detachable(c),
 		GIDMapping:         gidMap,
 		GroupAdd:           c.HostConfig.GroupAdd,
 		Ipc:                ipc,
@@ -236,6 +237,7 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 		Pid:                pid,
 		ReadonlyRootfs:     c.HostConfig.ReadonlyRootfs,
 		RemappedRoot:       remappedRoot,
+		SeccompProfile:     c.SeccompProfile,
 		UIDMapping:         uidMap,
 		UTS:                uts,
 	}
@@ -654,6 +656,7 @@ func (daemon *Daemon) connectToNetwork(container *container.Container, idOrName
 		return derr.ErrorCodeJoinInfo.WithArgs(err)
 	}
 
+	daemon.LogNetworkEventWithAttributes(n, "connect", map[string]string{"container": container.ID})
 	return nil
 }
 
@@ -667,7 +670,12 @@ func (daemon *Daemon) DisconnectFromNetwork(container *container.Container, n li
 		return runconfig.ErrConflictHostNetwork
 	}
 
-	return disconnectFromNetwork(container, n)
+	if err := disconnectFromNetwork(container, n); err != nil {
+		return err
+	}
+
+	daemon.LogNetworkEventWithAttributes(n, "disconnect", map[string]string{"container": container.ID})
+	return nil
 }
 
 func disconnectFromNetwork(container *container.Container, n libnetwork.Network) error {
@@ -798,7 +806,13 @@ func (daemon *Daemon) releaseNetwork(container *container.Container) {
 
 	sid := container.NetworkSettings.SandboxID
 	networks := container.NetworkSettings.Networks
-	for n := range networks {
+	var joined []libnetwork.Network
+	for n, epSettings := range networks {
+		if epSettings.EndpointID != "" {
+			if nw, err := daemon.FindNetwork(n); err == nil {
+				joined = append(joined, nw)
+			}
+		}
 		networks[n] = &network.EndpointSettings{}
 	}
 
@@ -817,6 +831,10 @@ func (daemon *Daemon) releaseNetwork(container *container.Container) {
 	if err := sb.Delete(); err != nil {
 		logrus.Errorf("Error deleting sandbox id %s for container %s: %v", sid, container.ID, err)
 	}
+
+	for _, nw := range joined {
+		daemon.LogNetworkEventWithAttributes(nw, "disconnect", map[string]string{"container": container.ID})
+	}
 }
 
 func (daemon *Daemon) setupIpcDirs(c *container.Container) error {
@@ -39,6 +39,7 @@ func (daemon *Daemon) ContainerCreate(params *ContainerCreateConfig) (types.Cont
 	if err != nil {
 		return types.ContainerCreateResponse{ID: "", Warnings: warnings}, err
 	}
+	daemon.setDefaultLogConfig(params.HostConfig)
 
 	container, err := daemon.create(params)
 	if err != nil {
@@ -48,6 +49,23 @@ func (daemon *Daemon) ContainerCreate(params *ContainerCreateConfig) (types.Cont
 	return types.ContainerCreateResponse{ID: container.ID, Warnings: warnings}, nil
 }
 
+// setDefaultLogConfig stores the default logging driver and options of the
+// daemon in hostConfig, unless it configures its own, so that reloading the
+// daemon with other defaults only affects the containers created afterwards.
+func (daemon *Daemon) setDefaultLogConfig(hostConfig *runconfig.HostConfig) {
+	if hostConfig.LogConfig.Type != "" || len(hostConfig.LogConfig.Config) > 0 {
+		return
+	}
+	defaultLogConfig := daemon.getDefaultLogConfig()
+	hostConfig.LogConfig.Type = defaultLogConfig.Type
+	if len(defaultLogConfig.Config) > 0 {
+		hostConfig.LogConfig.Config = make(map[string]string, len(defaultLogConfig.Config))
+		for k, v := range defaultLogConfig.Config {
+			hostConfig.LogConfig.Config[k] = v
+		}
+	}
+}
+
 // Create creates a new container from the given configuration with a given name.
 func (daemon *Daemon) create(params *ContainerCreateConfig) (retC *container.Container, retErr error) {
 	var (
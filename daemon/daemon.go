@@ -22,6 +22,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/cliconfig"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/events"
@@ -44,17 +45,18 @@ import (
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/graphdb"
 	"github.com/docker/docker/pkg/idtools"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/docker/docker/pkg/nat"
 	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/plugins"
 	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/stringutils"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/truncindex"
+	"github.com/docker/docker/pkg/units"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/tag"
@@ -141,6 +143,9 @@ type Daemon struct {
 	netController             libnetwork.NetworkController
 	volumes                   *store.VolumeStore
 	discoveryWatcher          discovery.Watcher
+	discoveryStop             chan struct{}
+	reloadLock                sync.Mutex
+	configLock                sync.RWMutex // guards defaultLogConfig and the reloadable options of configStore
 	root                      string
 	shutdown                  bool
 	uidMaps                   []idtools.IDMap
@@ -236,26 +241,6 @@ func (daemon *Daemon) Register(container *container.Container) error {
 	// we'll waste time if we update it for every container
 	daemon.idIndex.Add(container.ID)
 
-	if container.IsRunning() {
-		logrus.Debugf("killing old running container %s", container.ID)
-		// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
-		container.SetStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
-		// use the current driver and ensure that the container is dead x.x
-		cmd := &execdriver.Command{
-			CommonCommand: execdriver.CommonCommand{
-				ID: container.ID,
-			},
-		}
-		daemon.execDriver.Terminate(cmd)
-
-		container.UnmountIpcMounts(mount.Unmount)
-
-		daemon.Unmount(container)
-		if err := container.ToDiskLocking(); err != nil {
-			logrus.Errorf("Error saving stopped state to disk: %v", err)
-		}
-	}
-
 	if err := daemon.prepareMountPoints(container); err != nil {
 		return err
 	}
@@ -263,6 +248,28 @@ func (daemon *Daemon) Register(container *container.Container) error {
 	return nil
 }
 
+// terminateStaleContainer makes sure the process of a container that was
+// running when the daemon went away is dead, and marks it as stopped.
+func (daemon *Daemon) terminateStaleContainer(container *container.Container) {
+	logrus.Debugf("killing old running container %s", container.ID)
+	// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
+	container.SetStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
+	// use the current driver and ensure that the container is dead x.x
+	cmd := &execdriver.Command{
+		CommonCommand: execdriver.CommonCommand{
+			ID: container.ID,
+		},
+	}
+	daemon.execDriver.Terminate(cmd)
+
+	container.UnmountIpcMounts(mount.Unmount)
+
+	daemon.Unmount(container)
+	if err := container.ToDiskLocking(); err != nil {
+		logrus.Errorf("Error saving stopped state to disk: %v", err)
+	}
+}
+
 func (daemon *Daemon) ensureName(container *container.Container) error {
 	if container.Name == "" {
 		name, err := daemon.generateNewName(container.ID)
@@ -350,16 +357,35 @@ func (daemon *Daemon) restore() error {
 
 			if err := daemon.Register(container); err != nil {
 				logrus.Errorf("Failed to register container %s: %s", container.ID, err)
-				// The container register failed should not be started.
+				// The container register failed should not be started,
+				// and its process can't be reattached.
+				if container.IsRunning() {
+					daemon.terminateStaleContainer(container)
+				}
 				return
 			}
 
+			if container.IsRunning() {
+				// With --live-restore the process may have survived the
+				// restart. Anything that can't be picked up again is
+				// killed rather than left running unmonitored.
+				if daemon.detachable(container) {
+					err := daemon.containerReattach(container)
+					if err == nil {
+						logrus.Debugf("Reattached to container %s", container.ID)
+						return
+					}
+					logrus.Errorf("Failed to reattach to container %s: %s", container.ID, err)
+				}
+				daemon.terminateStaleContainer(container)
+			}
+
 			// check the restart policy on the containers and restart any container with
 			// the restart policy of "always"
 			if daemon.configStore.AutoRestart && container.ShouldRestart() {
 				logrus.Debugf("Starting container %s", container.ID)
 
-				if err := daemon.containerStart(container); err != nil {
+				if err := daemon.containerStart(container, ""); err != nil {
 					logrus.Errorf("Failed to start container %s: %s", container.ID, err)
 				}
 			}
@@ -544,13 +570,13 @@ func (daemon *Daemon) getEventFilter(filter filters.Args) *events.Filter {
 			filter.Add("container", c.ID)
 		}
 	}
-	return events.NewFilter(filter, daemon.GetLabels)
+	return events.NewFilter(filter)
 }
 
-// SubscribeToEvents returns the currently record of events, a channel to stream new events from, and a function to cancel the stream of events.
-func (daemon *Daemon) SubscribeToEvents(since, sinceNano int64, filter filters.Args) ([]*jsonmessage.JSONMessage, chan interface{}) {
+// SubscribeToEvents returns the recorded events between since and until, and a channel to stream new events from.
+func (daemon *Daemon) SubscribeToEvents(since, until time.Time, filter filters.Args) ([]eventtypes.Message, chan interface{}) {
 	ef := daemon.getEventFilter(filter)
-	return daemon.EventsService.SubscribeTopic(since, sinceNano, ef)
+	return daemon.EventsService.SubscribeTopic(since, until, ef)
 }
 
 // UnsubscribeFromEvents stops the event subscription for a client by closing the
@@ -559,21 +585,6 @@ func (daemon *Daemon) UnsubscribeFromEvents(listener chan interface{}) {
 	daemon.EventsService.Evict(listener)
 }
 
-// GetLabels for a container or image id
-func (daemon *Daemon) GetLabels(id string) map[string]string {
-	// TODO: TestCase
-	container := daemon.containers.Get(id)
-	if container != nil {
-		return container.Config.Labels
-	}
-
-	img, err := daemon.GetImage(id)
-	if err == nil {
-		return img.ContainerConfig.Labels
-	}
-	return nil
-}
-
 // children returns all child containers of the container with the
 // given name. The containers are returned as a map from the container
 // name to a pointer to Container.
@@ -773,6 +784,13 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	}
 
 	eventsService := events.New()
+	if config.EventsJournal.Enabled {
+		journal, err := newEventsJournal(config)
+		if err != nil {
+			return nil, err
+		}
+		eventsService.SetJournal(journal)
+	}
 
 	tagStore, err := tag.NewTagStore(filepath.Join(imageRoot, "repositories.json"))
 	if err != nil {
@@ -796,7 +814,8 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 			return nil, fmt.Errorf("discovery advertise parsing failed (%v)", err)
 		}
 		config.ClusterAdvertise = advertise
-		d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, config.ClusterOpts)
+		d.discoveryStop = make(chan struct{})
+		d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, config.ClusterOpts, d.discoveryStop)
 		if err != nil {
 			return nil, fmt.Errorf("discovery initialization failed (%v)", err)
 		}
@@ -829,6 +848,10 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 		return nil, err
 	}
 
+	// The logs are streamed to the logging driver plugins through FIFOs
+	// under the exec root.
+	logger.SetPluginFifoRoot(filepath.Join(config.ExecRoot, "logging"))
+
 	d.ID = trustKey.PublicKey().KeyID()
 	d.repository = daemonRepo
 	d.containers = &contStore{s: make(map[string]*container.Container)}
@@ -845,6 +868,10 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	d.RegistryService = registryService
 	d.EventsService = eventsService
 	d.volumes = volStore
+	d.volumes.SetEventLogger(d.LogVolumeEvent)
+	plugins.HandleActivation(func(name string, m *plugins.Manifest) {
+		d.LogPluginEvent(name, "activate", map[string]string{"implements": strings.Join(m.Implements, ",")})
+	})
 	d.root = config.Root
 	d.uidMaps = uidMaps
 	d.gidMaps = gidMaps
@@ -904,6 +931,7 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	leftRunning := false
 	if daemon.containers != nil {
 		group := sync.WaitGroup{}
 		logrus.Debug("starting clean shutdown of all containers...")
@@ -911,6 +939,11 @@ func (daemon *Daemon) Shutdown() error {
 			if !cont.IsRunning() {
 				continue
 			}
+			if daemon.isDetached(cont) {
+				logrus.Debugf("leaving %s running", cont.ID)
+				leftRunning = true
+				continue
+			}
 			logrus.Debugf("stopping %s", cont.ID)
 			group.Add(1)
 			go func(c *container.Container) {
@@ -936,12 +969,19 @@ func (daemon *Daemon) Shutdown() error {
 		}
 	}
 
-	if daemon.driver != nil {
+	// The root filesystems of the containers left running stay mounted.
+	if daemon.driver != nil && !leftRunning {
 		if err := daemon.driver.Cleanup(); err != nil {
 			logrus.Errorf("Error during graph storage driver.Cleanup(): %v", err)
 		}
 	}
 
+	if daemon.EventsService != nil {
+		if err := daemon.EventsService.Close(); err != nil {
+			logrus.Errorf("Error closing the events journal: %v", err)
+		}
+	}
+
 	if err := daemon.cleanupMounts(); err != nil {
 		return err
 	}
@@ -994,13 +1034,63 @@ func (daemon *Daemon) Unmount(container *container.Container) {
 
 // Run uses the execution driver to run a given container
 func (daemon *Daemon) Run(c *container.Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
+	hooks, exited := daemon.containerHooks(c, startCallback)
+	defer exited()
+	return daemon.execDriver.Run(c.Command, pipes, hooks)
+}
+
+// Restore uses the execution driver to restore a given container from the
+// checkpoint stored in checkpointDir
+func (daemon *Daemon) Restore(c *container.Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback, checkpointDir string) (execdriver.ExitStatus, error) {
+	opts := &execdriver.CheckpointOpts{
+		ImagesDirectory: checkpointDir,
+		WorkDirectory:   checkpointDir,
+	}
+	hooks, exited := daemon.containerHooks(c, startCallback)
+	defer exited()
+	return daemon.execDriver.Restore(c.Command, pipes, hooks, opts)
+}
+
+// Reattach uses the execution driver to pick up a given container whose
+// process kept running while the daemon was restarted
+func (daemon *Daemon) Reattach(c *container.Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
+	hooks, exited := daemon.containerHooks(c, startCallback)
+	defer exited()
+	return daemon.execDriver.Reattach(c.Command, pipes, hooks)
+}
+
+// containerHooks returns the execution driver hooks used to run or restore c,
+// and a function to call once the process exited.
+func (daemon *Daemon) containerHooks(c *container.Container, startCallback execdriver.DriverCallback) (execdriver.Hooks, func()) {
+	started := make(chan struct{})
 	hooks := execdriver.Hooks{
-		Start: startCallback,
+		Start: func(processConfig *execdriver.ProcessConfig, pid int, chOOM <-chan struct{}) error {
+			if err := startCallback(processConfig, pid, chOOM); err != nil {
+				return err
+			}
+			close(started)
+			c.Lock()
+			daemon.initHealthMonitor(c)
+			c.Unlock()
+			return nil
+		},
 	}
 	hooks.PreStart = append(hooks.PreStart, func(processConfig *execdriver.ProcessConfig, pid int, chOOM <-chan struct{}) error {
 		return daemon.setNetworkNamespaceKey(c.ID, pid)
 	})
-	return daemon.execDriver.Run(c.Command, pipes, hooks)
+	exited := func() {
+		// If the process never started, the caller starting it may still
+		// hold the container lock, waiting for the driver to return; but
+		// then there is no health monitor to stop either.
+		select {
+		case <-started:
+			c.Lock()
+			daemon.stopHealthchecks(c)
+			c.Unlock()
+		default:
+		}
+	}
+	return hooks, exited
 }
 
 func (daemon *Daemon) kill(c *container.Container, sig int) error {
@@ -1034,7 +1124,7 @@ func (daemon *Daemon) TagImage(newTag reference.Named, imageName string) error {
 	if err := daemon.tagStore.AddTag(newTag, imageID, true); err != nil {
 		return err
 	}
-	daemon.EventsService.Log("tag", newTag.String(), "")
+	daemon.LogImageEvent(newTag.String(), newTag.String(), "tag")
 	return nil
 }
 
@@ -1042,16 +1132,16 @@ func (daemon *Daemon) TagImage(newTag reference.Named, imageName string) error {
 // tag may be either empty, or indicate a specific tag to pull.
 func (daemon *Daemon) PullImage(ref reference.Named, metaHeaders map[string][]string, authConfig *cliconfig.AuthConfig, outStream io.Writer) error {
 	imagePullConfig := &distribution.ImagePullConfig{
-		MetaHeaders:     metaHeaders,
-		AuthConfig:      authConfig,
-		OutStream:       outStream,
-		RegistryService: daemon.RegistryService,
-		EventsService:   daemon.EventsService,
-		MetadataStore:   daemon.distributionMetadataStore,
-		LayerStore:      daemon.layerStore,
-		ImageStore:      daemon.imageStore,
-		TagStore:        daemon.tagStore,
-		Pool:            daemon.distributionPool,
+		MetaHeaders:      metaHeaders,
+		AuthConfig:       authConfig,
+		OutStream:        outStream,
+		RegistryService:  daemon.RegistryService,
+		ImageEventLogger: daemon.LogImageEvent,
+		MetadataStore:    daemon.distributionMetadataStore,
+		LayerStore:       daemon.layerStore,
+		ImageStore:       daemon.imageStore,
+		TagStore:         daemon.tagStore,
+		Pool:             daemon.distributionPool,
 	}
 
 	return distribution.Pull(ref, imagePullConfig)
@@ -1070,16 +1160,16 @@ func (daemon *Daemon) ExportImage(names []string, outStream io.Writer) error {
 // PushImage initiates a push operation on the repository named localName.
 func (daemon *Daemon) PushImage(ref reference.Named, metaHeaders map[string][]string, authConfig *cliconfig.AuthConfig, outStream io.Writer) error {
 	imagePushConfig := &distribution.ImagePushConfig{
-		MetaHeaders:     metaHeaders,
-		AuthConfig:      authConfig,
-		OutStream:       outStream,
-		RegistryService: daemon.RegistryService,
-		EventsService:   daemon.EventsService,
-		MetadataStore:   daemon.distributionMetadataStore,
-		LayerStore:      daemon.layerStore,
-		ImageStore:      daemon.imageStore,
-		TagStore:        daemon.tagStore,
-		TrustKey:        daemon.trustKey,
+		MetaHeaders:      metaHeaders,
+		AuthConfig:       authConfig,
+		OutStream:        outStream,
+		RegistryService:  daemon.RegistryService,
+		ImageEventLogger: daemon.LogImageEvent,
+		MetadataStore:    daemon.distributionMetadataStore,
+		LayerStore:       daemon.layerStore,
+		ImageStore:       daemon.imageStore,
+		TagStore:         daemon.tagStore,
+		TrustKey:         daemon.trustKey,
 	}
 
 	return distribution.Push(ref, imagePushConfig)
@@ -1444,6 +1534,15 @@ func configureVolumes(config *Config, rootUID, rootGID int) (*store.VolumeStore,
 	return s, nil
 }
 
+// newEventsJournal opens the events journal under the root of the daemon.
+func newEventsJournal(config *Config) (*events.Journal, error) {
+	maxSize, err := units.RAMInBytes(config.EventsJournal.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid events journal size %q: %v", config.EventsJournal.MaxSize, err)
+	}
+	return events.NewJournal(filepath.Join(config.Root, "events"), maxSize, config.EventsJournal.MaxFiles)
+}
+
 // AuthenticateToRegistry checks the validity of credentials in authConfig
 func (daemon *Daemon) AuthenticateToRegistry(authConfig *cliconfig.AuthConfig) (string, error) {
 	return daemon.RegistryService.Auth(authConfig)
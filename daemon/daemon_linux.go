@@ -38,6 +38,10 @@ func (daemon *Daemon) cleanupMountsFromReader(reader io.Reader, unmount func(tar
 			mnt := fields[4]
 			mountBase := filepath.Base(mnt)
 			if mountBase == "mqueue" || mountBase == "shm" {
+				if daemon.keepsRunning(filepath.Base(filepath.Dir(mnt))) {
+					logrus.Debugf("Keeping %v of a container left running", mnt)
+					continue
+				}
 				logrus.Debugf("Unmounting %v", mnt)
 				if err := unmount(mnt); err != nil {
 					logrus.Error(err)
@@ -58,3 +62,23 @@ func (daemon *Daemon) cleanupMountsFromReader(reader io.Reader, unmount func(tar
 	logrus.Debugf("Cleaning up old shm/mqueue mounts: done.")
 	return nil
 }
+
+// keepsRunning returns whether the container with the given id is running
+// detached from the daemon, so that its mounts must not be cleaned up. On
+// startup the containers are not loaded yet, and the ones that will be
+// reattached are looked up on disk.
+func (daemon *Daemon) keepsRunning(id string) bool {
+	if daemon.containers != nil {
+		if c := daemon.containers.Get(id); c != nil {
+			return c.IsRunning() && daemon.isDetached(c)
+		}
+	}
+	if daemon.configStore == nil || !daemon.configStore.LiveRestore {
+		return false
+	}
+	c, err := daemon.load(id)
+	if err != nil {
+		return false
+	}
+	return c.IsRunning() && daemon.detachable(c)
+}
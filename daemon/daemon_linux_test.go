@@ -5,6 +5,9 @@ package daemon
 import (
 	"strings"
 	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
 )
 
 func TestCleanupMounts(t *testing.T) {
@@ -72,3 +75,32 @@ func TestNotCleanupMounts(t *testing.T) {
 		t.Fatalf("Expected not to clean up /dev/shm")
 	}
 }
+
+func TestNotCleanupMountsOfDetachedContainers(t *testing.T) {
+	detached := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:      "47903e2e67014246eba27607809d5f5c2437c3bf84c2986393448f84093cc40b",
+			State:   container.NewState(),
+			Command: &execdriver.Command{Detach: true},
+		},
+	}
+	detached.SetRunning(42)
+	d := &Daemon{
+		repository: "/var/lib/docker/containers",
+		containers: &contStore{s: map[string]*container.Container{detached.ID: detached}},
+	}
+	mountInfo := `133 230 0:55 / /var/lib/docker/containers/47903e2e67014246eba27607809d5f5c2437c3bf84c2986393448f84093cc40b/mqueue rw,nosuid,nodev,noexec,relatime - mqueue mqueue rw
+134 230 0:56 / /var/lib/docker/containers/dfac036ce135a8914e292cb2f6fea114f7339983c186366aa26d0051e93162cb/mqueue rw,nosuid,nodev,noexec,relatime - mqueue mqueue rw`
+
+	var unmounted []string
+	unmount := func(target string) error {
+		unmounted = append(unmounted, target)
+		return nil
+	}
+	d.cleanupMountsFromReader(strings.NewReader(mountInfo), unmount)
+
+	expected := "/var/lib/docker/containers/dfac036ce135a8914e292cb2f6fea114f7339983c186366aa26d0051e93162cb/mqueue"
+	if len(unmounted) != 1 || unmounted[0] != expected {
+		t.Fatalf("Expected to only unmount %s, got %v", expected, unmounted)
+	}
+}
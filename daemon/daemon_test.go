@@ -17,6 +17,25 @@ import (
 	"github.com/docker/docker/volume/store"
 )
 
+// newTestContainer returns a stopped container with a temporary root, which
+// the caller removes.
+func newTestContainer(t *testing.T, id string) *container.Container {
+	root, err := ioutil.TempDir("", "docker-daemon-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:         id,
+			Name:       "/" + id,
+			Root:       root,
+			State:      container.NewState(),
+			Config:     &runconfig.Config{},
+			HostConfig: &runconfig.HostConfig{},
+		},
+	}
+}
+
 //
 // https://github.com/docker/docker/issues/8069
 //
@@ -152,6 +171,24 @@ func TestParseSecurityOpt(t *testing.T) {
 		t.Fatalf("Unexpected AppArmorProfile, expected: \"test_profile\", got %q", container.AppArmorProfile)
 	}
 
+	// test seccomp, using the key=value form
+	profile := `{"defaultAction":"SCMP_ACT_ERRNO","syscalls":[{"name":"read","action":"SCMP_ACT_ALLOW"}]}`
+	config.SecurityOpt = []string{"seccomp=" + profile}
+	if err := parseSecurityOpt(container, config); err != nil {
+		t.Fatalf("Unexpected parseSecurityOpt error: %v", err)
+	}
+	if container.SeccompProfile != profile {
+		t.Fatalf("Unexpected SeccompProfile, expected: %q, got %q", profile, container.SeccompProfile)
+	}
+
+	config.SecurityOpt = []string{"seccomp:unconfined"}
+	if err := parseSecurityOpt(container, config); err != nil {
+		t.Fatalf("Unexpected parseSecurityOpt error: %v", err)
+	}
+	if container.SeccompProfile != "unconfined" {
+		t.Fatalf("Unexpected SeccompProfile, expected: \"unconfined\", got %q", container.SeccompProfile)
+	}
+
 	// test valid label
 	config.SecurityOpt = []string{"label:user:USER"}
 	if err := parseSecurityOpt(container, config); err != nil {
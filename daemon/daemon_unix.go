@@ -65,7 +65,7 @@ func parseSecurityOpt(container *container.Container, config *runconfig.HostConf
 	)
 
 	for _, opt := range config.SecurityOpt {
-		con := strings.SplitN(opt, ":", 2)
+		con := splitSecurityOpt(opt)
 		if len(con) == 1 {
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -74,6 +74,8 @@ func parseSecurityOpt(container *container.Container, config *runconfig.HostConf
 			labelOpts = append(labelOpts, con[1])
 		case "apparmor":
 			container.AppArmorProfile = con[1]
+		case "seccomp":
+			container.SeccompProfile = con[1]
 		default:
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -83,6 +85,19 @@ func parseSecurityOpt(container *container.Container, config *runconfig.HostConf
 	return err
 }
 
+// splitSecurityOpt splits a --security-opt value into its key and value. Both
+// the "key=value" and the older "key:value" forms are accepted; whichever
+// separator comes first wins, so values may themselves contain either one.
+func splitSecurityOpt(opt string) []string {
+	sep := ":"
+	if i := strings.Index(opt, "="); i >= 0 {
+		if j := strings.Index(opt, ":"); j < 0 || i < j {
+			sep = "="
+		}
+	}
+	return strings.SplitN(opt, sep, 2)
+}
+
 func checkKernelVersion(k, major, minor int) bool {
 	if v, err := kernel.GetKernelVersion(); err != nil {
 		logrus.Warnf("%s", err)
@@ -271,6 +286,9 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 		warnings = append(warnings, "IPv4 forwarding is disabled. Networking will not work.")
 		logrus.Warnf("IPv4 forwarding is disabled. Networking will not work")
 	}
+	if err := verifySeccompProfile(hostConfig.SecurityOpt); err != nil {
+		return warnings, err
+	}
 	return warnings, nil
 }
 
@@ -616,6 +634,23 @@ func (daemon *Daemon) registerLinks(container *container.Container, hostConfig *
 	return nil
 }
 
+// detachable returns whether the process of a container can keep running
+// while the daemon is restarted. Only containers without a TTY, whose network
+// does not depend on the sandboxes libnetwork cleans up on startup, qualify.
+func (daemon *Daemon) detachable(container *container.Container) bool {
+	if !daemon.configStore.LiveRestore || container.Config.Tty {
+		return false
+	}
+	mode := container.HostConfig.NetworkMode
+	return mode.IsHost() || mode.IsNone()
+}
+
+// isDetached returns whether the process of a running container was started
+// detached from the daemon.
+func (daemon *Daemon) isDetached(container *container.Container) bool {
+	return container.Command != nil && container.Command.Detach
+}
+
 // conditionalMountOnStart is a platform specific helper function during the
 // container start to call mount.
 func (daemon *Daemon) conditionalMountOnStart(container *container.Container) error {
@@ -7,6 +7,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/docker/docker/container"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -85,3 +86,38 @@ func TestAdjustCPUSharesNoAdjustment(t *testing.T) {
 		t.Error("Expected CPUShares to be unchanged")
 	}
 }
+
+func TestDetachable(t *testing.T) {
+	daemon := &Daemon{configStore: &Config{}}
+
+	newContainer := func(networkMode string, tty bool) *container.Container {
+		return &container.Container{
+			CommonContainer: container.CommonContainer{
+				Config:     &runconfig.Config{Tty: tty},
+				HostConfig: &runconfig.HostConfig{NetworkMode: runconfig.NetworkMode(networkMode)},
+			},
+		}
+	}
+
+	if daemon.detachable(newContainer("host", false)) {
+		t.Fatal("Containers must not be detached without --live-restore")
+	}
+
+	daemon.configStore.LiveRestore = true
+	for _, tc := range []struct {
+		networkMode string
+		tty         bool
+		expected    bool
+	}{
+		{"host", false, true},
+		{"none", false, true},
+		{"none", true, false},
+		{"bridge", false, false},
+		{"default", false, false},
+		{"container:foo", false, false},
+	} {
+		if actual := daemon.detachable(newContainer(tc.networkMode, tc.tty)); actual != tc.expected {
+			t.Fatalf("Expected detachable(%s, tty=%v) to be %v", tc.networkMode, tc.tty, tc.expected)
+		}
+	}
+}
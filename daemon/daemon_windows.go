@@ -124,6 +124,18 @@ func (daemon *Daemon) cleanupMounts() error {
 	return nil
 }
 
+// detachable returns whether the process of a container can keep running
+// while the daemon is restarted. This is not supported on Windows.
+func (daemon *Daemon) detachable(container *container.Container) bool {
+	return false
+}
+
+// isDetached returns whether the process of a running container was started
+// detached from the daemon. This is not supported on Windows.
+func (daemon *Daemon) isDetached(container *container.Container) bool {
+	return false
+}
+
 // conditionalMountOnStart is a platform specific helper function during the
 // container start to call mount.
 func (daemon *Daemon) conditionalMountOnStart(container *container.Container) error {
@@ -127,13 +127,24 @@ func (d Docker) Release(sessionID string, activeImages []string) {
 }
 
 // Copy copies/extracts a source FileInfo to a destination path inside a container
-// specified by a container object.
+// specified by a container object. The copied files are owned by owner, or by
+// the root user of the container if owner is nil.
 // TODO: make sure callers don't unnecessarily convert destPath with filepath.FromSlash (Copy does it already).
 // Copy should take in abstract paths (with slashes) and the implementation should convert it to OS-specific paths.
-func (d Docker) Copy(c *container.Container, destPath string, src builder.FileInfo, decompress bool) error {
+func (d Docker) Copy(c *container.Container, destPath string, src builder.FileInfo, decompress bool, owner *builder.Ownership) error {
 	srcPath := src.Path()
 	destExists := true
-	rootUID, rootGID := d.Daemon.GetRemappedUIDGID()
+	uid, gid := d.Daemon.GetRemappedUIDGID()
+	uidMaps, gidMaps := d.Daemon.GetUIDGIDMaps()
+	if owner != nil {
+		var err error
+		if uid, err = idtools.ToHost(owner.UID, uidMaps); err != nil {
+			return err
+		}
+		if gid, err = idtools.ToHost(owner.GID, gidMaps); err != nil {
+			return err
+		}
+	}
 
 	// Work in daemon-local OS specific file paths
 	destPath = filepath.FromSlash(destPath)
@@ -165,7 +176,7 @@ func (d Docker) Copy(c *container.Container, destPath string, src builder.FileIn
 		if err := d.Archiver.CopyWithTar(srcPath, destPath); err != nil {
 			return err
 		}
-		return fixPermissions(srcPath, destPath, rootUID, rootGID, destExists)
+		return fixPermissions(srcPath, destPath, uid, gid, destExists)
 	}
 	if decompress {
 		// Only try to untar if it is a file and that we've been told to decompress (when ADD-ing a remote file)
@@ -180,7 +191,7 @@ func (d Docker) Copy(c *container.Container, destPath string, src builder.FileIn
 		}
 
 		// try to successfully untar the orig
-		if err := d.Archiver.UntarPath(srcPath, tarDest); err == nil {
+		if err := d.untarPath(srcPath, tarDest, owner, uid, gid); err == nil {
 			return nil
 		} else if err != io.EOF {
 			logrus.Debugf("Couldn't untar to %s: %v", tarDest, err)
@@ -192,14 +203,33 @@ func (d Docker) Copy(c *container.Container, destPath string, src builder.FileIn
 		destPath = filepath.Join(destPath, src.Name())
 	}
 
-	if err := idtools.MkdirAllNewAs(filepath.Dir(destPath), 0755, rootUID, rootGID); err != nil {
+	if err := idtools.MkdirAllNewAs(filepath.Dir(destPath), 0755, uid, gid); err != nil {
 		return err
 	}
 	if err := d.Archiver.CopyFileWithTar(srcPath, destPath); err != nil {
 		return err
 	}
 
-	return fixPermissions(srcPath, destPath, rootUID, rootGID, destExists)
+	return fixPermissions(srcPath, destPath, uid, gid, destExists)
+}
+
+// untarPath extracts the archive at srcPath to dest. If owner isn't nil, the
+// extracted files are owned by uid and gid on the host instead of the owners
+// recorded in the archive.
+func (d Docker) untarPath(srcPath, dest string, owner *builder.Ownership, uid, gid int) error {
+	if owner == nil {
+		return d.Archiver.UntarPath(srcPath, dest)
+	}
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return d.Archiver.Untar(src, dest, &archive.TarOptions{
+		UIDMaps:   d.Archiver.UIDMaps,
+		GIDMaps:   d.Archiver.GIDMaps,
+		ChownOpts: &archive.TarChownOptions{UID: uid, GID: gid},
+	})
 }
 
 // GetCachedImage returns a reference to a cached image whose parent equals `parent`
@@ -212,6 +242,27 @@ func (d Docker) GetCachedImage(imgID string, cfg *runconfig.Config) (string, err
 	return cache.ID().String(), nil
 }
 
+// MakeImageCache returns an ImageCache that also looks up the build steps in
+// the history of the images referenced by `cacheFrom`.
+func (d Docker) MakeImageCache(cacheFrom []string) builder.ImageCache {
+	return imageCache{d.Daemon.MakeImageCache(cacheFrom)}
+}
+
+// imageCache implements builder.ImageCache on top of a daemon.ImageCache.
+type imageCache struct {
+	cache *daemon.ImageCache
+}
+
+// GetCachedImage returns a reference to a cached image whose parent equals `parent`
+// and runconfig equals `cfg`. A cache miss is expected to return an empty ID and a nil error.
+func (c imageCache) GetCachedImage(imgID string, cfg *runconfig.Config) (string, error) {
+	cache, err := c.cache.GetCachedImage(image.ID(imgID), cfg)
+	if cache == nil || err != nil {
+		return "", err
+	}
+	return cache.ID().String(), nil
+}
+
 // Kill stops the container execution abruptly.
 func (d Docker) Kill(container *container.Container) error {
 	return d.Daemon.Kill(container)
@@ -236,14 +287,14 @@ func (d Docker) Start(c *container.Container) error {
 // Following is specific to builder contexts
 
 // DetectContextFromRemoteURL returns a context and in certain cases the name of the dockerfile to be used
-// irrespective of user input.
+// irrespective of user input. For a Git endpoint, it also returns the commit checked out.
 // progressReader is only used if remoteURL is actually a URL (not empty, and not a Git endpoint).
-func DetectContextFromRemoteURL(r io.ReadCloser, remoteURL string, progressReader *progressreader.Config) (context builder.ModifiableContext, dockerfileName string, err error) {
+func DetectContextFromRemoteURL(r io.ReadCloser, remoteURL string, progressReader *progressreader.Config) (context builder.ModifiableContext, dockerfileName, gitCommit string, err error) {
 	switch {
 	case remoteURL == "":
 		context, err = builder.MakeTarSumContext(r)
 	case urlutil.IsGitURL(remoteURL):
-		context, err = builder.MakeGitContext(remoteURL)
+		context, gitCommit, err = builder.MakeGitContext(remoteURL)
 	case urlutil.IsURL(remoteURL):
 		context, err = builder.MakeRemoteContext(remoteURL, map[string]func(io.ReadCloser) (io.ReadCloser, error){
 			httputils.MimeTypes.TextPlain: func(rc io.ReadCloser) (io.ReadCloser, error) {
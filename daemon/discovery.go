@@ -56,8 +56,9 @@ func discoveryOpts(clusterOpts map[string]string) (time.Duration, time.Duration,
 }
 
 // initDiscovery initialized the nodes discovery subsystem by connecting to the specified backend
-// and start a registration loop to advertise the current node under the specified address.
-func initDiscovery(backend, address string, clusterOpts map[string]string) (discovery.Backend, error) {
+// and start a registration loop to advertise the current node under the specified address. The
+// loop runs until stop is closed.
+func initDiscovery(backend, address string, clusterOpts map[string]string, stop <-chan struct{}) (discovery.Backend, error) {
 
 	heartbeat, ttl, err := discoveryOpts(clusterOpts)
 	if err != nil {
@@ -71,7 +72,7 @@ func initDiscovery(backend, address string, clusterOpts map[string]string) (disc
 
 	// We call Register() on the discovery backend in a loop for the whole lifetime of the daemon,
 	// but we never actually Watch() for nodes appearing and disappearing for the moment.
-	go registrationLoop(discoveryBackend, address, heartbeat)
+	go registrationLoop(discoveryBackend, address, heartbeat, stop)
 	return discoveryBackend, nil
 }
 
@@ -82,11 +83,18 @@ func registerAddr(backend discovery.Backend, addr string) {
 }
 
 // registrationLoop registers the current node against the discovery backend using the specified
-// address. The function never returns, as registration against the backend comes with a TTL and
-// requires regular heartbeats.
-func registrationLoop(discoveryBackend discovery.Backend, address string, heartbeat time.Duration) {
+// address. Registration against the backend comes with a TTL and requires regular heartbeats, so
+// the function only returns when stop is closed.
+func registrationLoop(discoveryBackend discovery.Backend, address string, heartbeat time.Duration, stop <-chan struct{}) {
 	registerAddr(discoveryBackend, address)
-	for range time.Tick(heartbeat) {
-		registerAddr(discoveryBackend, address)
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			registerAddr(discoveryBackend, address)
+		case <-stop:
+			return
+		}
 	}
 }
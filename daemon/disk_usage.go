@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"sort"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/directory"
+)
+
+// SystemDiskUsage returns the disk space used by the images, the writable
+// layers of the containers and the volumes.
+func (daemon *Daemon) SystemDiskUsage() (*types.DiskUsage, error) {
+	du := &types.DiskUsage{
+		Images:     []*types.ImageDiskUsage{},
+		Containers: []*types.ContainerDiskUsage{},
+		Volumes:    []*types.VolumeDiskUsage{},
+	}
+
+	containers := daemon.List()
+	imageContainers := make(map[image.ID]int)
+	for _, c := range containers {
+		imageContainers[c.ImageID]++
+	}
+
+	// Only the images listed by `docker images` and the images used by
+	// containers are reported, the layers of the other intermediate images
+	// are accounted to their children.
+	images := make(map[image.ID][]layer.ChainID)
+	layerRefs := make(map[layer.ChainID]int)
+	usedLayers := make(map[layer.ChainID]bool)
+	for id, img := range daemon.imageStore.Map() {
+		refs := daemon.tagStore.References(id)
+		if len(refs) == 0 && len(daemon.imageStore.Children(id)) > 0 && imageContainers[id] == 0 {
+			continue
+		}
+		chain := daemon.imageChainIDs(img)
+		for _, chainID := range chain {
+			layerRefs[chainID]++
+			if imageContainers[id] > 0 {
+				usedLayers[chainID] = true
+			}
+		}
+		images[id] = chain
+	}
+
+	// The layers that no image used by a container references can be
+	// reclaimed by removing the images.
+	layerSizes := daemon.layerDiffSizes()
+	for chainID, size := range layerSizes {
+		du.LayersSize += size
+		if !usedLayers[chainID] {
+			du.ReclaimableLayersSize += size
+		}
+	}
+
+	for id, chain := range images {
+		img, err := daemon.imageStore.Get(id)
+		if err != nil {
+			continue
+		}
+		idu := &types.ImageDiskUsage{
+			ID:         id.String(),
+			RepoTags:   []string{},
+			Created:    img.Created.Unix(),
+			Containers: imageContainers[id],
+		}
+		for _, ref := range daemon.tagStore.References(id) {
+			if _, ok := ref.(reference.Tagged); ok {
+				idu.RepoTags = append(idu.RepoTags, ref.String())
+			}
+		}
+		for _, chainID := range chain {
+			size := layerSizes[chainID]
+			idu.Size += size
+			if layerRefs[chainID] > 1 {
+				idu.SharedSize += size
+			}
+		}
+		idu.UniqueSize = idu.Size - idu.SharedSize
+		du.Images = append(du.Images, idu)
+	}
+	sort.Sort(sort.Reverse(imageDiskUsageByCreated(du.Images)))
+
+	for _, c := range containers {
+		sizeRw, sizeRootFs := daemon.getSize(c)
+		du.Containers = append(du.Containers, &types.ContainerDiskUsage{
+			ID:         c.ID,
+			Names:      []string{c.Name},
+			Image:      c.Config.Image,
+			Created:    c.Created.Unix(),
+			Running:    c.IsRunning(),
+			SizeRw:     sizeRw,
+			SizeRootFs: sizeRootFs,
+		})
+	}
+
+	for _, v := range daemon.volumes.List() {
+		size := int64(-1)
+		if v.DriverName() == "local" {
+			if s, err := directory.Size(v.Path()); err == nil {
+				size = s
+			}
+		}
+		du.Volumes = append(du.Volumes, &types.VolumeDiskUsage{
+			Name:       v.Name(),
+			Driver:     v.DriverName(),
+			Mountpoint: v.Path(),
+			Size:       size,
+			RefCount:   int(daemon.volumes.Count(v)),
+		})
+	}
+
+	return du, nil
+}
+
+// imageChainIDs returns the chain IDs of the layers of the image, from the
+// top layer down to the base layer.
+func (daemon *Daemon) imageChainIDs(img *image.Image) []layer.ChainID {
+	chainID := img.RootFS.ChainID()
+	if chainID == "" {
+		return nil
+	}
+	l, err := daemon.layerStore.Get(chainID)
+	if err != nil {
+		return nil
+	}
+	defer layer.ReleaseAndLog(daemon.layerStore, l)
+
+	var chain []layer.ChainID
+	for p := l; p != nil; p = p.Parent() {
+		chain = append(chain, p.ChainID())
+	}
+	return chain
+}
+
+type imageDiskUsageByCreated []*types.ImageDiskUsage
+
+func (r imageDiskUsageByCreated) Len() int           { return len(r) }
+func (r imageDiskUsageByCreated) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r imageDiskUsageByCreated) Less(i, j int) bool { return r[i].Created < r[j].Created }
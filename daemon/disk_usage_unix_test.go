@@ -0,0 +1,182 @@
+// +build !windows
+
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/tag"
+	"github.com/docker/docker/volume/store"
+)
+
+type diskUsageLayer struct {
+	layer.Layer
+	diffIDs  []layer.DiffID
+	parent   *diskUsageLayer
+	diffSize int64
+}
+
+func (l *diskUsageLayer) ChainID() layer.ChainID { return layer.CreateChainID(l.diffIDs) }
+
+func (l *diskUsageLayer) Parent() layer.Layer {
+	if l.parent == nil {
+		return nil
+	}
+	return l.parent
+}
+
+func (l *diskUsageLayer) DiffSize() (int64, error) { return l.diffSize, nil }
+
+func (l *diskUsageLayer) Size() (int64, error) {
+	var size int64
+	for p := l; p != nil; p = p.parent {
+		size += p.diffSize
+	}
+	return size, nil
+}
+
+type diskUsageRWLayer struct {
+	layer.RWLayer
+	parent *diskUsageLayer
+	size   int64
+}
+
+func (l *diskUsageRWLayer) Path() (string, error) { return "", nil }
+func (l *diskUsageRWLayer) Parent() layer.Layer   { return l.parent }
+func (l *diskUsageRWLayer) Size() (int64, error)  { return l.size, nil }
+
+type diskUsageLayerStore struct {
+	layer.Store
+	layers   map[layer.ChainID]*diskUsageLayer
+	rwLayers map[string]*diskUsageRWLayer
+}
+
+func (s *diskUsageLayerStore) Get(chainID layer.ChainID) (layer.Layer, error) {
+	l, ok := s.layers[chainID]
+	if !ok {
+		return nil, layer.ErrLayerDoesNotExist
+	}
+	return l, nil
+}
+
+func (s *diskUsageLayerStore) Release(l layer.Layer) ([]layer.Metadata, error) { return nil, nil }
+
+func (s *diskUsageLayerStore) Mount(id string, parent layer.ChainID, label string, init layer.MountInit) (layer.RWLayer, error) {
+	return s.rwLayers[id], nil
+}
+
+func (s *diskUsageLayerStore) Unmount(id string) error { return nil }
+
+func (s *diskUsageLayerStore) add(parent *diskUsageLayer, name string, diffSize int64) *diskUsageLayer {
+	dgst, _ := digest.FromBytes([]byte(name))
+	l := &diskUsageLayer{parent: parent, diffSize: diffSize}
+	if parent != nil {
+		l.diffIDs = append(l.diffIDs, parent.diffIDs...)
+	}
+	l.diffIDs = append(l.diffIDs, layer.DiffID(dgst))
+	s.layers[l.ChainID()] = l
+	return l
+}
+
+type diskUsageImageStore struct {
+	image.Store
+	images   map[image.ID]*image.Image
+	children map[image.ID][]image.ID
+}
+
+func (s *diskUsageImageStore) Get(id image.ID) (*image.Image, error) { return s.images[id], nil }
+func (s *diskUsageImageStore) Map() map[image.ID]*image.Image        { return s.images }
+func (s *diskUsageImageStore) Children(id image.ID) []image.ID       { return s.children[id] }
+
+type diskUsageTagStore struct {
+	tag.Store
+	refs map[image.ID][]reference.Named
+}
+
+func (s *diskUsageTagStore) References(id image.ID) []reference.Named { return s.refs[id] }
+
+func diskUsageImage(id string, l *diskUsageLayer) (image.ID, *image.Image) {
+	return image.ID(id), &image.Image{RootFS: &image.RootFS{Type: "layers", DiffIDs: l.diffIDs}}
+}
+
+func TestSystemDiskUsage(t *testing.T) {
+	ls := &diskUsageLayerStore{layers: map[layer.ChainID]*diskUsageLayer{}}
+	base := ls.add(nil, "base", 100)
+	mid := ls.add(base, "mid", 200)
+	app := ls.add(mid, "app", 400)
+	other := ls.add(base, "other", 800)
+
+	// The untagged parent of app:1 is used by a container.
+	parentID, parentImg := diskUsageImage("parent", mid)
+	appID, appImg := diskUsageImage("app", app)
+	otherID, otherImg := diskUsageImage("other", other)
+	appRef, _ := reference.ParseNamed("app:1")
+	otherRef, _ := reference.ParseNamed("other:latest")
+
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:         "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+			Name:       "/tender_bardeen",
+			ImageID:    parentID,
+			State:      container.NewState(),
+			Config:     &runconfig.Config{Image: "parent"},
+			HostConfig: &runconfig.HostConfig{},
+		},
+	}
+	ls.rwLayers = map[string]*diskUsageRWLayer{c.ID: {parent: mid, size: 50}}
+
+	daemon := &Daemon{
+		containers: &contStore{s: map[string]*container.Container{c.ID: c}},
+		layerStore: ls,
+		imageStore: &diskUsageImageStore{
+			images:   map[image.ID]*image.Image{parentID: parentImg, appID: appImg, otherID: otherImg},
+			children: map[image.ID][]image.ID{parentID: {appID}},
+		},
+		tagStore: &diskUsageTagStore{refs: map[image.ID][]reference.Named{appID: {appRef}, otherID: {otherRef}}},
+		volumes:  store.New(),
+	}
+
+	du, err := daemon.SystemDiskUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if du.LayersSize != 1500 {
+		t.Fatalf("Expected the layers to use 1500 bytes, got %d", du.LayersSize)
+	}
+	// Only the layers of app:1 and other:latest can be reclaimed, the base
+	// layer they share with the parent image is used.
+	if du.ReclaimableLayersSize != 1200 {
+		t.Fatalf("Expected 1200 bytes of layers to be reclaimable, got %d", du.ReclaimableLayersSize)
+	}
+
+	expected := map[string][4]int64{
+		"parent": {300, 300, 0, 1},
+		"app":    {700, 300, 400, 0},
+		"other":  {900, 100, 800, 0},
+	}
+	if len(du.Images) != len(expected) {
+		t.Fatalf("Expected %d images, got %d", len(expected), len(du.Images))
+	}
+	for _, img := range du.Images {
+		e, ok := expected[img.ID]
+		if !ok {
+			t.Fatalf("Unexpected image %s", img.ID)
+		}
+		if actual := [4]int64{img.Size, img.SharedSize, img.UniqueSize, int64(img.Containers)}; actual != e {
+			t.Fatalf("Expected size, shared size, unique size and containers %v for image %s, got %v", e, img.ID, actual)
+		}
+	}
+
+	if len(du.Containers) != 1 {
+		t.Fatalf("Expected 1 container, got %d", len(du.Containers))
+	}
+	if cu := du.Containers[0]; cu.SizeRw != 50 || cu.SizeRootFs != 350 {
+		t.Fatalf("Expected a writable layer of 50 bytes over 300 bytes of image layers, got %d and %d", cu.SizeRw, cu.SizeRootFs)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
 )
 
@@ -13,17 +14,18 @@ const (
 	bufferSize  = 1024
 )
 
-// Events is pubsub channel for *jsonmessage.JSONMessage
+// Events is pubsub channel for events generated by the engine.
 type Events struct {
-	mu     sync.Mutex
-	events []*jsonmessage.JSONMessage
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []eventtypes.Message
+	pub     *pubsub.Publisher
+	journal *Journal
 }
 
 // New returns new *Events instance
 func New() *Events {
 	return &Events{
-		events: make([]*jsonmessage.JSONMessage, 0, eventsLimit),
+		events: make([]eventtypes.Message, 0, eventsLimit),
 		pub:    pubsub.NewPublisher(100*time.Millisecond, bufferSize),
 	}
 }
@@ -32,9 +34,9 @@ func New() *Events {
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion), and a function to call
 // to stop the stream of events.
-func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}, func()) {
+func (e *Events) Subscribe() ([]eventtypes.Message, chan interface{}, func()) {
 	e.mu.Lock()
-	current := make([]*jsonmessage.JSONMessage, len(e.events))
+	current := make([]eventtypes.Message, len(e.events))
 	copy(current, e.events)
 	l := e.pub.Subscribe()
 	e.mu.Unlock()
@@ -45,30 +47,48 @@ func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}, func
 	return current, l, cancel
 }
 
-// SubscribeTopic adds new listener to events, returns slice of 64 stored
-// last events, a channel in which you can expect new events (in form
-// of interface{}, so you need type assertion).
-func (e *Events) SubscribeTopic(since, sinceNano int64, ef *Filter) ([]*jsonmessage.JSONMessage, chan interface{}) {
+// SetJournal makes e record every event in journal, and replay the events
+// from journal when subscribers ask for events older than the ones kept in
+// memory.
+func (e *Events) SetJournal(journal *Journal) {
+	e.mu.Lock()
+	e.journal = journal
+	e.mu.Unlock()
+}
+
+// Close closes the journal of e, if there is one. Events logged afterwards
+// are only kept in memory.
+func (e *Events) Close() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	var buffered []*jsonmessage.JSONMessage
-	topic := func(m interface{}) bool {
-		return ef.Include(m.(*jsonmessage.JSONMessage))
+	if e.journal == nil {
+		return nil
 	}
+	err := e.journal.Close()
+	e.journal = nil
+	return err
+}
 
-	if since != -1 {
-		for i := len(e.events) - 1; i >= 0; i-- {
-			ev := e.events[i]
-			if ev.Time < since || ((ev.Time == since) && (ev.TimeNano < sinceNano)) {
-				break
-			}
-			if ef.filter.Len() == 0 || topic(ev) {
-				buffered = append([]*jsonmessage.JSONMessage{ev}, buffered...)
-			}
+// SubscribeTopic adds new listener to events, returns slice of stored
+// events that happened between since and until, a channel in which you
+// can expect new events (in form of interface{}, so you need type
+// assertion). No stored event is returned if since is zero, and until is
+// ignored when it is zero. The events older than the 64 last ones are
+// replayed from the journal, if there is one.
+func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtypes.Message, chan interface{}) {
+	topic := func(m interface{}) bool {
+		return ef.Include(m.(eventtypes.Message))
+	}
+	include := func(ev eventtypes.Message) bool {
+		t := time.Unix(0, ev.TimeNano)
+		if t.Before(since) || (!until.IsZero() && t.After(until)) {
+			return false
 		}
+		return ef.filter.Len() == 0 || topic(ev)
 	}
 
+	e.mu.Lock()
 	var ch chan interface{}
 	if ef.filter.Len() > 0 {
 		ch = e.pub.SubscribeTopic(topic)
@@ -76,7 +96,51 @@ func (e *Events) SubscribeTopic(since, sinceNano int64, ef *Filter) ([]*jsonmess
 		// Subscribe to all events if there are no filters
 		ch = e.pub.Subscribe()
 	}
+	if since.IsZero() {
+		e.mu.Unlock()
+		return nil, ch
+	}
+
+	current := make([]eventtypes.Message, len(e.events))
+	copy(current, e.events)
+	// The events kept in memory are enough when the oldest of them
+	// happened before since.
+	journal := e.journal
+	if len(current) > 0 && !time.Unix(0, current[0].TimeNano).After(since) {
+		journal = nil
+	}
+	e.mu.Unlock()
 
+	// Log writes an event to the journal before publishing it, so an event
+	// the reader misses is still sent to ch, which is already subscribed.
+	var reader *journalReader
+	if journal != nil {
+		var err error
+		if reader, err = journal.openReader(); err != nil {
+			logrus.Errorf("Error opening the events journal: %v", err)
+		}
+	}
+
+	var buffered []eventtypes.Message
+	if reader != nil {
+		err := reader.replay(since, func(ev eventtypes.Message) {
+			if include(ev) {
+				buffered = append(buffered, ev)
+			}
+		})
+		reader.Close()
+		if err == nil {
+			return buffered, ch
+		}
+		logrus.Errorf("Error replaying the events journal: %v", err)
+		buffered = nil
+	}
+
+	for _, ev := range current {
+		if include(ev) {
+			buffered = append(buffered, ev)
+		}
+	}
 	return buffered, ch
 }
 
@@ -87,9 +151,27 @@ func (e *Events) Evict(l chan interface{}) {
 
 // Log broadcasts event to listeners. Each listener has 100 millisecond for
 // receiving event or it will be skipped.
-func (e *Events) Log(action, id, from string) {
+func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
 	now := time.Now().UTC()
-	jm := &jsonmessage.JSONMessage{Status: action, ID: id, From: from, Time: now.Unix(), TimeNano: now.UnixNano()}
+	jm := eventtypes.Message{
+		Action:   action,
+		Type:     eventType,
+		Actor:    actor,
+		Time:     now.Unix(),
+		TimeNano: now.UnixNano(),
+	}
+
+	// fill deprecated fields for container and images
+	switch eventType {
+	case eventtypes.ContainerEventType:
+		jm.ID = actor.ID
+		jm.Status = action
+		jm.From = actor.Attributes["image"]
+	case eventtypes.ImageEventType:
+		jm.ID = actor.ID
+		jm.Status = action
+	}
+
 	e.mu.Lock()
 	if len(e.events) == cap(e.events) {
 		// discard oldest event
@@ -98,7 +180,16 @@ func (e *Events) Log(action, id, from string) {
 	} else {
 		e.events = append(e.events, jm)
 	}
+	journal := e.journal
 	e.mu.Unlock()
+
+	// The journal does its own locking, so that subscribers aren't blocked
+	// while it writes, rotates or compacts its segments.
+	if journal != nil {
+		if err := journal.Write(jm); err != nil {
+			logrus.Errorf("Error writing event to the journal: %v", err)
+		}
+	}
 	e.pub.Publish(jm)
 }
 
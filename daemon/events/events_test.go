@@ -5,7 +5,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/api/types/events"
 )
 
 func TestEventsLog(t *testing.T) {
@@ -18,10 +18,13 @@ func TestEventsLog(t *testing.T) {
 	if count != 2 {
 		t.Fatalf("Must be 2 subscribers, got %d", count)
 	}
-	e.Log("test", "cont", "image")
+	e.Log("test", events.ContainerEventType, events.Actor{
+		ID:         "cont",
+		Attributes: map[string]string{"image": "image"},
+	})
 	select {
 	case msg := <-l1:
-		jmsg, ok := msg.(*jsonmessage.JSONMessage)
+		jmsg, ok := msg.(events.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
@@ -42,7 +45,7 @@ func TestEventsLog(t *testing.T) {
 	}
 	select {
 	case msg := <-l2:
-		jmsg, ok := msg.(*jsonmessage.JSONMessage)
+		jmsg, ok := msg.(events.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
@@ -70,7 +73,7 @@ func TestEventsLogTimeout(t *testing.T) {
 
 	c := make(chan struct{})
 	go func() {
-		e.Log("test", "cont", "image")
+		e.Log("test", events.ContainerEventType, events.Actor{ID: "cont"})
 		close(c)
 	}()
 
@@ -88,7 +91,10 @@ func TestLogEvents(t *testing.T) {
 		action := fmt.Sprintf("action_%d", i)
 		id := fmt.Sprintf("cont_%d", i)
 		from := fmt.Sprintf("image_%d", i)
-		e.Log(action, id, from)
+		e.Log(action, events.ContainerEventType, events.Actor{
+			ID:         id,
+			Attributes: map[string]string{"image": from},
+		})
 	}
 	time.Sleep(50 * time.Millisecond)
 	current, l, _ := e.Subscribe()
@@ -97,16 +103,19 @@ func TestLogEvents(t *testing.T) {
 		action := fmt.Sprintf("action_%d", num)
 		id := fmt.Sprintf("cont_%d", num)
 		from := fmt.Sprintf("image_%d", num)
-		e.Log(action, id, from)
+		e.Log(action, events.ContainerEventType, events.Actor{
+			ID:         id,
+			Attributes: map[string]string{"image": from},
+		})
 	}
 	if len(e.events) != eventsLimit {
 		t.Fatalf("Must be %d events, got %d", eventsLimit, len(e.events))
 	}
 
-	var msgs []*jsonmessage.JSONMessage
+	var msgs []events.Message
 	for len(msgs) < 10 {
 		m := <-l
-		jm, ok := (m).(*jsonmessage.JSONMessage)
+		jm, ok := (m).(events.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", m)
 		}
@@ -2,48 +2,98 @@ package events
 
 import (
 	"github.com/docker/distribution/reference"
-	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/parsers/filters"
 )
 
 // Filter can filter out docker events from a stream
 type Filter struct {
-	filter    filters.Args
-	getLabels func(id string) map[string]string
+	filter filters.Args
 }
 
 // NewFilter creates a new Filter
-func NewFilter(filter filters.Args, getLabels func(id string) map[string]string) *Filter {
-	return &Filter{filter: filter, getLabels: getLabels}
+func NewFilter(filter filters.Args) *Filter {
+	return &Filter{filter: filter}
 }
 
 // Include returns true when the event ev is included by the filters
-func (ef *Filter) Include(ev *jsonmessage.JSONMessage) bool {
-	return ef.filter.ExactMatch("event", ev.Status) &&
-		ef.filter.ExactMatch("container", ev.ID) &&
-		ef.isImageIncluded(ev.ID, ev.From) &&
-		ef.isLabelFieldIncluded(ev.ID)
+func (ef *Filter) Include(ev events.Message) bool {
+	return ef.filter.ExactMatch("event", ev.Action) &&
+		ef.filter.ExactMatch("type", ev.Type) &&
+		ef.matchDaemon(ev) &&
+		ef.matchContainer(ev) &&
+		ef.matchVolume(ev) &&
+		ef.matchNetwork(ev) &&
+		ef.matchImage(ev) &&
+		ef.matchLabels(ev.Actor.Attributes)
 }
 
-func (ef *Filter) isLabelFieldIncluded(id string) bool {
+func (ef *Filter) matchLabels(attributes map[string]string) bool {
 	if !ef.filter.Include("label") {
 		return true
 	}
-	return ef.filter.MatchKVList("label", ef.getLabels(id))
+	return ef.filter.MatchKVList("label", attributes)
 }
 
-// The image filter will be matched against both event.ID (for image events)
-// and event.From (for container events), so that any container that was created
+func (ef *Filter) matchDaemon(ev events.Message) bool {
+	return ef.matchName(ev, events.DaemonEventType)
+}
+
+func (ef *Filter) matchContainer(ev events.Message) bool {
+	return ef.matchName(ev, events.ContainerEventType)
+}
+
+func (ef *Filter) matchVolume(ev events.Message) bool {
+	return ef.matchName(ev, events.VolumeEventType)
+}
+
+func (ef *Filter) matchNetwork(ev events.Message) bool {
+	return ef.matchName(ev, events.NetworkEventType)
+}
+
+// matchName matches the filter named after eventType against both the ID and
+// the name of the actor of the event. Events of other types never match.
+func (ef *Filter) matchName(ev events.Message, eventType string) bool {
+	if !ef.filter.Include(eventType) {
+		return true
+	}
+	if ev.Type != eventType {
+		return false
+	}
+	return ef.filter.ExactMatch(eventType, ev.Actor.ID) ||
+		ef.filter.ExactMatch(eventType, ev.Actor.Attributes["name"])
+}
+
+// matchImage matches against both event.Actor.ID (for image events)
+// and event.Actor.Attributes["image"] (for container events), so that any container that was created
 // from an image will be included in the image events. Also compare both
 // against the stripped repo name without any tags.
-func (ef *Filter) isImageIncluded(eventID string, eventFrom string) bool {
-	return ef.filter.ExactMatch("image", eventID) ||
-		ef.filter.ExactMatch("image", eventFrom) ||
-		ef.filter.ExactMatch("image", stripTag(eventID)) ||
-		ef.filter.ExactMatch("image", stripTag(eventFrom))
+func (ef *Filter) matchImage(ev events.Message) bool {
+	if !ef.filter.Include("image") {
+		return true
+	}
+
+	var id, imageName string
+	switch ev.Type {
+	case events.ImageEventType:
+		id = ev.Actor.ID
+		imageName = ev.Actor.Attributes["name"]
+	case events.ContainerEventType:
+		imageName = ev.Actor.Attributes["image"]
+	default:
+		return false
+	}
+
+	return ef.filter.ExactMatch("image", id) ||
+		ef.filter.ExactMatch("image", imageName) ||
+		ef.filter.ExactMatch("image", stripTag(id)) ||
+		ef.filter.ExactMatch("image", stripTag(imageName))
 }
 
 func stripTag(image string) string {
+	if image == "" {
+		return ""
+	}
 	ref, err := reference.ParseNamed(image)
 	if err != nil {
 		return image
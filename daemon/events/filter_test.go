@@ -0,0 +1,99 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func newTestFilter(t *testing.T, args ...string) *Filter {
+	f := filters.NewArgs()
+	for _, arg := range args {
+		var err error
+		f, err = filters.ParseFlag(arg, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewFilter(f)
+}
+
+func TestFilterInclude(t *testing.T) {
+	container := events.Message{
+		Type:   events.ContainerEventType,
+		Action: "start",
+		Actor: events.Actor{
+			ID:         "cont1",
+			Attributes: map[string]string{"name": "web", "image": "busybox:latest", "com.example": "yes"},
+		},
+	}
+	image := events.Message{
+		Type:   events.ImageEventType,
+		Action: "pull",
+		Actor: events.Actor{
+			ID:         "busybox:latest",
+			Attributes: map[string]string{"name": "busybox:latest"},
+		},
+	}
+	volume := events.Message{
+		Type:   events.VolumeEventType,
+		Action: "create",
+		Actor: events.Actor{
+			ID:         "data",
+			Attributes: map[string]string{"driver": "local"},
+		},
+	}
+	network := events.Message{
+		Type:   events.NetworkEventType,
+		Action: "connect",
+		Actor: events.Actor{
+			ID:         "net1",
+			Attributes: map[string]string{"name": "backend", "type": "bridge", "container": "cont1"},
+		},
+	}
+	daemon := events.Message{
+		Type:   events.DaemonEventType,
+		Action: "reload",
+		Actor: events.Actor{
+			ID:         "daemon1",
+			Attributes: map[string]string{"name": "host1"},
+		},
+	}
+
+	cases := []struct {
+		filters  []string
+		message  events.Message
+		expected bool
+	}{
+		{nil, volume, true},
+		{[]string{"type=volume"}, volume, true},
+		{[]string{"type=volume"}, container, false},
+		{[]string{"type=image", "type=container"}, container, true},
+		{[]string{"event=start"}, container, true},
+		{[]string{"event=start"}, network, false},
+		{[]string{"container=web"}, container, true},
+		{[]string{"container=cont1"}, container, true},
+		{[]string{"container=cont1"}, network, false},
+		{[]string{"image=busybox"}, container, true},
+		{[]string{"image=busybox"}, image, true},
+		{[]string{"image=busybox"}, volume, false},
+		{[]string{"volume=data"}, volume, true},
+		{[]string{"volume=other"}, volume, false},
+		{[]string{"network=backend"}, network, true},
+		{[]string{"network=net1"}, network, true},
+		{[]string{"network=backend"}, container, false},
+		{[]string{"daemon=host1"}, daemon, true},
+		{[]string{"daemon=daemon1", "event=reload"}, daemon, true},
+		{[]string{"daemon=host2"}, daemon, false},
+		{[]string{"label=com.example=yes"}, container, true},
+		{[]string{"label=com.example=no"}, container, false},
+		{[]string{"type=network", "event=connect", "network=backend"}, network, true},
+	}
+
+	for _, c := range cases {
+		if actual := newTestFilter(t, c.filters...).Include(c.message); actual != c.expected {
+			t.Fatalf("expected %v for filters %v and event %+v, got %v", c.expected, c.filters, c.message, actual)
+		}
+	}
+}
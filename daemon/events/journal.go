@@ -0,0 +1,375 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+const (
+	journalExt       = ".log"
+	journalTmpPrefix = "compact-"
+)
+
+// Journal is an on-disk log of events. Events are appended as JSON lines to
+// segment files named after an increasing sequence number, so that rotating
+// the journal never renames a file that a reader may be replaying. Once the
+// current segment reaches the maximum size, the closed segments are compacted
+// and a new segment is started.
+type Journal struct {
+	mu       sync.RWMutex
+	root     string
+	maxSize  int64 // maximum size of each segment
+	maxFiles int   // maximum number of segments, including the current one
+	f        *os.File
+	seq      uint64
+	size     int64
+}
+
+// journalSegment is a segment file of the journal.
+type journalSegment struct {
+	seq  uint64
+	path string
+	size int64
+}
+
+// NewJournal opens the journal stored in the root directory, creating it if
+// needed. The journal holds at most maxFiles segments of maxSize bytes. A
+// new segment is started every time the journal is opened, the previous ones
+// are compacted.
+func NewJournal(root string, maxSize int64, maxFiles int) (*Journal, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid events journal size %d, it must be positive", maxSize)
+	}
+	if maxFiles < 2 {
+		return nil, fmt.Errorf("invalid number of events journal files %d, it must be at least 2", maxFiles)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+
+	j := &Journal{
+		root:     root,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+
+	// Remove the leftovers of a compaction interrupted by a crash.
+	tmps, err := filepath.Glob(filepath.Join(root, journalTmpPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, tmp := range tmps {
+		os.Remove(tmp)
+	}
+
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+	if err := j.openSegment(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Write appends the event to the journal, starting a new segment first if the
+// event doesn't fit in the current one.
+func (j *Journal) Write(ev eventtypes.Message) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return fmt.Errorf("events journal is closed")
+	}
+	if j.size > 0 && j.size+int64(len(b)) > j.maxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := j.f.Write(b)
+	j.size += int64(n)
+	return err
+}
+
+// Close closes the current segment of the journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+// journalReader replays the events written to the journal before it was
+// opened.
+type journalReader struct {
+	files []*os.File
+	seq   uint64 // sequence number of the segment being written when opened
+	size  int64  // size of that segment when opened
+}
+
+// openReader opens the segments of the journal along with the size of the
+// current one, so that the events written so far can be replayed even if the
+// journal is rotated and compacted in the meantime: a compaction replaces or
+// removes segment files, but not the ones already opened.
+func (j *Journal) openReader() (*journalReader, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	segments, err := j.segments()
+	if err != nil {
+		return nil, err
+	}
+	r := &journalReader{seq: j.seq, size: j.size}
+	for _, s := range segments {
+		f, err := os.Open(s.path)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.files = append(r.files, f)
+	}
+	return r, nil
+}
+
+// replay calls fn, in order, for every event of the journal not before since.
+func (r *journalReader) replay(since time.Time, fn func(eventtypes.Message)) error {
+	for _, f := range r.files {
+		var rd io.Reader = f
+		if seq, _ := parseSegmentName(filepath.Base(f.Name())); seq == r.seq {
+			rd = io.LimitReader(f, r.size)
+		}
+		br := bufio.NewReader(rd)
+		for {
+			line, err := br.ReadBytes('\n')
+			if len(line) > 0 {
+				var ev eventtypes.Message
+				if err := json.Unmarshal(line, &ev); err != nil {
+					// Most likely an event cut short by a crash of
+					// the daemon.
+					logrus.Debugf("Skipping invalid event in journal %s: %v", f.Name(), err)
+				} else if !time.Unix(0, ev.TimeNano).Before(since) {
+					fn(ev)
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the segments opened by the reader.
+func (r *journalReader) Close() {
+	closeFiles(r.files)
+}
+
+// rotate closes the current segment, compacts the journal and starts a new
+// segment. It must be called with the lock held.
+func (j *Journal) rotate() error {
+	if err := j.f.Close(); err != nil {
+		return err
+	}
+	j.f = nil
+	if err := j.compact(); err != nil {
+		logrus.Errorf("Error compacting the events journal: %v", err)
+	}
+	return j.openSegment()
+}
+
+// openSegment starts a new segment after the existing ones.
+func (j *Journal) openSegment() error {
+	segments, err := j.segments()
+	if err != nil {
+		return err
+	}
+	seq := j.seq + 1
+	if len(segments) > 0 && segments[len(segments)-1].seq >= seq {
+		seq = segments[len(segments)-1].seq + 1
+	}
+	f, err := os.OpenFile(filepath.Join(j.root, segmentName(seq)), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	j.f = f
+	j.seq = seq
+	j.size = 0
+	return nil
+}
+
+// compact merges adjacent closed segments as long as they fit in a single
+// segment, which keeps the segments left by daemon restarts from pushing
+// older events out of the journal, and then removes the oldest segments to
+// leave room for a new segment. It must be called with the lock held and the
+// current segment closed.
+func (j *Journal) compact() error {
+	segments, err := j.segments()
+	if err != nil {
+		return err
+	}
+
+	var (
+		compacted []journalSegment
+		run       []journalSegment
+		runSize   int64
+	)
+	flush := func() error {
+		if len(run) > 1 {
+			s, err := j.merge(run)
+			if err != nil {
+				return err
+			}
+			compacted = append(compacted, s)
+		} else {
+			compacted = append(compacted, run...)
+		}
+		run, runSize = nil, 0
+		return nil
+	}
+	for _, s := range segments {
+		if len(run) > 0 && runSize+s.size > j.maxSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		run = append(run, s)
+		runSize += s.size
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	for len(compacted) > j.maxFiles-1 {
+		if err := os.Remove(compacted[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		compacted = compacted[1:]
+	}
+	return nil
+}
+
+// merge concatenates the segments into the last of them, and removes the
+// others.
+func (j *Journal) merge(segments []journalSegment) (journalSegment, error) {
+	last := segments[len(segments)-1]
+
+	tmp, err := ioutil.TempFile(j.root, journalTmpPrefix)
+	if err != nil {
+		return last, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	var size int64
+	for _, s := range segments {
+		b, err := ioutil.ReadFile(s.path)
+		if err != nil {
+			tmp.Close()
+			return last, err
+		}
+		// Terminate an event cut short by a crash so that it doesn't
+		// corrupt the first event of the next segment.
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			b = append(b, '\n')
+		}
+		n, err := tmp.Write(b)
+		size += int64(n)
+		if err != nil {
+			tmp.Close()
+			return last, err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return last, err
+	}
+	if err := tmp.Close(); err != nil {
+		return last, err
+	}
+	if err := os.Rename(tmpPath, last.path); err != nil {
+		return last, err
+	}
+	for _, s := range segments[:len(segments)-1] {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return last, err
+		}
+	}
+
+	last.size = size
+	return last, nil
+}
+
+// segments returns the segments of the journal sorted by sequence number.
+func (j *Journal) segments() ([]journalSegment, error) {
+	fis, err := ioutil.ReadDir(j.root)
+	if err != nil {
+		return nil, err
+	}
+	var segments []journalSegment
+	for _, fi := range fis {
+		seq, ok := parseSegmentName(fi.Name())
+		if !ok || fi.IsDir() {
+			continue
+		}
+		segments = append(segments, journalSegment{
+			seq:  seq,
+			path: filepath.Join(j.root, fi.Name()),
+			size: fi.Size(),
+		})
+	}
+	sort.Sort(bySeq(segments))
+	return segments, nil
+}
+
+type bySeq []journalSegment
+
+func (s bySeq) Len() int           { return len(s) }
+func (s bySeq) Less(i, j int) bool { return s[i].seq < s[j].seq }
+func (s bySeq) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func segmentName(seq uint64) string {
+	return fmt.Sprintf("%016d%s", seq, journalExt)
+}
+
+func parseSegmentName(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, journalExt) {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(strings.TrimSuffix(name, journalExt), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
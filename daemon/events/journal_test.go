@@ -0,0 +1,295 @@
+package events
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func newTestJournal(t *testing.T, maxSize int64, maxFiles int) (*Journal, string) {
+	root, err := ioutil.TempDir("", "docker-events-journal-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := NewJournal(root, maxSize, maxFiles)
+	if err != nil {
+		os.RemoveAll(root)
+		t.Fatal(err)
+	}
+	return j, root
+}
+
+func testEvent(i int) eventtypes.Message {
+	return eventtypes.Message{
+		Type:     eventtypes.ContainerEventType,
+		Action:   fmt.Sprintf("action_%d", i),
+		Actor:    eventtypes.Actor{ID: fmt.Sprintf("cont_%d", i)},
+		Time:     int64(1000 + i),
+		TimeNano: int64(1000+i) * int64(time.Second),
+	}
+}
+
+func replayAll(t *testing.T, j *Journal, since time.Time) []eventtypes.Message {
+	r, err := j.openReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	return replayReader(t, r, since)
+}
+
+func replayReader(t *testing.T, r *journalReader, since time.Time) []eventtypes.Message {
+	var evs []eventtypes.Message
+	if err := r.replay(since, func(ev eventtypes.Message) {
+		evs = append(evs, ev)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return evs
+}
+
+func TestJournalReplay(t *testing.T) {
+	j, root := newTestJournal(t, 1024*1024, 2)
+	defer os.RemoveAll(root)
+
+	for i := 0; i < 10; i++ {
+		if err := j.Write(testEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, err := j.openReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := j.Write(testEvent(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	evs := replayReader(t, r, time.Unix(1004, 0))
+	if len(evs) != 6 || evs[0].Action != "action_4" || evs[5].Action != "action_9" {
+		t.Fatalf("expected the events from action_4 to action_9, got %+v", evs)
+	}
+}
+
+func TestJournalReopen(t *testing.T) {
+	j, root := newTestJournal(t, 1024*1024, 3)
+	defer os.RemoveAll(root)
+
+	for i := 0; i < 3; i++ {
+		if err := j.Write(testEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate an event cut short by a crash.
+	f, err := os.OpenFile(filepath.Join(root, segmentName(1)), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(`{"Type":"container","Act`))
+	f.Close()
+
+	for n := 0; n < 5; n++ {
+		j, err = NewJournal(root, 1024*1024, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := j.Write(testEvent(3 + n)); err != nil {
+			t.Fatal(err)
+		}
+		if err := j.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	j, err = NewJournal(root, 1024*1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	segments, err := j.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("expected the small segments to be compacted in one, got %d segments", len(segments))
+	}
+	evs := replayAll(t, j, time.Unix(0, 0))
+	if len(evs) != 8 {
+		t.Fatalf("expected 8 events, got %d: %+v", len(evs), evs)
+	}
+	for i, ev := range evs {
+		if ev.Action != fmt.Sprintf("action_%d", i) {
+			t.Fatalf("expected action_%d, got %s", i, ev.Action)
+		}
+	}
+}
+
+func TestJournalRotate(t *testing.T) {
+	b := len(`{"Type":"container","Action":"action_10","Actor":{"ID":"cont_10","Attributes":null},"time":1010,"timeNano":1010000000000}`) + 1
+	// Two events per segment.
+	j, root := newTestJournal(t, int64(2*b), 3)
+	defer os.RemoveAll(root)
+	defer j.Close()
+
+	for i := 10; i < 20; i++ {
+		if err := j.Write(testEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	segments, err := j.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 3 {
+		t.Fatalf("expected 3 segments, got %d", len(segments))
+	}
+	evs := replayAll(t, j, time.Unix(0, 0))
+	if len(evs) != 6 || evs[0].Action != "action_14" || evs[5].Action != "action_19" {
+		t.Fatalf("expected the 6 most recent events, got %+v", evs)
+	}
+}
+
+func TestJournalReplayWhileCompacting(t *testing.T) {
+	b := len(`{"Type":"container","Action":"action_10","Actor":{"ID":"cont_10","Attributes":null},"time":1010,"timeNano":1010000000000}`) + 1
+	j, root := newTestJournal(t, int64(3*b), 3)
+	defer os.RemoveAll(root)
+
+	if err := j.Write(testEvent(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	j, err := NewJournal(root, int64(3*b), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if err := j.Write(testEvent(11)); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := j.openReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// Rotating merges the segment being written when the reader was opened
+	// with the previous one, under the name of the former.
+	for i := 12; i < 14; i++ {
+		if err := j.Write(testEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	segments, err := j.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("expected the journal to be compacted in 2 segments, got %d", len(segments))
+	}
+
+	evs := replayReader(t, r, time.Unix(0, 0))
+	if len(evs) != 2 || evs[0].Action != "action_10" || evs[1].Action != "action_11" {
+		t.Fatalf("expected the events written before the reader was opened, got %+v", evs)
+	}
+}
+
+func TestSubscribeTopicReplaysJournal(t *testing.T) {
+	j, root := newTestJournal(t, 1024*1024, 2)
+	defer os.RemoveAll(root)
+
+	e := New()
+	e.SetJournal(j)
+	defer e.Close()
+
+	since := time.Now()
+	for i := 0; i < eventsLimit+16; i++ {
+		e.Log(fmt.Sprintf("action_%d", i), eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	}
+
+	buffered, l := e.SubscribeTopic(since, time.Time{}, NewFilter(filters.NewArgs()))
+	defer e.Evict(l)
+	if len(buffered) != eventsLimit+16 {
+		t.Fatalf("expected %d events, got %d", eventsLimit+16, len(buffered))
+	}
+	if buffered[0].Action != "action_0" {
+		t.Fatalf("expected the first event to be action_0, got %s", buffered[0].Action)
+	}
+
+	until := time.Unix(0, buffered[9].TimeNano)
+	f := filters.NewArgs()
+	f.Add("event", "action_3")
+	f.Add("event", "action_12")
+	buffered, l2 := e.SubscribeTopic(since, until, NewFilter(f))
+	defer e.Evict(l2)
+	if len(buffered) != 1 || buffered[0].Action != "action_3" {
+		t.Fatalf("expected only action_3 before until, got %+v", buffered)
+	}
+
+	buffered, l3 := e.SubscribeTopic(time.Time{}, time.Time{}, NewFilter(filters.NewArgs()))
+	defer e.Evict(l3)
+	if len(buffered) != 0 {
+		t.Fatalf("expected no stored events without since, got %d", len(buffered))
+	}
+}
+
+func TestLogDoesNotBlockSubscribersOnJournal(t *testing.T) {
+	j, root := newTestJournal(t, 1024*1024, 2)
+	defer os.RemoveAll(root)
+
+	e := New()
+	e.SetJournal(j)
+	defer e.Close()
+
+	// Hold the journal as a slow write would.
+	j.mu.Lock()
+	logged := make(chan struct{})
+	go func() {
+		e.Log("slow", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+		close(logged)
+	}()
+
+	subscribed := make(chan []eventtypes.Message)
+	go func() {
+		for {
+			current, _, cancel := e.Subscribe()
+			cancel()
+			if len(current) > 0 {
+				subscribed <- current
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	select {
+	case current := <-subscribed:
+		if current[0].Action != "slow" {
+			t.Fatalf("expected the slow event, got %+v", current)
+		}
+	case <-time.After(10 * time.Second):
+		j.mu.Unlock()
+		t.Fatal("subscribing was blocked by the journal")
+	}
+	j.mu.Unlock()
+	<-logged
+
+	evs := replayAll(t, j, time.Unix(0, 0))
+	if len(evs) != 1 || evs[0].Action != "slow" {
+		t.Fatalf("expected the slow event in the journal, got %+v", evs)
+	}
+}
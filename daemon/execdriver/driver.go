@@ -72,6 +72,24 @@ type Driver interface {
 	// Unpause unpauses a container.
 	Unpause(c *Command) error
 
+	// Update updates the resource limits of a running container to
+	// match c.Resources.
+	Update(c *Command) error
+
+	// Checkpoint saves the state of a running container to
+	// opts.ImagesDirectory so that it can be restored later.
+	Checkpoint(c *Command, opts *CheckpointOpts) error
+
+	// Restore recreates a container from the checkpoint in
+	// opts.ImagesDirectory instead of executing a new process. Like Run,
+	// it blocks until the restored process exits and returns the exit code.
+	Restore(c *Command, pipes *Pipes, hooks Hooks, opts *CheckpointOpts) (ExitStatus, error)
+
+	// Reattach resumes monitoring a container whose process kept running
+	// while the daemon was restarted. It connects pipes to the process and
+	// blocks until it exits like Run.
+	Reattach(c *Command, pipes *Pipes, hooks Hooks) (ExitStatus, error)
+
 	// Name returns the name of the driver.
 	Name() string
 
@@ -95,6 +113,17 @@ type Driver interface {
 	SupportsHooks() bool
 }
 
+// CheckpointOpts holds the options used to checkpoint and restore a container.
+type CheckpointOpts struct {
+	// ImagesDirectory is where the checkpoint image files are written to
+	// or read from.
+	ImagesDirectory string
+	// WorkDirectory is where logs of the checkpoint tool are written.
+	WorkDirectory string
+	// LeaveRunning keeps the container running after it was checkpointed.
+	LeaveRunning bool
+}
+
 // CommonResources contains the resource configs for a driver that are
 // common across platforms.
 type CommonResources struct {
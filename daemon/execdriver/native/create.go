@@ -82,6 +82,10 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		container.AppArmorProfile = c.AppArmorProfile
 	}
 
+	if err := d.setupSeccomp(container, c); err != nil {
+		return nil, err
+	}
+
 	if err := execdriver.SetupCgroups(container, c); err != nil {
 		return nil, err
 	}
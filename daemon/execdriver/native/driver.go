@@ -128,6 +128,67 @@ type execOutput struct {
 // Run implements the exec driver Driver interface,
 // it calls libcontainer APIs to run a container.
 func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	return d.run(c, pipes, hooks, func(cont libcontainer.Container, p *libcontainer.Process) error {
+		return cont.Start(p)
+	})
+}
+
+// Restore implements the exec driver Driver interface,
+// it recreates the container from a checkpoint instead of starting a new process.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, opts *execdriver.CheckpointOpts) (execdriver.ExitStatus, error) {
+	return d.run(c, pipes, hooks, func(cont libcontainer.Container, p *libcontainer.Process) error {
+		if err := cont.Restore(p, &libcontainer.CriuOpts{
+			ImagesDirectory:         opts.ImagesDirectory,
+			WorkDirectory:           opts.WorkDirectory,
+			TcpEstablished:          true,
+			ExternalUnixConnections: true,
+			FileLocks:               true,
+		}); err != nil {
+			return err
+		}
+		// libcontainer does not run the prestart hooks on restore.
+		if len(hooks.PreStart) == 0 {
+			return nil
+		}
+		pid, err := p.Pid()
+		if err != nil {
+			return err
+		}
+		chOOM := make(chan struct{})
+		close(chOOM)
+		for _, fnHook := range hooks.PreStart {
+			if err := fnHook(&c.ProcessConfig, pid, chOOM); err != nil {
+				p.Signal(os.Kill)
+				p.Wait()
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Checkpoint implements the exec driver Driver interface,
+// it dumps the state of a running container with CRIU.
+func (d *Driver) Checkpoint(c *execdriver.Command, opts *execdriver.CheckpointOpts) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+	if active == nil {
+		return fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+	return active.Checkpoint(&libcontainer.CriuOpts{
+		ImagesDirectory:         opts.ImagesDirectory,
+		WorkDirectory:           opts.WorkDirectory,
+		LeaveRunning:            opts.LeaveRunning,
+		TcpEstablished:          true,
+		ExternalUnixConnections: true,
+		FileLocks:               true,
+	})
+}
+
+// run creates the libcontainer container for c and uses start to bring its
+// init process to life, then waits for it to exit.
+func (d *Driver) run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, start func(libcontainer.Container, *libcontainer.Process) error) (execdriver.ExitStatus, error) {
 	destroyed := false
 	var err error
 	c.TmpDir, err = ioutil.TempDir("", c.ID)
@@ -149,7 +210,22 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		User: c.ProcessConfig.User,
 	}
 
-	if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
+	// the container's ends of its stdio FIFOs are only needed until it started
+	var closeAfterStart []io.Closer
+	closeStdio := func() {
+		for _, closer := range closeAfterStart {
+			closer.Close()
+		}
+		closeAfterStart = nil
+	}
+	defer closeStdio()
+
+	if c.Detach {
+		closeAfterStart, err = d.setupDetachedPipes(container, c, p, pipes)
+	} else {
+		err = setupPipes(container, &c.ProcessConfig, p, pipes)
+	}
+	if err != nil {
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
@@ -167,7 +243,9 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		d.cleanContainer(c.ID)
 	}()
 
-	if err := cont.Start(p); err != nil {
+	err = start(cont, p)
+	closeStdio()
+	if err != nil {
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
@@ -305,6 +383,22 @@ func (d *Driver) Unpause(c *execdriver.Command) error {
 	return active.Resume()
 }
 
+// Update implements the exec driver Driver interface,
+// it applies the command's resources to the cgroups of a running container.
+func (d *Driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+	if active == nil {
+		return fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+	config := active.Config()
+	if err := execdriver.SetupCgroups(&config, c); err != nil {
+		return err
+	}
+	return active.Set(config)
+}
+
 // Terminate implements the exec driver Driver interface.
 func (d *Driver) Terminate(c *execdriver.Command) error {
 	defer d.cleanContainer(c.ID)
@@ -358,6 +452,7 @@ func (d *Driver) cleanContainer(id string) error {
 	d.Lock()
 	delete(d.activeContainers, id)
 	d.Unlock()
+	os.RemoveAll(d.stdioDir(id))
 	return os.RemoveAll(filepath.Join(d.root, id))
 }
 
@@ -0,0 +1,144 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bytes"
+	"encoding/binary"
+	"syscall"
+	"time"
+
+	"github.com/opencontainers/runc/libcontainer/utils"
+	"github.com/vishvananda/netlink/nl"
+)
+
+// The process events connector reports the exit status of every process of
+// the host, which is the only way to learn how a process that isn't a child
+// of the daemon exited. See linux/cn_proc.h.
+const (
+	netlinkConnector = 11 // NETLINK_CONNECTOR
+	cnIdxProc        = 1  // CN_IDX_PROC
+	cnValProc        = 1  // CN_VAL_PROC
+
+	procCnMcastListen = 1          // PROC_CN_MCAST_LISTEN
+	procEventExit     = 0x80000000 // PROC_EVENT_EXIT
+
+	cnMsgLen         = 20 // struct cn_msg
+	procEventHdrLen  = 16 // what, cpu and timestamp_ns of struct proc_event
+	procEventExitLen = 16 // pid, tgid, exit_code and exit_signal
+)
+
+// cnMsg is the header of the messages of the connector, struct cn_msg.
+type cnMsg struct {
+	Idx   uint32
+	Val   uint32
+	Seq   uint32
+	Ack   uint32
+	Len   uint16
+	Flags uint16
+}
+
+// exitListener receives the exit events of the processes of the host.
+type exitListener struct {
+	fd int
+}
+
+// newExitListener subscribes to the process events. It fails if the kernel
+// was built without the process events connector or the caller lacks
+// CAP_NET_ADMIN.
+func newExitListener(timeout time.Duration) (*exitListener, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, netlinkConnector)
+	if err != nil {
+		return nil, err
+	}
+	l := &exitListener{fd: fd}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: cnIdxProc}); err != nil {
+		l.Close()
+		return nil, err
+	}
+	tv := syscall.NsecToTimeval(timeout.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	hdr := syscall.NlMsghdr{
+		Len:  syscall.NLMSG_HDRLEN + cnMsgLen + 4,
+		Type: syscall.NLMSG_DONE,
+	}
+	binary.Write(buf, nl.NativeEndian(), hdr)
+	binary.Write(buf, nl.NativeEndian(), cnMsg{Idx: cnIdxProc, Val: cnValProc, Len: 4})
+	binary.Write(buf, nl.NativeEndian(), uint32(procCnMcastListen))
+	if err := syscall.Sendto(fd, buf.Bytes(), 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// wait waits for the process with the given pid to exit and returns its
+// exit code. running is called whenever no event was received for the
+// timeout of the listener; if the process isn't running anymore and its exit
+// was missed, false is returned.
+func (l *exitListener) wait(pid int, running func() bool) (int, bool) {
+	buf := make([]byte, syscall.Getpagesize())
+	gone := false
+	for {
+		n, _, err := syscall.Recvfrom(l.fd, buf, 0)
+		if err != nil {
+			// On ENOBUFS events were dropped, the process is then found
+			// gone once it exits.
+			if err == syscall.EINTR || err == syscall.ENOBUFS {
+				continue
+			}
+			if err != syscall.EAGAIN {
+				return -1, false
+			}
+			// The exit is reported before the process goes away, so the
+			// event is already queued if the process is found gone: it
+			// is read on the next round.
+			if gone {
+				return -1, false
+			}
+			gone = !running()
+			continue
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, m := range msgs {
+			if status, ok := parseExitEvent(m.Data, pid); ok {
+				return utils.ExitStatus(syscall.WaitStatus(status)), true
+			}
+		}
+	}
+}
+
+// Close stops listening to the events.
+func (l *exitListener) Close() error {
+	return syscall.Close(l.fd)
+}
+
+// parseExitEvent returns the wait status of the process pid from the data
+// of a message of the connector, if it reports that this process exited.
+func parseExitEvent(data []byte, pid int) (uint32, bool) {
+	if len(data) < cnMsgLen+procEventHdrLen+procEventExitLen {
+		return 0, false
+	}
+	if nl.NativeEndian().Uint32(data[0:4]) != cnIdxProc || nl.NativeEndian().Uint32(data[4:8]) != cnValProc {
+		return 0, false
+	}
+	event := data[cnMsgLen:]
+	if nl.NativeEndian().Uint32(event[0:4]) != procEventExit {
+		return 0, false
+	}
+	exit := event[procEventHdrLen:]
+	epid := nl.NativeEndian().Uint32(exit[0:4])
+	tgid := nl.NativeEndian().Uint32(exit[4:8])
+	if int(epid) != pid || epid != tgid {
+		return 0, false
+	}
+	return nl.NativeEndian().Uint32(exit[8:12]), true
+}
@@ -0,0 +1,219 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+// reattachPollInterval is how often the process of a reattached container
+// is checked for exit. It is not our child anymore, so it can't be waited for.
+const reattachPollInterval = 100 * time.Millisecond
+
+// Reattach implements the exec driver Driver interface,
+// it picks up a container whose process kept running while the daemon was
+// restarted. The exit code of the process is taken from the process events of
+// the kernel; -1 is reported if they are not available.
+func (d *Driver) Reattach(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	cont, err := d.factory.Load(c.ID)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	state, err := cont.State()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	pid, startTime := state.InitProcessPid, state.InitProcessStartTime
+	running := func() bool {
+		return processRunning(pid, startTime)
+	}
+	// Listen before checking that the process is running, so that its exit
+	// can't be missed in between.
+	exits, err := newExitListener(reattachPollInterval)
+	if err != nil {
+		logrus.Warnf("Can't get the exit code of container %s: %v", c.ID, err)
+	} else {
+		defer exits.Close()
+	}
+	if !running() {
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("process of container %s is not running", c.ID)
+	}
+	if err := d.reattachPipes(c, pipes); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	d.Lock()
+	d.activeContainers[c.ID] = cont
+	d.Unlock()
+	defer d.cleanContainer(c.ID)
+
+	// the network namespace is joined to a new sandbox, like the prestart
+	// hook set up by createNetwork does when the container is started
+	if c.Network != nil && c.Network.ContainerID == "" && c.Network.NamespacePath == "" {
+		chOOM := make(chan struct{})
+		close(chOOM)
+		for _, fnHook := range hooks.PreStart {
+			if err := fnHook(&c.ProcessConfig, pid, chOOM); err != nil {
+				return execdriver.ExitStatus{ExitCode: -1}, err
+			}
+		}
+	}
+
+	oom := notifyOnOOM(cont)
+	if hooks.Start != nil {
+		hooks.Start(&c.ProcessConfig, pid, oom)
+	}
+
+	exitCode := -1
+	if exits != nil {
+		if code, ok := exits.wait(pid, running); ok {
+			exitCode = code
+		}
+	}
+	for running() {
+		time.Sleep(reattachPollInterval)
+	}
+	if nss := cont.Config().Namespaces; !nss.Contains(configs.NEWPID) {
+		killCgroupProcs(cont)
+	}
+	cont.Destroy()
+	_, oomKill := <-oom
+	return execdriver.ExitStatus{ExitCode: exitCode, OOMKilled: oomKill}, nil
+}
+
+// processRunning returns whether the process with the given pid is still
+// the one that was started at startTime.
+func processRunning(pid int, startTime string) bool {
+	current, err := system.GetProcessStartTime(pid)
+	return err == nil && current == startTime
+}
+
+// stdioDir returns the directory holding the stdio FIFOs of a detached
+// container.
+func (d *Driver) stdioDir(id string) string {
+	return filepath.Join(d.root, "stdio", id)
+}
+
+// setupDetachedPipes connects the stdio of a detached container to FIFOs
+// instead of pipes so that the daemon can go away and reattach to them later.
+// The container keeps stdout and stderr open for reading as well: while no
+// daemon is around its writes are buffered by the kernel instead of failing.
+// Stdin is closed when the daemon exits. The returned files are the
+// container's ends, to be closed once the process has been started.
+func (d *Driver) setupDetachedPipes(container *configs.Config, c *execdriver.Command, p *libcontainer.Process, pipes *execdriver.Pipes) ([]io.Closer, error) {
+	rootuid, err := container.HostUID()
+	if err != nil {
+		return nil, err
+	}
+	dir := d.stdioDir(c.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	term := &execdriver.StdConsole{}
+	c.ProcessConfig.Terminal = term
+
+	var closeAfterStart []io.Closer
+	stdout, err := setupFifoOutput(filepath.Join(dir, "stdout"), rootuid, pipes.Stdout, term)
+	if err != nil {
+		return closeAfterStart, err
+	}
+	closeAfterStart = append(closeAfterStart, stdout)
+	p.Stdout = stdout
+
+	stderr, err := setupFifoOutput(filepath.Join(dir, "stderr"), rootuid, pipes.Stderr, term)
+	if err != nil {
+		return closeAfterStart, err
+	}
+	closeAfterStart = append(closeAfterStart, stderr)
+	p.Stderr = stderr
+
+	if pipes.Stdin != nil {
+		path := filepath.Join(dir, "stdin")
+		if err := mkfifo(path, rootuid); err != nil {
+			return closeAfterStart, err
+		}
+		r, err := openFifoReader(path)
+		if err != nil {
+			return closeAfterStart, err
+		}
+		closeAfterStart = append(closeAfterStart, r)
+		w, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return closeAfterStart, err
+		}
+		go func() {
+			io.Copy(w, pipes.Stdin)
+			w.Close()
+		}()
+		p.Stdin = r
+	}
+	return closeAfterStart, nil
+}
+
+// reattachPipes connects pipes to the stdout and stderr FIFOs of a detached
+// container. Stdin was closed when the previous daemon exited.
+func (d *Driver) reattachPipes(c *execdriver.Command, pipes *execdriver.Pipes) error {
+	term := &execdriver.StdConsole{}
+	for name, w := range map[string]io.Writer{"stdout": pipes.Stdout, "stderr": pipes.Stderr} {
+		r, err := openFifoReader(filepath.Join(d.stdioDir(c.ID), name))
+		if err != nil {
+			term.Close()
+			return err
+		}
+		term.Closers = append(term.Closers, r)
+		if w != nil {
+			go io.Copy(w, r)
+		}
+	}
+	c.ProcessConfig.Terminal = term
+	return nil
+}
+
+// setupFifoOutput creates an output FIFO copied to w and returns the
+// container's end of it.
+func setupFifoOutput(path string, uid int, w io.Writer, term *execdriver.StdConsole) (*os.File, error) {
+	if err := mkfifo(path, uid); err != nil {
+		return nil, err
+	}
+	r, err := openFifoReader(path)
+	if err != nil {
+		return nil, err
+	}
+	term.Closers = append(term.Closers, r)
+	if w != nil {
+		go io.Copy(w, r)
+	}
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
+
+func mkfifo(path string, uid int) error {
+	if err := syscall.Mkfifo(path, 0600); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return os.Chown(path, uid, uid)
+}
+
+// openFifoReader opens the read end of a FIFO without waiting for a writer.
+func openFifoReader(path string) (*os.File, error) {
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
@@ -0,0 +1,128 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+func TestFifoOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-fifo-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	term := &execdriver.StdConsole{}
+	defer term.Close()
+	out := &syncBuffer{}
+	w, err := setupFifoOutput(filepath.Join(dir, "stdout"), os.Getuid(), out, term)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, out, "hello\n")
+}
+
+func TestFifoOutputWithoutReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-fifo-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stdout")
+
+	term := &execdriver.StdConsole{}
+	w, err := setupFifoOutput(path, os.Getuid(), nil, term)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// The daemon going away must not break the writer.
+	term.Close()
+	if _, err := w.Write([]byte("while away\n")); err != nil {
+		t.Fatalf("Write without a reader failed: %v", err)
+	}
+
+	// A new reader picks up what was buffered in the meantime.
+	r, err := openFifoReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "while away\n" {
+		t.Fatalf("Expected buffered output, got %q", buf[:n])
+	}
+}
+
+func TestExitListener(t *testing.T) {
+	for script, expected := range map[string]int{"exit 3": 3, "kill -9 $$": 137} {
+		l, err := newExitListener(reattachPollInterval)
+		if err != nil {
+			t.Skipf("Process events are not available: %v", err)
+		}
+		cmd := exec.Command("sh", "-c", script)
+		if err := cmd.Start(); err != nil {
+			l.Close()
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(10 * time.Second)
+		code, ok := l.wait(cmd.Process.Pid, func() bool {
+			return time.Now().Before(deadline)
+		})
+		cmd.Wait()
+		l.Close()
+		if !ok {
+			t.Fatalf("Expected the exit of %q to be reported", script)
+		}
+		if code != expected {
+			t.Fatalf("Expected exit code %d for %q, got %d", expected, script, code)
+		}
+	}
+}
+
+func waitForOutput(t *testing.T, out *syncBuffer, expected string) {
+	for i := 0; i < 100; i++ {
+		if out.String() == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected output %q, got %q", expected, out.String())
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
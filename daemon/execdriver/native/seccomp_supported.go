@@ -0,0 +1,6 @@
+// +build linux,cgo,seccomp
+
+package native
+
+// seccompEnabled is true when the driver was built with seccomp support.
+const seccompEnabled = true
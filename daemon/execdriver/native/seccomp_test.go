@@ -0,0 +1,66 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestLoadSeccompProfile(t *testing.T) {
+	profile := `{
+		"defaultAction": "SCMP_ACT_ERRNO",
+		"architectures": ["SCMP_ARCH_X86"],
+		"syscalls": [
+			{"name": "read", "action": "SCMP_ACT_ALLOW", "args": []},
+			{"name": "personality", "action": "SCMP_ACT_ALLOW", "args": [{"index": 0, "value": 8, "valueTwo": 0, "op": "SCMP_CMP_EQ"}]}
+		]
+	}`
+
+	config, err := loadSeccompProfile(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DefaultAction != configs.Errno {
+		t.Fatalf("Expected default action %v, got %v", configs.Errno, config.DefaultAction)
+	}
+	if len(config.Architectures) != 1 || config.Architectures[0] != "x86" {
+		t.Fatalf("Unexpected architectures %v", config.Architectures)
+	}
+	if len(config.Syscalls) != 2 {
+		t.Fatalf("Expected 2 syscalls, got %d", len(config.Syscalls))
+	}
+	arg := config.Syscalls[1].Args[0]
+	if arg.Value != 8 || arg.Op != configs.EqualTo {
+		t.Fatalf("Unexpected argument rule %+v", arg)
+	}
+}
+
+func TestLoadSeccompProfileInvalid(t *testing.T) {
+	for _, profile := range []string{
+		`not json`,
+		`{"defaultAction": "SCMP_ACT_NOPE"}`,
+		`{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "read", "action": "SCMP_ACT_ALLOW", "args": [{"op": "SCMP_CMP_NOPE"}]}]}`,
+	} {
+		if _, err := loadSeccompProfile(profile); err == nil {
+			t.Fatalf("Expected error loading profile %s", profile)
+		}
+	}
+}
+
+func TestDefaultSeccompProfile(t *testing.T) {
+	config, err := getDefaultSeccompProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DefaultAction != configs.Errno {
+		t.Fatalf("Expected default action %v, got %v", configs.Errno, config.DefaultAction)
+	}
+	for _, call := range config.Syscalls {
+		switch call.Name {
+		case "unshare", "setns", "mount", "init_module", "settimeofday":
+			t.Fatalf("Syscall %s must not be allowed by the default profile", call.Name)
+		}
+	}
+}
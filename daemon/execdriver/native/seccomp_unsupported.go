@@ -0,0 +1,5 @@
+// +build linux,cgo,!seccomp
+
+package native
+
+const seccompEnabled = false
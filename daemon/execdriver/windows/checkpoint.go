@@ -0,0 +1,19 @@
+// +build windows
+
+package windows
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// Checkpoint implements the exec driver Driver interface.
+func (d *Driver) Checkpoint(c *execdriver.Command, opts *execdriver.CheckpointOpts) error {
+	return fmt.Errorf("Windows: Containers cannot be checkpointed")
+}
+
+// Restore implements the exec driver Driver interface.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, opts *execdriver.CheckpointOpts) (execdriver.ExitStatus, error) {
+	return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("Windows: Containers cannot be restored")
+}
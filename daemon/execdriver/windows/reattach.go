@@ -0,0 +1,14 @@
+// +build windows
+
+package windows
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// Reattach implements the exec driver Driver interface.
+func (d *Driver) Reattach(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("Windows: Containers cannot be reattached")
+}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/runconfig"
+)
+
+func reset(c *container.Container) {
+	c.State = &container.State{}
+	c.State.Health = &container.Health{}
+	c.State.Health.Status = types.Starting
+}
+
+func TestHealthStates(t *testing.T) {
+	e := events.New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	expect := func(expected string) {
+		select {
+		case event := <-l:
+			ev := event.(eventtypes.Message)
+			if ev.Status != expected {
+				t.Errorf("Expecting event %#v, but got %#v\n", expected, ev.Status)
+			}
+		case <-time.After(1 * time.Second):
+			t.Errorf("Expecting event %#v, but got nothing\n", expected)
+		}
+	}
+
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:   "container_id",
+			Name: "container_name",
+			Config: &runconfig.Config{
+				Image: "image_name",
+			},
+		},
+	}
+	daemon := &Daemon{
+		EventsService: e,
+	}
+
+	c.Config.Healthcheck = &runconfig.HealthConfig{
+		Retries: 1,
+	}
+
+	reset(c)
+	stop := make(chan struct{})
+
+	handleResult := func(startTime time.Time, exitCode int) {
+		handleProbeResult(daemon, c, stop, &types.HealthcheckResult{
+			Start:    startTime,
+			End:      startTime,
+			ExitCode: exitCode,
+		})
+	}
+
+	// starting -> failed -> success -> failed
+
+	handleResult(c.State.StartedAt.Add(1*time.Second), 1)
+	expect("health_status: unhealthy")
+
+	handleResult(c.State.StartedAt.Add(2*time.Second), 0)
+	expect("health_status: healthy")
+
+	handleResult(c.State.StartedAt.Add(3*time.Second), 1)
+	expect("health_status: unhealthy")
+
+	// starting -> failed -> success
+	reset(c)
+	handleResult(c.State.StartedAt.Add(20*time.Second), 0)
+	expect("health_status: healthy")
+	handleResult(c.State.StartedAt.Add(40*time.Second), 0)
+
+	if c.State.Health.FailingStreak != 0 {
+		t.Errorf("Expecting FailingStreak=0, but got %d\n", c.State.Health.FailingStreak)
+	}
+	if len(c.State.Health.Log) != 2 {
+		t.Errorf("Expecting 2 log entries, but got %d\n", len(c.State.Health.Log))
+	}
+
+	// Results arriving after the monitor was stopped are ignored
+	close(stop)
+	handleResult(c.State.StartedAt.Add(60*time.Second), 1)
+	if c.State.Health.Status != types.Healthy {
+		t.Errorf("Expecting status %s after stop, but got %s\n", types.Healthy, c.State.Health.Status)
+	}
+}
+
+func TestHealthLogIsBounded(t *testing.T) {
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "container_id",
+			Config: &runconfig.Config{Healthcheck: &runconfig.HealthConfig{}},
+		},
+	}
+	daemon := &Daemon{EventsService: events.New()}
+	reset(c)
+
+	stop := make(chan struct{})
+	for i := 0; i < maxLogEntries+3; i++ {
+		handleProbeResult(daemon, c, stop, &types.HealthcheckResult{ExitCode: i})
+	}
+	if len(c.State.Health.Log) != maxLogEntries {
+		t.Fatalf("Expecting %d log entries, but got %d", maxLogEntries, len(c.State.Health.Log))
+	}
+	if last := c.State.Health.Log[maxLogEntries-1]; last.ExitCode != maxLogEntries+2 {
+		t.Fatalf("Expecting most recent result last, but got exit code %d", last.ExitCode)
+	}
+}
@@ -88,7 +88,7 @@ func (daemon *Daemon) ImageDelete(imageRef string, force, prune bool) ([]types.I
 
 		untaggedRecord := types.ImageDelete{Untagged: parsedRef.String()}
 
-		daemon.EventsService.Log("untag", imgID.String(), "")
+		daemon.LogImageEvent(imgID.String(), parsedRef.String(), "untag")
 		records = append(records, untaggedRecord)
 
 		// If has remaining references then untag finishes the remove
@@ -110,7 +110,7 @@ func (daemon *Daemon) ImageDelete(imageRef string, force, prune bool) ([]types.I
 
 			untaggedRecord := types.ImageDelete{Untagged: parsedRef.String()}
 
-			daemon.EventsService.Log("untag", imgID.String(), "")
+			daemon.LogImageEvent(imgID.String(), parsedRef.String(), "untag")
 			records = append(records, untaggedRecord)
 		}
 	}
@@ -185,7 +185,7 @@ func (daemon *Daemon) removeAllReferencesToImageID(imgID image.ID, records *[]ty
 
 		untaggedRecord := types.ImageDelete{Untagged: parsedRef.String()}
 
-		daemon.EventsService.Log("untag", imgID.String(), "")
+		daemon.LogImageEvent(imgID.String(), parsedRef.String(), "untag")
 		*records = append(*records, untaggedRecord)
 	}
 
@@ -254,7 +254,7 @@ func (daemon *Daemon) imageDeleteHelper(imgID image.ID, records *[]types.ImageDe
 		return err
 	}
 
-	daemon.EventsService.Log("delete", imgID.String(), "")
+	daemon.LogImageEvent(imgID.String(), imgID.String(), "delete")
 	*records = append(*records, types.ImageDelete{Deleted: imgID.String()})
 	for _, removedLayer := range removedLayers {
 		*records = append(*records, types.ImageDelete{Deleted: removedLayer.ChainID.String()})
@@ -106,6 +106,6 @@ func (daemon *Daemon) ImportImage(src string, newRef reference.Named, msg string
 	}
 
 	outStream.Write(sf.FormatStatus("", id.String()))
-	daemon.EventsService.Log("import", id.String(), "")
+	daemon.LogImageEvent(id.String(), id.String(), "import")
 	return nil
 }
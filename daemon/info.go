@@ -54,6 +54,13 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 	initPath := utils.DockerInitPath("")
 	sysInfo := sysinfo.New(true)
 
+	daemon.configLock.RLock()
+	loggingDriver := daemon.defaultLogConfig.Type
+	labels := daemon.configStore.Labels
+	clusterStore := daemon.configStore.ClusterStore
+	clusterAdvertise := daemon.configStore.ClusterAdvertise
+	daemon.configLock.RUnlock()
+
 	v := &types.Info{
 		ID:                 daemon.ID,
 		Containers:         len(daemon.List()),
@@ -69,24 +76,24 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		NGoroutines:        runtime.NumGoroutine(),
 		SystemTime:         time.Now().Format(time.RFC3339Nano),
 		ExecutionDriver:    daemon.ExecutionDriver().Name(),
-		LoggingDriver:      daemon.defaultLogConfig.Type,
+		LoggingDriver:      loggingDriver,
 		NEventsListener:    daemon.EventsService.SubscribersCount(),
 		KernelVersion:      kernelVersion,
 		OperatingSystem:    operatingSystem,
 		IndexServerAddress: registry.IndexServer,
 		OSType:             platform.OSType,
 		Architecture:       platform.Architecture,
-		RegistryConfig:     daemon.RegistryService.Config,
+		RegistryConfig:     daemon.RegistryService.ServiceConfig(),
 		InitSha1:           dockerversion.InitSHA1,
 		InitPath:           initPath,
 		NCPU:               runtime.NumCPU(),
 		MemTotal:           meminfo.MemTotal,
 		DockerRootDir:      daemon.configStore.Root,
-		Labels:             daemon.configStore.Labels,
+		Labels:             labels,
 		ExperimentalBuild:  utils.ExperimentalBuild(),
 		ServerVersion:      dockerversion.Version,
-		ClusterStore:       daemon.configStore.ClusterStore,
-		ClusterAdvertise:   daemon.configStore.ClusterAdvertise,
+		ClusterStore:       clusterStore,
+		ClusterAdvertise:   clusterAdvertise,
 		HTTPProxy:          getProxyEnv("http_proxy"),
 		HTTPSProxy:         getProxyEnv("https_proxy"),
 		NoProxy:            getProxyEnv("no_proxy"),
@@ -104,6 +111,16 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		v.CPUCfsQuota = sysInfo.CPUCfsQuota
 		v.CPUShares = sysInfo.CPUShares
 		v.CPUSet = sysInfo.Cpuset
+
+		if sysInfo.AppArmor {
+			v.SecurityOptions = append(v.SecurityOptions, "apparmor")
+		}
+		if sysInfo.Seccomp && supportsSeccomp {
+			v.SecurityOptions = append(v.SecurityOptions, "seccomp")
+		}
+		if selinuxEnabled() {
+			v.SecurityOptions = append(v.SecurityOptions, "selinux")
+		}
 	}
 
 	if hostname, err := os.Hostname(); err == nil {
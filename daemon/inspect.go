@@ -107,14 +107,24 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 			hostConfig.Links = append(hostConfig.Links, fmt.Sprintf("%s:%s", child.Name, linkAlias))
 		}
 	}
-	// we need this trick to preserve empty log driver, so
-	// container will use daemon defaults even if daemon change them
+	// containers created before their log config was stored use the daemon
+	// defaults, even if the daemon changes them
+	defaultLogConfig := daemon.getDefaultLogConfig()
 	if hostConfig.LogConfig.Type == "" {
-		hostConfig.LogConfig.Type = daemon.defaultLogConfig.Type
+		hostConfig.LogConfig.Type = defaultLogConfig.Type
 	}
 
 	if len(hostConfig.LogConfig.Config) == 0 {
-		hostConfig.LogConfig.Config = daemon.defaultLogConfig.Config
+		hostConfig.LogConfig.Config = defaultLogConfig.Config
+	}
+
+	var containerHealth *types.Health
+	if container.State.Health != nil {
+		containerHealth = &types.Health{
+			Status:        container.State.Health.Status,
+			FailingStreak: container.State.Health.FailingStreak,
+			Log:           append([]*types.HealthcheckResult{}, container.State.Health.Log...),
+		}
 	}
 
 	containerState := &types.ContainerState{
@@ -129,6 +139,7 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 		Error:      container.State.Error,
 		StartedAt:  container.State.StartedAt.Format(time.RFC3339Nano),
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
+		Health:     containerHealth,
 	}
 
 	contJSONBase := &types.ContainerJSONBase{
@@ -139,6 +150,7 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 		State:        containerState,
 		Image:        container.ImageID.String(),
 		LogPath:      container.LogPath,
+		LogDropped:   container.LogDroppedMessages(),
 		Name:         container.Name,
 		RestartCount: container.RestartCount,
 		Driver:       container.Driver,
@@ -20,6 +20,8 @@ type Copier struct {
 	srcs     map[string]io.Reader
 	dst      Logger
 	copyJobs sync.WaitGroup
+
+	multiline *MultilineConfig
 }
 
 // NewCopier creates a new Copier
@@ -31,6 +33,13 @@ func NewCopier(cid string, srcs map[string]io.Reader, dst Logger) *Copier {
 	}
 }
 
+// SetMultiline makes the Copier join the lines into multi-line records as
+// configured by m, or copy each line as a message if m is nil. It must be
+// called before Run.
+func (c *Copier) SetMultiline(m *MultilineConfig) {
+	c.multiline = m
+}
+
 // Run starts logs copying
 func (c *Copier) Run() {
 	for src, w := range c.srcs {
@@ -41,6 +50,10 @@ func (c *Copier) Run() {
 
 func (c *Copier) copySrc(name string, src io.Reader) {
 	defer c.copyJobs.Done()
+	if c.multiline != nil {
+		c.copyMultiline(name, src)
+		return
+	}
 	reader := bufio.NewReader(src)
 
 	for {
@@ -50,9 +63,7 @@ func (c *Copier) copySrc(name string, src io.Reader) {
 		// ReadBytes can return full or partial output even when it failed.
 		// e.g. it can return a full entry and EOF.
 		if err == nil || len(line) > 0 {
-			if logErr := c.dst.Log(&Message{ContainerID: c.cid, Line: line, Source: name, Timestamp: time.Now().UTC()}); logErr != nil {
-				logrus.Errorf("Failed to log msg %q for logger %s: %s", line, c.dst.Name(), logErr)
-			}
+			c.log(name, line, time.Now().UTC())
 		}
 
 		if err != nil {
@@ -65,6 +76,12 @@ func (c *Copier) copySrc(name string, src io.Reader) {
 	}
 }
 
+func (c *Copier) log(name string, line []byte, timestamp time.Time) {
+	if logErr := c.dst.Log(&Message{ContainerID: c.cid, Line: line, Source: name, Timestamp: timestamp}); logErr != nil {
+		logrus.Errorf("Failed to log msg %q for logger %s: %s", line, c.dst.Name(), logErr)
+	}
+}
+
 // Wait waits until all copying is done
 func (c *Copier) Wait() {
 	c.copyJobs.Wait()
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -94,3 +95,104 @@ func TestCopier(t *testing.T) {
 		}
 	}
 }
+
+type testLoggerLines struct {
+	lines chan string
+}
+
+func (l *testLoggerLines) Log(m *Message) error {
+	l.lines <- string(m.Line)
+	return nil
+}
+
+func (l *testLoggerLines) Close() error { return nil }
+
+func (l *testLoggerLines) Name() string { return "lines" }
+
+func TestCopierMultiline(t *testing.T) {
+	cases := []struct {
+		cfg      map[string]string
+		input    string
+		expected []string
+	}{
+		{
+			cfg:   map[string]string{MultilineStartOpt: `^\S`},
+			input: "Exception in thread \"main\"\n\tat Main.a(Main.java:3)\n\tat Main.main(Main.java:7)\nnext\n",
+			expected: []string{
+				"Exception in thread \"main\"\n\tat Main.a(Main.java:3)\n\tat Main.main(Main.java:7)",
+				"next",
+			},
+		},
+		{
+			cfg:      map[string]string{MultilineContinueOpt: `^\s`},
+			input:    "Traceback (most recent call last):\n  File \"a.py\", line 1\nValueError\n",
+			expected: []string{"Traceback (most recent call last):\n  File \"a.py\", line 1", "ValueError"},
+		},
+		{
+			cfg:      map[string]string{MultilineContinueOpt: `^\s`, MultilineMaxSizeOpt: "8"},
+			input:    "abc\n def\n ghi\n",
+			expected: []string{"abc\n def", " ghi"},
+		},
+	}
+
+	for _, c := range cases {
+		multiline, err := ParseMultilineConfig(c.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := &testLoggerLines{lines: make(chan string, 10)}
+		copier := NewCopier("cid", map[string]io.Reader{"stdout": bytes.NewBufferString(c.input)}, l)
+		copier.SetMultiline(multiline)
+		copier.Run()
+		copier.Wait()
+		close(l.lines)
+
+		var got []string
+		for line := range l.lines {
+			got = append(got, line)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Fatalf("with %v, expected %q, got %q", c.cfg, c.expected, got)
+		}
+	}
+}
+
+func TestCopierMultilineFlushTimeout(t *testing.T) {
+	multiline, err := ParseMultilineConfig(map[string]string{MultilineStartOpt: `^\S`, MultilineFlushTimeoutOpt: "10ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, w := io.Pipe()
+	defer w.Close()
+	l := &testLoggerLines{lines: make(chan string, 10)}
+	copier := NewCopier("cid", map[string]io.Reader{"stdout": r}, l)
+	copier.SetMultiline(multiline)
+	copier.Run()
+
+	// The record is sent without waiting for the next one to start.
+	w.Write([]byte("panic: oops\n\tgoroutine 1\n"))
+	select {
+	case line := <-l.lines:
+		if line != "panic: oops\n\tgoroutine 1" {
+			t.Fatalf("unexpected record %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the record to be flushed")
+	}
+}
+
+func TestParseMultilineConfig(t *testing.T) {
+	if m, err := ParseMultilineConfig(map[string]string{"max-size": "1m"}); err != nil || m != nil {
+		t.Fatalf("expected no multi-line configuration, got %v, %v", m, err)
+	}
+	for _, cfg := range []map[string]string{
+		{MultilineStartOpt: "("},
+		{MultilineStartOpt: "^a", MultilineFlushTimeoutOpt: "soon"},
+		{MultilineStartOpt: "^a", MultilineMaxSizeOpt: "0"},
+		{MultilineMaxSizeOpt: "1k"},
+	} {
+		if err := ValidateLogOpts("test", cfg); err == nil {
+			t.Fatalf("expected %v to be invalid", cfg)
+		}
+	}
+}
@@ -14,8 +14,12 @@ type LogOptValidator func(cfg map[string]string) error
 
 type logdriverFactory struct {
 	registry     map[string]Creator
+	readers      map[string]Creator
 	optValidator map[string]LogOptValidator
 	m            sync.Mutex
+
+	commonOpts       map[string]bool
+	commonValidators []LogOptValidator
 }
 
 func (lf *logdriverFactory) register(name string, c Creator) error {
@@ -40,13 +44,38 @@ func (lf *logdriverFactory) registerLogOptValidator(name string, l LogOptValidat
 	return nil
 }
 
-func (lf *logdriverFactory) get(name string) (Creator, error) {
+// registerPlugin registers the Creator of a logging driver plugin and the
+// Creator of its readers, unless a driver with the same name is already
+// registered.
+func (lf *logdriverFactory) registerPlugin(name string, c, r Creator) {
 	lf.m.Lock()
 	defer lf.m.Unlock()
 
+	if _, ok := lf.registry[name]; !ok {
+		lf.registry[name] = c
+		lf.readers[name] = r
+	}
+}
+
+func (lf *logdriverFactory) get(name string) (Creator, error) {
+	lf.m.Lock()
 	c, ok := lf.registry[name]
+	lf.m.Unlock()
 	if !ok {
-		return c, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+		return lookupPlugin(name)
+	}
+	return c, nil
+}
+
+func (lf *logdriverFactory) getReader(name string) (Creator, error) {
+	lf.m.Lock()
+	c, ok := lf.readers[name]
+	if !ok {
+		c, ok = lf.registry[name]
+	}
+	lf.m.Unlock()
+	if !ok {
+		return lookupPluginReader(name)
 	}
 	return c, nil
 }
@@ -59,7 +88,37 @@ func (lf *logdriverFactory) getLogOptValidator(name string) LogOptValidator {
 	return c
 }
 
-var factory = &logdriverFactory{registry: make(map[string]Creator), optValidator: make(map[string]LogOptValidator)} // global factory instance
+func (lf *logdriverFactory) registerCommonLogOpts(keys []string, l LogOptValidator) {
+	lf.m.Lock()
+	defer lf.m.Unlock()
+
+	for _, key := range keys {
+		lf.commonOpts[key] = true
+	}
+	lf.commonValidators = append(lf.commonValidators, l)
+}
+
+// filterCommonLogOpts validates the options that all the drivers accept, and
+// returns the other options of cfg.
+func (lf *logdriverFactory) filterCommonLogOpts(cfg map[string]string) (map[string]string, error) {
+	lf.m.Lock()
+	defer lf.m.Unlock()
+
+	for _, l := range lf.commonValidators {
+		if err := l(cfg); err != nil {
+			return nil, err
+		}
+	}
+	filtered := make(map[string]string)
+	for key, value := range cfg {
+		if !lf.commonOpts[key] {
+			filtered[key] = value
+		}
+	}
+	return filtered, nil
+}
+
+var factory = &logdriverFactory{registry: make(map[string]Creator), readers: make(map[string]Creator), optValidator: make(map[string]LogOptValidator), commonOpts: make(map[string]bool)} // global factory instance
 
 // RegisterLogDriver registers the given logging driver builder with given logging
 // driver name.
@@ -73,14 +132,33 @@ func RegisterLogOptValidator(name string, l LogOptValidator) error {
 	return factory.registerLogOptValidator(name, l)
 }
 
+// RegisterCommonLogOpts registers logging options that all the logging
+// drivers accept, as they are handled by the daemon, along with their
+// validator. The validator is given all the options of the driver.
+func RegisterCommonLogOpts(keys []string, l LogOptValidator) {
+	factory.registerCommonLogOpts(keys, l)
+}
+
 // GetLogDriver provides the logging driver builder for a logging driver name.
 func GetLogDriver(name string) (Creator, error) {
 	return factory.get(name)
 }
 
+// GetLogReader provides the builder of the loggers reading the logs of a
+// stopped container for a logging driver name. Unlike the loggers built by
+// the builder of GetLogDriver, they don't ask logging driver plugins to start
+// logging.
+func GetLogReader(name string) (Creator, error) {
+	return factory.getReader(name)
+}
+
 // ValidateLogOpts checks the options for the given log driver. The
 // options supported are specific to the LogDriver implementation.
 func ValidateLogOpts(name string, cfg map[string]string) error {
+	cfg, err := factory.filterCommonLogOpts(cfg)
+	if err != nil {
+		return err
+	}
 	l := factory.getLogOptValidator(name)
 	if l != nil {
 		return l(cfg)
@@ -0,0 +1,418 @@
+// Package local provides a Logger writing the logs to files on the host in a
+// compact binary format, with a sparse index of the timestamps so that the
+// logs can be read from a point in time or from the end without decoding the
+// whole files.
+package local
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/units"
+)
+
+// Name is the name of the local logging driver.
+const Name = "local"
+
+const (
+	defaultMaxSize  = 20 * 1024 * 1024
+	defaultMaxFiles = 5
+)
+
+var errClosed = errors.New("local: logger is closed")
+
+// localLogger is a Logger writing the messages as records to a file, rotated
+// in compressed segments.
+type localLogger struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu          sync.Mutex
+	f           *os.File
+	idx         *os.File
+	size        int64                           // size of the records in f
+	maxTime     int64                           // greatest timestamp of the records in f
+	lastIndexed int64                           // offset of the last entry of idx
+	gen         int                             // number of rotations
+	notify      chan struct{}                   // closed when records are written or f is rotated
+	closed      bool                            // whether the logger is closed
+	err         error                           // error leaving the files of the logger unusable
+	readers     map[*logger.LogWatcher]struct{} // stores the active log followers
+
+	compressed  chan struct{} // closed when the rotated file is compressed, nil if none is
+	compressErr error         // error compressing the rotated file, set before compressed is closed
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// New creates a localLogger writing to the log path of the context.
+func New(ctx logger.Context) (logger.Logger, error) {
+	maxSize, maxFiles, err := parseOpts(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	l := &localLogger{
+		path:     ctx.LogPath,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		notify:   make(chan struct{}),
+		readers:  make(map[*logger.LogWatcher]struct{}),
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	// The daemon may have stopped before the last rotated file was
+	// compressed.
+	if _, err := os.Stat(rotatedPath(l.path)); err == nil {
+		if l.maxFiles > 1 {
+			l.compress()
+		} else {
+			os.Remove(rotatedPath(l.path))
+		}
+	}
+	return l, nil
+}
+
+func parseOpts(cfg map[string]string) (int64, int, error) {
+	maxSize := int64(defaultMaxSize)
+	if v, ok := cfg["max-size"]; ok {
+		var err error
+		maxSize, err = units.FromHumanSize(v)
+		if err != nil || maxSize <= 0 {
+			return 0, 0, fmt.Errorf("invalid value for log opt 'max-size': %s", v)
+		}
+	}
+	maxFiles := defaultMaxFiles
+	if v, ok := cfg["max-file"]; ok {
+		var err error
+		maxFiles, err = strconv.Atoi(v)
+		if err != nil || maxFiles < 1 {
+			return 0, 0, fmt.Errorf("invalid value for log opt 'max-file': %s", v)
+		}
+	}
+	return maxSize, maxFiles, nil
+}
+
+// ValidateLogOpt looks for the local specific log options max-size and
+// max-file.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "max-size":
+		case "max-file":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for local log driver", key)
+		}
+	}
+	_, _, err := parseOpts(cfg)
+	return err
+}
+
+func segmentPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d.gz", path, i)
+}
+
+// rotatedPath is the path of the last rotated file until it is compressed into
+// the first segment.
+func rotatedPath(path string) string {
+	return path + ".1"
+}
+
+func indexPath(path string, i int) string {
+	if i == 0 {
+		return path + ".idx"
+	}
+	return fmt.Sprintf("%s.%d.idx", path, i)
+}
+
+// open opens the log file and its index, and recovers the state of the
+// logger from the records following the last valid entry of the index. The
+// entries after it and a record left incomplete, by a crash of the daemon,
+// are truncated.
+func (l *localLogger) open() error {
+	index, err := readIndex(indexPath(l.path, 0))
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	idx, err := os.OpenFile(indexPath(l.path, 0), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	var offset, maxTime int64
+	fi, err := f.Stat()
+	if err == nil {
+		valid := validIndex(f, fi.Size(), index)
+		if len(valid) < len(index) {
+			logrus.Debugf("local: dropping %d invalid entries of the index of %s", len(index)-len(valid), l.path)
+		}
+		if n := len(valid); n > 0 {
+			offset, maxTime = valid[n-1].offset, valid[n-1].maxTime
+		}
+		// This also drops an entry left incomplete.
+		err = idx.Truncate(int64(len(valid)) * indexEntryLen)
+	}
+	l.lastIndexed = offset
+	if err == nil {
+		_, err = f.Seek(offset, os.SEEK_SET)
+	}
+	r := bufio.NewReader(f)
+	for err == nil {
+		var msg *logger.Message
+		var n int64
+		if msg, n, err = readRecord(r); err == nil {
+			offset += n
+			if ts := msg.Timestamp.UnixNano(); ts > maxTime {
+				maxTime = ts
+			}
+		}
+	}
+	if err != io.EOF {
+		logrus.Debugf("local: truncating %s at %d: %v", l.path, offset, err)
+	}
+	if err = f.Truncate(offset); err == nil {
+		_, err = f.Seek(offset, os.SEEK_SET)
+	}
+	if err != nil {
+		f.Close()
+		idx.Close()
+		return err
+	}
+
+	l.f, l.idx = f, idx
+	l.size, l.maxTime = offset, maxTime
+	return nil
+}
+
+// Log writes the message as a record to the log file, rotating it first if
+// the record would exceed the maximum size.
+func (l *localLogger) Log(msg *logger.Message) error {
+	b := encodeRecord(msg)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errClosed
+	}
+	if l.err != nil {
+		return l.err
+	}
+
+	if l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	if l.size-l.lastIndexed >= indexInterval {
+		if _, err := l.idx.Write(encodeIndexEntry(indexEntry{l.size, l.maxTime})); err != nil {
+			return err
+		}
+		l.lastIndexed = l.size
+	}
+
+	if _, err := l.f.Write(b); err != nil {
+		// Don't leave a partial record behind.
+		l.f.Truncate(l.size)
+		l.f.Seek(l.size, os.SEEK_SET)
+		return err
+	}
+	l.size += int64(len(b))
+	if ts := msg.Timestamp.UnixNano(); ts > l.maxTime {
+		l.maxTime = ts
+	}
+	l.broadcast()
+	return nil
+}
+
+// broadcast wakes up the followers. l.mu must be held.
+func (l *localLogger) broadcast() {
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// rotate moves the log file aside, shifting the previous segments and
+// dropping the oldest one, and starts a new log file. The rotated file is
+// compressed into the first segment in the background. If compressing the
+// previous one failed, it was kept as is until now, and it is compressed
+// again before being shifted, or dropped if that fails again. l.mu must be
+// held.
+func (l *localLogger) rotate() error {
+	if l.compressed != nil {
+		// Only waits if the logs are written faster than compressed.
+		<-l.compressed
+		l.compressed = nil
+	}
+	if l.compressErr != nil {
+		l.compressErr = nil
+		src := rotatedPath(l.path)
+		if err := compressFile(src, segmentPath(l.path, 1)); err != nil {
+			logrus.Errorf("local: error compressing %s again, dropping it: %v", src, err)
+			os.Remove(indexPath(l.path, 1))
+		}
+		os.Remove(src)
+	}
+
+	// The new files are created aside, so that the logger keeps writing to
+	// the current ones if that fails.
+	f, err := os.OpenFile(l.path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	idx, err := os.OpenFile(indexPath(l.path, 0)+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0640)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	// The last entry of the index of a segment covers all its records, so
+	// that readers can skip the segment altogether.
+	if _, err := l.idx.Write(encodeIndexEntry(indexEntry{l.size, l.maxTime})); err != nil {
+		logrus.Errorf("local: error writing the index of %s: %v", l.path, err)
+	}
+	l.f.Close()
+	l.idx.Close()
+	l.f, l.idx = f, idx
+
+	if l.maxFiles > 1 {
+		for i := l.maxFiles - 1; i > 1; i-- {
+			if err := os.Rename(segmentPath(l.path, i-1), segmentPath(l.path, i)); err != nil && !os.IsNotExist(err) {
+				logrus.Errorf("local: error rotating %s: %v", segmentPath(l.path, i-1), err)
+			}
+			os.Rename(indexPath(l.path, i-1), indexPath(l.path, i))
+		}
+		// The first segment was shifted, or is the oldest one.
+		os.Remove(segmentPath(l.path, 1))
+		err = os.Rename(l.path, rotatedPath(l.path))
+		if err == nil {
+			err = os.Rename(indexPath(l.path, 0), indexPath(l.path, 1))
+		}
+	}
+	// Followers may still be reading the old file, so it's replaced rather
+	// than truncated.
+	if err == nil {
+		err = os.Rename(f.Name(), l.path)
+	}
+	if err == nil {
+		err = os.Rename(idx.Name(), indexPath(l.path, 0))
+	}
+	if err != nil {
+		// The files don't match the state of the logger anymore.
+		l.err = fmt.Errorf("local: error rotating %s: %v", l.path, err)
+		return l.err
+	}
+
+	l.size, l.maxTime, l.lastIndexed = 0, 0, 0
+	l.gen++
+	l.broadcast()
+	if l.maxFiles > 1 {
+		l.compress()
+	}
+	return nil
+}
+
+// compress compresses the rotated file into the first segment in the
+// background. The rotated file is removed once compressed, until then the
+// readers read it instead. l.mu must be held, or l not shared yet.
+func (l *localLogger) compress() {
+	done := make(chan struct{})
+	l.compressed = done
+	go func() {
+		defer close(done)
+		src := rotatedPath(l.path)
+		if err := compressFile(src, segmentPath(l.path, 1)); err != nil {
+			logrus.Errorf("local: error compressing %s: %v", src, err)
+			l.compressErr = err
+			return
+		}
+		if err := os.Remove(src); err != nil {
+			logrus.Errorf("local: error removing %s: %v", src, err)
+		}
+	}()
+}
+
+// compressFile writes src compressed with gzip to dst, replacing it
+// atomically.
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// LogPath returns the location the logger logs to.
+func (l *localLogger) LogPath() string {
+	return l.path
+}
+
+// Close closes the log file and signals all readers to stop.
+func (l *localLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	for r := range l.readers {
+		r.Close()
+		delete(l.readers, r)
+	}
+	l.broadcast()
+	if l.compressed != nil {
+		<-l.compressed
+	}
+
+	err := l.f.Close()
+	if ierr := l.idx.Close(); err == nil {
+		err = ierr
+	}
+	return err
+}
+
+// Name returns the name of the logger.
+func (l *localLogger) Name() string {
+	return Name
+}
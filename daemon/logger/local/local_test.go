@@ -0,0 +1,349 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+var epoch = time.Unix(1450000000, 0).UTC()
+
+func newTestLogger(t *testing.T, cfg map[string]string) (logger.Logger, string) {
+	tmp, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Context{LogPath: filename, Config: cfg})
+	if err != nil {
+		os.RemoveAll(tmp)
+		t.Fatal(err)
+	}
+	return l, tmp
+}
+
+func logLines(t *testing.T, l logger.Logger, from, to int) {
+	for i := from; i < to; i++ {
+		msg := &logger.Message{
+			Line:      []byte(fmt.Sprintf("line %d", i)),
+			Source:    "stdout",
+			Timestamp: epoch.Add(time.Duration(i) * time.Second),
+		}
+		if err := l.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// waitCompressed waits for the last rotated file to be compressed.
+func waitCompressed(l logger.Logger) {
+	ll := l.(*localLogger)
+	ll.mu.Lock()
+	compressed := ll.compressed
+	ll.mu.Unlock()
+	if compressed != nil {
+		<-compressed
+	}
+}
+
+func readLines(t *testing.T, l logger.Logger, config logger.ReadConfig) []string {
+	watcher := l.(logger.LogReader).ReadLogs(config)
+	var lines []string
+	for {
+		select {
+		case msg, ok := <-watcher.Msg:
+			if !ok {
+				return lines
+			}
+			lines = append(lines, string(msg.Line))
+		case err := <-watcher.Err:
+			t.Fatal(err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout reading the logs")
+		}
+	}
+}
+
+func checkLines(t *testing.T, lines []string, from, to int) {
+	if len(lines) != to-from {
+		t.Fatalf("expected %d lines, got %d: %q", to-from, len(lines), lines)
+	}
+	for i, line := range lines {
+		if expected := fmt.Sprintf("line %d\n", from+i); line != expected {
+			t.Fatalf("expected %q, got %q", expected, line)
+		}
+	}
+}
+
+func TestReadLogs(t *testing.T) {
+	l, tmp := newTestLogger(t, nil)
+	defer os.RemoveAll(tmp)
+	defer l.Close()
+
+	// Enough records for the index to have a few entries.
+	logLines(t, l, 0, 10000)
+
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1}), 0, 10000)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: 10}), 9990, 10000)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1, Since: epoch.Add(9000 * time.Second)}), 9000, 10000)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: 10, Since: epoch.Add(9995 * time.Second)}), 9995, 10000)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: 0}), 0, 0)
+}
+
+func TestReadLogsRotated(t *testing.T) {
+	l, tmp := newTestLogger(t, map[string]string{"max-size": "1k", "max-file": "3"})
+	defer os.RemoveAll(tmp)
+	defer l.Close()
+
+	logLines(t, l, 0, 100)
+	waitCompressed(l)
+
+	filename := filepath.Join(tmp, "container.log")
+	for _, name := range []string{filename, filename + ".1.gz", filename + ".2.gz"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filename + ".3.gz"); !os.IsNotExist(err) {
+		t.Fatalf("expected only 2 rotated segments, got %v", err)
+	}
+
+	all := readLines(t, l, logger.ReadConfig{Tail: -1})
+	var first int
+	if len(all) == 0 || len(all) >= 100 {
+		t.Fatalf("expected the oldest lines to be dropped, got %d lines", len(all))
+	}
+	fmt.Sscanf(all[0], "line %d", &first)
+	checkLines(t, all, first, 100)
+
+	// The tail and since span the segments.
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: len(all) - 1}), first+1, 100)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1, Since: epoch.Add(time.Duration(first+1) * time.Second)}), first+1, 100)
+}
+
+func TestFollowRotation(t *testing.T) {
+	l, tmp := newTestLogger(t, map[string]string{"max-size": "1k", "max-file": "2"})
+	defer os.RemoveAll(tmp)
+
+	logLines(t, l, 0, 5)
+	watcher := l.(logger.LogReader).ReadLogs(logger.ReadConfig{Tail: 2, Follow: true})
+
+	started := make(chan struct{})
+	done := make(chan []string)
+	go func() {
+		var lines []string
+		for msg := range watcher.Msg {
+			lines = append(lines, string(msg.Line))
+			if len(lines) == 2 {
+				close(started)
+			}
+		}
+		done <- lines
+	}()
+	// The tail is read asynchronously.
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout reading the tail")
+	}
+
+	// Followers keep up with each record, so that they see every rotation.
+	for i := 5; i < 60; i++ {
+		logLines(t, l, i, i+1)
+		time.Sleep(time.Millisecond)
+	}
+	l.Close()
+
+	select {
+	case lines := <-done:
+		checkLines(t, lines, 3, 60)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout following the logs")
+	}
+}
+
+func TestRecoverPartialRecord(t *testing.T) {
+	l, tmp := newTestLogger(t, nil)
+	defer os.RemoveAll(tmp)
+
+	logLines(t, l, 0, 3)
+	l.Close()
+
+	filename := filepath.Join(tmp, "container.log")
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(encodeRecord(&logger.Message{Line: []byte("partial"), Timestamp: epoch})[:10])
+	f.Close()
+
+	l, err = New(logger.Context{LogPath: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	logLines(t, l, 3, 5)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1}), 0, 5)
+}
+
+// logUntilRotated logs lines from n until the logger rotates its file, and
+// returns the number of the line following the last one logged.
+func logUntilRotated(t *testing.T, l logger.Logger, n int) int {
+	ll := l.(*localLogger)
+	ll.mu.Lock()
+	gen := ll.gen
+	ll.mu.Unlock()
+	for ; ; n++ {
+		logLines(t, l, n, n+1)
+		waitCompressed(l)
+		ll.mu.Lock()
+		rotated := ll.gen != gen
+		ll.mu.Unlock()
+		if rotated {
+			return n + 1
+		}
+	}
+}
+
+func TestReadLogsCompressionFailure(t *testing.T) {
+	l, tmp := newTestLogger(t, map[string]string{"max-size": "1k", "max-file": "4"})
+	defer os.RemoveAll(tmp)
+	defer l.Close()
+
+	// The compressed segment can't be created.
+	filename := filepath.Join(tmp, "container.log")
+	if err := os.Mkdir(filename+".1.gz.tmp", 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rotated file is kept and read, and logging carries on.
+	first := logUntilRotated(t, l, 0)
+	if _, err := os.Stat(filename + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	n := first + 10
+	logLines(t, l, first, n)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1}), 0, n)
+
+	// Compressing it fails again at the next rotation, it is dropped.
+	second := logUntilRotated(t, l, n)
+	if _, err := os.Stat(filename + ".1.idx"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filename + ".2.idx"); !os.IsNotExist(err) {
+		t.Fatalf("expected the index of the dropped file to be removed, got %v", err)
+	}
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1}), first-1, second)
+
+	// Compressing it succeeds at the next rotation.
+	if err := os.Remove(filename + ".1.gz.tmp"); err != nil {
+		t.Fatal(err)
+	}
+	third := logUntilRotated(t, l, second)
+	for _, name := range []string{".1", ".1.gz.tmp"} {
+		if _, err := os.Stat(filename + name); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", filename+name, err)
+		}
+	}
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1}), first-1, third)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: 5}), third-5, third)
+}
+
+func TestRecoverTornIndex(t *testing.T) {
+	l, tmp := newTestLogger(t, nil)
+	defer os.RemoveAll(tmp)
+
+	logLines(t, l, 0, 10000)
+	l.Close()
+
+	filename := filepath.Join(tmp, "container.log")
+	index, err := readIndex(indexPath(filename, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index) < 2 {
+		t.Fatalf("expected a few index entries, got %d", len(index))
+	}
+
+	last := index[len(index)-1]
+	for _, garbage := range [][]indexEntry{
+		{{last.offset + 3, last.maxTime}},
+		{{-1, last.maxTime}},
+		{{1 << 40, last.maxTime}},
+		{{last.offset, last.maxTime - 1}},
+	} {
+		f, err := os.OpenFile(indexPath(filename, 0), os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range garbage {
+			f.Write(encodeIndexEntry(e))
+		}
+		// A torn entry.
+		f.Write([]byte{0, 0, 1})
+		f.Close()
+
+		l, err = New(logger.Context{LogPath: filename})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(indexPath(filename, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() != int64(len(index))*indexEntryLen {
+			t.Fatalf("expected the index to be truncated to %d entries, got %d bytes", len(index), fi.Size())
+		}
+		checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1, Since: epoch.Add(9000 * time.Second)}), 9000, 10000)
+		l.Close()
+	}
+
+	// An entry that isn't at the end of a record is dropped, and the records
+	// after the previous entry are recovered.
+	if err := os.Truncate(indexPath(filename, 0), 0); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(indexPath(filename, 0), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(encodeIndexEntry(index[0]))
+	f.Write(encodeIndexEntry(indexEntry{index[1].offset + 1, index[1].maxTime}))
+	f.Close()
+
+	l, err = New(logger.Context{LogPath: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if ll := l.(*localLogger); ll.lastIndexed != index[0].offset {
+		t.Fatalf("expected the last valid entry at %d, got %d", index[0].offset, ll.lastIndexed)
+	}
+	logLines(t, l, 10000, 10010)
+	checkLines(t, readLines(t, l, logger.ReadConfig{Tail: -1}), 0, 10010)
+}
+
+func TestSinceOffset(t *testing.T) {
+	index := []indexEntry{{100, 10}, {200, 20}, {300, 20}, {400, 40}}
+	for since, expected := range map[int64]int64{0: 0, 10: 0, 11: 100, 20: 100, 21: 300, 40: 300, 41: 400} {
+		if offset := sinceOffset(index, since); offset != expected {
+			t.Fatalf("expected offset %d since %d, got %d", expected, since, offset)
+		}
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	if err := ValidateLogOpt(map[string]string{"max-size": "10m", "max-file": "3"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, cfg := range []map[string]string{{"max-file": "0"}, {"max-size": "big"}, {"labels": "a"}} {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("expected an error for %v", cfg)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package local
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+// ReadLogs implements the logger's LogReader interface for the logs
+// created by this driver.
+func (l *localLogger) ReadLogs(config logger.ReadConfig) *logger.LogWatcher {
+	logWatcher := logger.NewLogWatcher()
+
+	go func() {
+		defer close(logWatcher.Msg)
+		if err := l.readLogs(logWatcher, config); err != nil {
+			logWatcher.Err <- err
+		}
+	}()
+	return logWatcher
+}
+
+// segment is a rotated log file, with the index of its records.
+type segment struct {
+	f          *os.File
+	compressed bool
+	index      []indexEntry
+}
+
+// openSegment opens the segment i of the logs at path. The first segment is
+// read from the rotated file as long as it isn't compressed.
+func openSegment(path string, i int) (*os.File, bool, error) {
+	if i == 1 {
+		f, err := os.Open(rotatedPath(path))
+		if err == nil || !os.IsNotExist(err) {
+			return f, false, err
+		}
+	}
+	f, err := os.Open(segmentPath(path, i))
+	return f, true, err
+}
+
+func (l *localLogger) readLogs(logWatcher *logger.LogWatcher, config logger.ReadConfig) error {
+	var since int64
+	if !config.Since.IsZero() {
+		since = config.Since.UnixNano()
+	}
+	send := func(msg *logger.Message) bool {
+		if msg.Timestamp.UnixNano() < since {
+			return true
+		}
+		msg.Line = append(msg.Line, '\n')
+		select {
+		case logWatcher.Msg <- msg:
+			return true
+		case <-logWatcher.WatchClose():
+			return false
+		}
+	}
+
+	// The files are opened while holding the lock, so that none is rotated
+	// in the meantime, and the size of the records of the latest file is
+	// taken at the same time.
+	l.mu.Lock()
+	var segments []segment
+	for i := l.maxFiles - 1; i > 0; i-- {
+		f, compressed, err := openSegment(l.path, i)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			l.mu.Unlock()
+			return err
+		}
+		defer f.Close()
+		index, err := readIndex(indexPath(l.path, i))
+		if err != nil {
+			index = nil
+		}
+		segments = append(segments, segment{f, compressed, index})
+	}
+	latest, err := os.Open(l.path)
+	if err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	index, err := readIndex(indexPath(l.path, 0))
+	if err != nil {
+		index = nil
+	}
+	size, gen := l.size, l.gen
+	if config.Follow {
+		l.readers[logWatcher] = struct{}{}
+	}
+	l.mu.Unlock()
+
+	defer func() {
+		latest.Close()
+		if config.Follow {
+			l.mu.Lock()
+			delete(l.readers, logWatcher)
+			l.mu.Unlock()
+		}
+	}()
+
+	switch {
+	case config.Tail > 0:
+		if ok, err := readTail(segments, latest, size, config.Tail, send); !ok || err != nil {
+			return err
+		}
+	case config.Tail < 0:
+		for _, s := range segments {
+			// The last entry of the index of a segment covers all its
+			// records.
+			if n := len(s.index); n > 0 && s.index[n-1].maxTime < since {
+				continue
+			}
+			if ok, err := readSegment(s, send); !ok || err != nil {
+				return err
+			}
+		}
+		if ok, err := readRange(latest, sinceOffset(index, since), size, send); !ok || err != nil {
+			return err
+		}
+	}
+
+	if !config.Follow {
+		return nil
+	}
+	return l.follow(logWatcher, latest, size, gen, send)
+}
+
+// readTail sends the last n records, from the latest file first, then from
+// as many segments as needed. It returns false if the reader went away.
+func readTail(segments []segment, latest *os.File, size int64, n int, send func(*logger.Message) bool) (bool, error) {
+	offset, count, err := tailOffset(latest, size, n)
+	if err != nil {
+		return false, err
+	}
+
+	// The segments are read from the start, as they are compressed, only
+	// keeping the last records.
+	var older [][]*logger.Message
+	for i := len(segments) - 1; i >= 0 && count < n; i-- {
+		var msgs []*logger.Message
+		if _, err := readSegment(segments[i], func(msg *logger.Message) bool {
+			msgs = append(msgs, msg)
+			if len(msgs) > n-count {
+				msgs = msgs[1:]
+			}
+			return true
+		}); err != nil {
+			return false, err
+		}
+		older = append(older, msgs)
+		count += len(msgs)
+	}
+	for i := len(older) - 1; i >= 0; i-- {
+		for _, msg := range older[i] {
+			if !send(msg) {
+				return false, nil
+			}
+		}
+	}
+	return readRange(latest, offset, size, send)
+}
+
+// readSegment sends the records of a segment.
+func readSegment(s segment, send func(*logger.Message) bool) (bool, error) {
+	if !s.compressed {
+		return readRecords(bufio.NewReader(s.f), send)
+	}
+	gz, err := gzip.NewReader(s.f)
+	if err != nil {
+		return false, err
+	}
+	defer gz.Close()
+	return readRecords(bufio.NewReader(gz), send)
+}
+
+// readRange sends the records of f from offset from to offset to.
+func readRange(f *os.File, from, to int64, send func(*logger.Message) bool) (bool, error) {
+	return readRecords(bufio.NewReader(io.NewSectionReader(f, from, to-from)), send)
+}
+
+func readRecords(r io.Reader, send func(*logger.Message) bool) (bool, error) {
+	for {
+		msg, _, err := readRecord(r)
+		if err != nil {
+			if err == io.EOF {
+				return true, nil
+			}
+			return false, err
+		}
+		if !send(msg) {
+			return false, nil
+		}
+	}
+}
+
+// follow sends the records written to the latest file from offset, until the
+// reader or the logger is closed. When the file is rotated, the rest of it is
+// read before following the new one. A follower falling behind by more than
+// a whole file misses the files rotated in between.
+func (l *localLogger) follow(logWatcher *logger.LogWatcher, f *os.File, offset int64, gen int, send func(*logger.Message) bool) error {
+	defer func() { f.Close() }()
+	for {
+		l.mu.Lock()
+		size, curGen, notify, closed := l.size, l.gen, l.notify, l.closed
+		l.mu.Unlock()
+
+		if curGen != gen {
+			// The rotated file isn't written anymore, read it to the end.
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			if ok, err := readRange(f, offset, fi.Size(), send); !ok || err != nil {
+				return err
+			}
+			f.Close()
+
+			l.mu.Lock()
+			f, err = os.Open(l.path)
+			gen = l.gen
+			l.mu.Unlock()
+			if err != nil {
+				return err
+			}
+			offset = 0
+			continue
+		}
+
+		if offset < size {
+			if ok, err := readRange(f, offset, size, send); !ok || err != nil {
+				return err
+			}
+			offset = size
+			continue
+		}
+		if closed {
+			return nil
+		}
+		select {
+		case <-notify:
+		case <-logWatcher.WatchClose():
+			return nil
+		}
+	}
+}
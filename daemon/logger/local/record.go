@@ -0,0 +1,204 @@
+package local
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+// A log file is a sequence of records. Each record is made of the size of its
+// payload as a big-endian uint32, the payload, and the size again, so that the
+// file can be read backwards. The payload is the timestamp of the message in
+// nanoseconds since the epoch as a big-endian int64, the size of the source
+// as a byte, the source and the line of the message.
+const (
+	sizeFieldLen   = 4
+	payloadHeader  = 9
+	maxPayloadSize = 1 << 30
+)
+
+var errCorrupted = errors.New("local: corrupted log file")
+
+// encodeRecord returns the record of msg.
+func encodeRecord(msg *logger.Message) []byte {
+	source := msg.Source
+	if len(source) > 255 {
+		source = source[:255]
+	}
+	size := payloadHeader + len(source) + len(msg.Line)
+	b := make([]byte, 2*sizeFieldLen+size)
+	binary.BigEndian.PutUint32(b, uint32(size))
+	p := b[sizeFieldLen:]
+	binary.BigEndian.PutUint64(p, uint64(msg.Timestamp.UnixNano()))
+	p[8] = byte(len(source))
+	copy(p[payloadHeader:], source)
+	copy(p[payloadHeader+len(source):], msg.Line)
+	binary.BigEndian.PutUint32(b[sizeFieldLen+size:], uint32(size))
+	return b
+}
+
+// readRecord reads the record at the current position of r, and returns its
+// message along with its size. It returns io.EOF if r is at the end, and
+// io.ErrUnexpectedEOF if the record is incomplete.
+func readRecord(r io.Reader) (*logger.Message, int64, error) {
+	var sizeField [sizeFieldLen]byte
+	if _, err := io.ReadFull(r, sizeField[:]); err != nil {
+		return nil, 0, err
+	}
+	size := binary.BigEndian.Uint32(sizeField[:])
+	if size < payloadHeader || size > maxPayloadSize {
+		return nil, 0, errCorrupted
+	}
+
+	b := make([]byte, int(size)+sizeFieldLen)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	if binary.BigEndian.Uint32(b[size:]) != size {
+		return nil, 0, errCorrupted
+	}
+	sourceLen := int(b[8])
+	if payloadHeader+sourceLen > int(size) {
+		return nil, 0, errCorrupted
+	}
+	msg := &logger.Message{
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(b))).UTC(),
+		Source:    string(b[payloadHeader : payloadHeader+sourceLen]),
+		Line:      b[payloadHeader+sourceLen : size],
+	}
+	return msg, int64(size) + 2*sizeFieldLen, nil
+}
+
+// tailOffset returns the offset of the first of the last n records of the
+// first size bytes of r, and the number of records found, which is less than
+// n if there are fewer records.
+func tailOffset(r io.ReaderAt, size int64, n int) (int64, int, error) {
+	offset := size
+	count := 0
+	var sizeField [sizeFieldLen]byte
+	for count < n && offset > 0 {
+		if offset < 2*sizeFieldLen+payloadHeader {
+			return 0, 0, errCorrupted
+		}
+		if _, err := r.ReadAt(sizeField[:], offset-sizeFieldLen); err != nil {
+			return 0, 0, err
+		}
+		offset -= int64(binary.BigEndian.Uint32(sizeField[:])) + 2*sizeFieldLen
+		if offset < 0 {
+			return 0, 0, errCorrupted
+		}
+		count++
+	}
+	return offset, count, nil
+}
+
+// The index of a log file is a sequence of entries, each made of an offset in
+// the file and the greatest timestamp of the records before this offset, as
+// big-endian int64. An entry is added every indexInterval bytes of records,
+// and a last one when the file is rotated.
+const (
+	indexEntryLen = 16
+	indexInterval = 64 * 1024
+)
+
+type indexEntry struct {
+	offset  int64
+	maxTime int64
+}
+
+func encodeIndexEntry(e indexEntry) []byte {
+	b := make([]byte, indexEntryLen)
+	binary.BigEndian.PutUint64(b, uint64(e.offset))
+	binary.BigEndian.PutUint64(b[8:], uint64(e.maxTime))
+	return b
+}
+
+// readIndex reads the index file at path. A missing index is empty.
+func readIndex(path string) ([]indexEntry, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// An incomplete entry at the end is ignored.
+	index := make([]indexEntry, 0, len(b)/indexEntryLen)
+	for ; len(b) >= indexEntryLen; b = b[indexEntryLen:] {
+		index = append(index, indexEntry{
+			offset:  int64(binary.BigEndian.Uint64(b)),
+			maxTime: int64(binary.BigEndian.Uint64(b[8:])),
+		})
+	}
+	return index, nil
+}
+
+// validIndex returns the entries of index up to the last one that is valid
+// for the first size bytes of the log file r: the offsets and the greatest
+// timestamps of the entries never decrease, and the offset of the last entry
+// is the end of a record. The entries written last may be invalid after a
+// crash.
+func validIndex(r io.ReaderAt, size int64, index []indexEntry) []indexEntry {
+	n := 0
+	for ; n < len(index); n++ {
+		e := index[n]
+		if e.offset < 0 || e.offset > size {
+			break
+		}
+		if n > 0 && (e.offset < index[n-1].offset || e.maxTime < index[n-1].maxTime) {
+			break
+		}
+	}
+	for n > 0 && !isRecordEnd(r, index[n-1].offset) {
+		n--
+	}
+	return index[:n]
+}
+
+// isRecordEnd returns whether a record of r ends at offset, as far as the
+// size fields around its payload tell.
+func isRecordEnd(r io.ReaderAt, offset int64) bool {
+	if offset == 0 {
+		return true
+	}
+	if offset < 2*sizeFieldLen+payloadHeader {
+		return false
+	}
+	var sizeField [sizeFieldLen]byte
+	if _, err := r.ReadAt(sizeField[:], offset-sizeFieldLen); err != nil {
+		return false
+	}
+	size := binary.BigEndian.Uint32(sizeField[:])
+	if size < payloadHeader || size > maxPayloadSize {
+		return false
+	}
+	start := offset - int64(size) - 2*sizeFieldLen
+	if start < 0 {
+		return false
+	}
+	if _, err := r.ReadAt(sizeField[:], start); err != nil {
+		return false
+	}
+	return binary.BigEndian.Uint32(sizeField[:]) == size
+}
+
+// sinceOffset returns the offset from which to read the records with a
+// timestamp at or after since: all the records before it are older. As the
+// greatest timestamps of the entries never decrease, it's found with a binary
+// search.
+func sinceOffset(index []indexEntry, since int64) int64 {
+	i := sort.Search(len(index), func(i int) bool { return index[i].maxTime >= since })
+	if i == 0 {
+		return 0
+	}
+	return index[i-1].offset
+}
@@ -0,0 +1,155 @@
+// Package cache keeps a local copy of the logs of the containers whose
+// logging driver can't read back the messages it sends, so that they can
+// still be read with `docker logs`.
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/pkg/units"
+)
+
+const (
+	disabledKey = "cache-disabled"
+	maxSizeKey  = "cache-max-size"
+	maxFileKey  = "cache-max-file"
+
+	defaultMaxSize = "20m"
+	defaultMaxFile = "5"
+)
+
+func init() {
+	logger.RegisterCommonLogOpts([]string{disabledKey, maxSizeKey, maxFileKey}, ValidateLogOpt)
+}
+
+// ValidateLogOpt checks the options of the cache, cache-disabled,
+// cache-max-size and cache-max-file.
+func ValidateLogOpt(cfg map[string]string) error {
+	if v, ok := cfg[disabledKey]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid value for log opt '%s': %s", disabledKey, v)
+		}
+	}
+	if v, ok := cfg[maxSizeKey]; ok {
+		size, err := units.FromHumanSize(v)
+		if err != nil || size <= 0 {
+			return fmt.Errorf("invalid value for log opt '%s': %s", maxSizeKey, v)
+		}
+	}
+	if v, ok := cfg[maxFileKey]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid value for log opt '%s': %s", maxFileKey, v)
+		}
+	}
+	return nil
+}
+
+// WithLocalCache returns a Logger that sends the messages to l and also
+// writes them to a local cache at path, from which they are read back. The
+// cache rotates when it reaches cache-max-size, and keeps cache-max-file
+// files. l is returned as is if it can read the logs itself, or if the
+// cache is disabled by cache-disabled.
+func WithLocalCache(l logger.Logger, ctx logger.Context, path string) (logger.Logger, error) {
+	if _, ok := l.(logger.LogReader); ok {
+		return l, nil
+	}
+	if disabled(ctx) {
+		return l, nil
+	}
+
+	c, err := newCache(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return &loggerWithCache{Logger: l, cache: c}, nil
+}
+
+// NewReader returns a Logger reading the logs cached at path for the logging
+// driver configured by ctx, without starting that driver, for a container
+// that is stopped. ok is false if nothing was cached at path, or if the cache
+// is disabled by cache-disabled.
+func NewReader(ctx logger.Context, path string) (l logger.Logger, ok bool, err error) {
+	if disabled(ctx) {
+		return nil, false, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	c, err := newCache(ctx, path)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, true, nil
+}
+
+func disabled(ctx logger.Context) bool {
+	disabled, _ := strconv.ParseBool(ctx.Config[disabledKey])
+	return disabled
+}
+
+// newCache returns the json-file logger of the cache at path.
+func newCache(ctx logger.Context, path string) (*jsonfilelog.JSONFileLogger, error) {
+	cacheCtx := ctx
+	cacheCtx.Config = map[string]string{
+		"max-size": defaultMaxSize,
+		"max-file": defaultMaxFile,
+	}
+	if v, ok := ctx.Config[maxSizeKey]; ok {
+		cacheCtx.Config["max-size"] = v
+	}
+	if v, ok := ctx.Config[maxFileKey]; ok {
+		cacheCtx.Config["max-file"] = v
+	}
+	cacheCtx.LogPath = path
+
+	c, err := jsonfilelog.New(cacheCtx)
+	if err != nil {
+		return nil, err
+	}
+	return c.(*jsonfilelog.JSONFileLogger), nil
+}
+
+// loggerWithCache is a Logger that copies the messages to a json-file
+// logger, which the logs are read from.
+type loggerWithCache struct {
+	logger.Logger
+	mu    sync.Mutex
+	cache *jsonfilelog.JSONFileLogger
+}
+
+// Log sends the message to the logging driver, after writing it to the
+// cache. A failure to write to the cache is logged, but doesn't prevent the
+// message from being sent.
+func (l *loggerWithCache) Log(msg *logger.Message) error {
+	l.mu.Lock()
+	err := l.cache.Log(msg)
+	l.mu.Unlock()
+	if err != nil {
+		logrus.Errorf("Failed to write log message to the cache of container %s: %v", msg.ContainerID, err)
+	}
+	return l.Logger.Log(msg)
+}
+
+// ReadLogs reads the logs from the cache.
+func (l *loggerWithCache) ReadLogs(config logger.ReadConfig) *logger.LogWatcher {
+	return l.cache.ReadLogs(config)
+}
+
+// Close closes the logging driver and the cache.
+func (l *loggerWithCache) Close() error {
+	err := l.Logger.Close()
+	if cacheErr := l.cache.Close(); err == nil {
+		err = cacheErr
+	}
+	return err
+}
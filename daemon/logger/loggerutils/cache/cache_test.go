@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+type testLogger struct {
+	messages []string
+	closed   bool
+}
+
+func (l *testLogger) Log(m *logger.Message) error {
+	l.messages = append(l.messages, string(m.Line))
+	return nil
+}
+
+func (l *testLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+func (l *testLogger) Name() string { return "test" }
+
+func TestWithLocalCache(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "log-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	driver := &testLogger{}
+	ctx := logger.Context{Config: map[string]string{maxSizeKey: "1k", maxFileKey: "2"}}
+	l, err := WithLocalCache(driver, ctx, filepath.Join(tmp, "container.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name() != "test" {
+		t.Fatalf("expected the name of the driver, got %s", l.Name())
+	}
+
+	now := time.Now().UTC()
+	for _, line := range []string{"one", "two", "three"} {
+		if err := l.Log(&logger.Message{Line: []byte(line), Source: "stdout", Timestamp: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(driver.messages) != 3 {
+		t.Fatalf("expected the driver to get 3 messages, got %v", driver.messages)
+	}
+
+	reader, ok := l.(logger.LogReader)
+	if !ok {
+		t.Fatal("expected the logger to read logs")
+	}
+	watcher := reader.ReadLogs(logger.ReadConfig{Tail: 2})
+	var lines []string
+	for msg := range watcher.Msg {
+		lines = append(lines, string(msg.Line))
+	}
+	if len(lines) != 2 || lines[0] != "two\n" || lines[1] != "three\n" {
+		t.Fatalf("expected the last 2 lines from the cache, got %q", lines)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !driver.closed {
+		t.Fatal("expected the driver to be closed")
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "log-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "container.log")
+	ctx := logger.Context{Config: map[string]string{}}
+	if _, ok, err := NewReader(ctx, path); err != nil || ok {
+		t.Fatalf("expected no cache to be found, got %v and %v", ok, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no cache to be created, got %v", err)
+	}
+
+	l, err := WithLocalCache(&testLogger{}, ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(&logger.Message{Line: []byte("one"), Source: "stdout", Timestamp: time.Now().UTC()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok, err := NewReader(ctx, path)
+	if err != nil || !ok {
+		t.Fatalf("expected the cache to be found, got %v and %v", ok, err)
+	}
+	defer r.Close()
+	watcher := r.(logger.LogReader).ReadLogs(logger.ReadConfig{Tail: -1})
+	var lines []string
+	for msg := range watcher.Msg {
+		lines = append(lines, string(msg.Line))
+	}
+	if len(lines) != 1 || lines[0] != "one\n" {
+		t.Fatalf("expected the line from the cache, got %q", lines)
+	}
+
+	ctx.Config[disabledKey] = "true"
+	if _, ok, err := NewReader(ctx, path); err != nil || ok {
+		t.Fatalf("expected the disabled cache not to be read, got %v and %v", ok, err)
+	}
+}
+
+func TestWithLocalCacheDisabled(t *testing.T) {
+	driver := &testLogger{}
+	ctx := logger.Context{Config: map[string]string{disabledKey: "true"}}
+	l, err := WithLocalCache(driver, ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != driver {
+		t.Fatal("expected the driver not to be wrapped")
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	valid := map[string]string{disabledKey: "false", maxSizeKey: "10m", maxFileKey: "3", "tag": "x"}
+	if err := ValidateLogOpt(valid); err != nil {
+		t.Fatal(err)
+	}
+	for _, cfg := range []map[string]string{
+		{disabledKey: "maybe"},
+		{maxSizeKey: "big"},
+		{maxSizeKey: "0"},
+		{maxFileKey: "0"},
+	} {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("expected %v to be invalid", cfg)
+		}
+	}
+
+	// The options of the cache are accepted by all the drivers.
+	if err := logger.ValidateLogOpts("json-file", map[string]string{maxSizeKey: "10m", "max-size": "1m"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/units"
+)
+
+const (
+	// MultilineStartOpt is the logging option with the regular expression
+	// matching the first line of the multi-line records.
+	MultilineStartOpt = "multiline-start"
+	// MultilineContinueOpt is the logging option with the regular expression
+	// matching the lines that continue the current record.
+	MultilineContinueOpt = "multiline-continue"
+	// MultilineFlushTimeoutOpt is the logging option with the time after
+	// which a record is sent if no line follows.
+	MultilineFlushTimeoutOpt = "multiline-flush-timeout"
+	// MultilineMaxSizeOpt is the logging option with the maximum size of a
+	// record.
+	MultilineMaxSizeOpt = "multiline-max-size"
+
+	defaultMultilineFlushTimeout = time.Second
+	defaultMultilineMaxSize      = 16 * 1024
+)
+
+func init() {
+	RegisterCommonLogOpts([]string{MultilineStartOpt, MultilineContinueOpt, MultilineFlushTimeoutOpt, MultilineMaxSizeOpt}, func(cfg map[string]string) error {
+		_, err := ParseMultilineConfig(cfg)
+		return err
+	})
+}
+
+// MultilineConfig configures the Copier to join the lines of the output of
+// a container into multi-line records, like stack traces. A line continues
+// the current record unless it matches Start, and if Continue is set, only if
+// it matches Continue.
+type MultilineConfig struct {
+	Start        *regexp.Regexp
+	Continue     *regexp.Regexp
+	FlushTimeout time.Duration
+	MaxSize      int
+}
+
+// ParseMultilineConfig returns the multi-line configuration set by the
+// logging options cfg, or nil if lines aren't joined.
+func ParseMultilineConfig(cfg map[string]string) (*MultilineConfig, error) {
+	m := &MultilineConfig{
+		FlushTimeout: defaultMultilineFlushTimeout,
+		MaxSize:      defaultMultilineMaxSize,
+	}
+	var err error
+	if v, ok := cfg[MultilineStartOpt]; ok {
+		if m.Start, err = regexp.Compile(v); err != nil {
+			return nil, fmt.Errorf("logger: invalid value for log opt '%s': %v", MultilineStartOpt, err)
+		}
+	}
+	if v, ok := cfg[MultilineContinueOpt]; ok {
+		if m.Continue, err = regexp.Compile(v); err != nil {
+			return nil, fmt.Errorf("logger: invalid value for log opt '%s': %v", MultilineContinueOpt, err)
+		}
+	}
+	if v, ok := cfg[MultilineFlushTimeoutOpt]; ok {
+		if m.FlushTimeout, err = time.ParseDuration(v); err != nil || m.FlushTimeout <= 0 {
+			return nil, fmt.Errorf("logger: invalid value for log opt '%s': %s", MultilineFlushTimeoutOpt, v)
+		}
+	}
+	if v, ok := cfg[MultilineMaxSizeOpt]; ok {
+		size, err := units.RAMInBytes(v)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("logger: invalid value for log opt '%s': %s", MultilineMaxSizeOpt, v)
+		}
+		m.MaxSize = int(size)
+	}
+
+	if m.Start == nil && m.Continue == nil {
+		for _, key := range []string{MultilineFlushTimeoutOpt, MultilineMaxSizeOpt} {
+			if _, ok := cfg[key]; ok {
+				return nil, fmt.Errorf("logger: log opt '%s' requires '%s' or '%s'", key, MultilineStartOpt, MultilineContinueOpt)
+			}
+		}
+		return nil, nil
+	}
+	return m, nil
+}
+
+// continues returns whether line continues a record of size bytes.
+func (m *MultilineConfig) continues(line []byte, size int) bool {
+	if size+1+len(line) > m.MaxSize {
+		return false
+	}
+	if m.Start != nil && m.Start.Match(line) {
+		return false
+	}
+	return m.Continue == nil || m.Continue.Match(line)
+}
+
+// copyMultiline copies the lines of src to the logger, joining them into
+// records. A record is sent when a line starts a new one, when it would
+// exceed the maximum size, or when no line follows before the flush timeout.
+func (c *Copier) copyMultiline(name string, src io.Reader) {
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(src)
+		for {
+			line, err := reader.ReadBytes('\n')
+			line = bytes.TrimSuffix(line, []byte{'\n'})
+			if err == nil || len(line) > 0 {
+				lines <- line
+			}
+			if err != nil {
+				if err != io.EOF {
+					logrus.Errorf("Error scanning log stream: %s", err)
+				}
+				return
+			}
+		}
+	}()
+
+	var (
+		record    []byte
+		timestamp time.Time
+		pending   bool
+	)
+	flush := func() {
+		if pending {
+			c.log(name, record, timestamp)
+			record, pending = nil, false
+		}
+	}
+	timer := time.NewTimer(c.multiline.FlushTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush()
+				return
+			}
+			if pending && c.multiline.continues(line, len(record)) {
+				record = append(append(record, '\n'), line...)
+			} else {
+				flush()
+				record, timestamp, pending = line, time.Now().UTC(), true
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(c.multiline.FlushTimeout)
+		case <-timer.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/plugins/logdriver"
+	"github.com/docker/docker/pkg/stringid"
+)
+
+func init() {
+	plugins.Handle(logdriver.Type, func(name string, c *plugins.Client) {
+		factory.registerPlugin(name, makePluginCreator(name, c), makePluginReaderCreator(name, c))
+	})
+}
+
+// SetPluginFifoRoot sets the directory of the FIFOs the logs are streamed to
+// the plugins through.
+func SetPluginFifoRoot(root string) {
+	pluginFifoRoot = root
+}
+
+// lookupPlugin returns the Creator of the logging driver plugin named name.
+func lookupPlugin(name string) (Creator, error) {
+	c, err := lookupPluginClient(name)
+	if err != nil {
+		return nil, err
+	}
+	return makePluginCreator(name, c), nil
+}
+
+// lookupPluginReader returns the Creator of the readers of the logging
+// driver plugin named name.
+func lookupPluginReader(name string) (Creator, error) {
+	c, err := lookupPluginClient(name)
+	if err != nil {
+		return nil, err
+	}
+	return makePluginReaderCreator(name, c), nil
+}
+
+func lookupPluginClient(name string) (pluginClient, error) {
+	pl, err := plugins.Lookup(name, logdriver.Type)
+	if err != nil {
+		logrus.Debugf("logger: no logging driver plugin named %s: %v", name, err)
+		return nil, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	}
+	return pl.Client, nil
+}
+
+// makePluginCreator returns a Creator of the loggers sending the messages to
+// the plugin named name through a FIFO.
+func makePluginCreator(name string, c pluginClient) Creator {
+	return func(ctx Context) (Logger, error) {
+		proxy := &logPluginProxy{c}
+		caps, err := proxy.Capabilities()
+		if err != nil {
+			// The capabilities are optional.
+			logrus.Debugf("logger: could not get the capabilities of the logging driver plugin %s: %v", name, err)
+		}
+
+		if err := os.MkdirAll(pluginFifoRoot, 0700); err != nil {
+			return nil, err
+		}
+		fifoPath := filepath.Join(pluginFifoRoot, stringid.GenerateNonCryptoID())
+		stream, err := openPluginFifo(fifoPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := proxy.StartLogging(fifoPath, ctx); err != nil {
+			stream.Close()
+			os.Remove(fifoPath)
+			return nil, err
+		}
+
+		a := &pluginAdapter{
+			driverName: name,
+			fifoPath:   fifoPath,
+			plugin:     proxy,
+			ctx:        ctx,
+			stream:     stream,
+			enc:        logdriver.NewEntryEncoder(stream),
+		}
+		if caps.ReadLogs {
+			return &pluginAdapterWithRead{a}, nil
+		}
+		return a, nil
+	}
+}
+
+// makePluginReaderCreator returns a Creator of the loggers reading the logs
+// of a stopped container from the plugin named name. The plugin isn't asked
+// to start logging.
+func makePluginReaderCreator(name string, c pluginClient) Creator {
+	return func(ctx Context) (Logger, error) {
+		proxy := &logPluginProxy{c}
+		caps, err := proxy.Capabilities()
+		if err != nil {
+			logrus.Debugf("logger: could not get the capabilities of the logging driver plugin %s: %v", name, err)
+		}
+
+		r := &pluginReader{driverName: name, plugin: proxy, ctx: ctx}
+		if caps.ReadLogs {
+			return &pluginReaderWithRead{r}, nil
+		}
+		return r, nil
+	}
+}
+
+// pluginAdapter is a Logger streaming the messages to a logging driver
+// plugin.
+type pluginAdapter struct {
+	driverName string
+	fifoPath   string
+	plugin     *logPluginProxy
+	ctx        Context
+
+	mu     sync.Mutex
+	stream io.WriteCloser
+	enc    logdriver.Encoder
+}
+
+// pluginAdapterWithRead is a pluginAdapter whose plugin can read the logs.
+type pluginAdapterWithRead struct {
+	*pluginAdapter
+}
+
+// Log sends the message to the plugin.
+func (a *pluginAdapter) Log(msg *Message) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(&logdriver.Entry{
+		Source:   msg.Source,
+		TimeNano: msg.Timestamp.UnixNano(),
+		Line:     msg.Line,
+	})
+}
+
+// Name returns the name of the plugin.
+func (a *pluginAdapter) Name() string {
+	return a.driverName
+}
+
+// Close closes the FIFO and tells the plugin that logging stopped.
+func (a *pluginAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stream.Close()
+	err := a.plugin.StopLogging(a.fifoPath)
+	if rmErr := os.Remove(a.fifoPath); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// ReadLogs reads the logs from the plugin.
+func (a *pluginAdapterWithRead) ReadLogs(config ReadConfig) *LogWatcher {
+	return readPluginLogs(a.plugin, a.ctx, config)
+}
+
+// pluginReader is a Logger that only reads the logs of a stopped container
+// from a logging driver plugin.
+type pluginReader struct {
+	driverName string
+	plugin     *logPluginProxy
+	ctx        Context
+}
+
+// pluginReaderWithRead is a pluginReader whose plugin can read the logs.
+type pluginReaderWithRead struct {
+	*pluginReader
+}
+
+// Log fails, as the plugin didn't start logging.
+func (r *pluginReader) Log(msg *Message) error {
+	return fmt.Errorf("logger: the logs of container %s are only read from the plugin %s", r.ctx.ContainerID, r.driverName)
+}
+
+// Name returns the name of the plugin.
+func (r *pluginReader) Name() string {
+	return r.driverName
+}
+
+// Close does nothing, as the plugin didn't start logging.
+func (r *pluginReader) Close() error {
+	return nil
+}
+
+// ReadLogs reads the logs from the plugin.
+func (r *pluginReaderWithRead) ReadLogs(config ReadConfig) *LogWatcher {
+	return readPluginLogs(r.plugin, r.ctx, config)
+}
+
+// readPluginLogs reads the logs of the container described by ctx from the
+// plugin.
+func readPluginLogs(plugin *logPluginProxy, ctx Context, config ReadConfig) *LogWatcher {
+	watcher := NewLogWatcher()
+
+	go func() {
+		defer close(watcher.Msg)
+		stream, err := plugin.ReadLogs(ctx, config)
+		if err != nil {
+			watcher.Err <- err
+			return
+		}
+		defer stream.Close()
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			// Stop the decoder, which may wait for messages to follow.
+			select {
+			case <-watcher.WatchClose():
+				stream.Close()
+			case <-done:
+			}
+		}()
+
+		dec := logdriver.NewEntryDecoder(stream)
+		for {
+			var entry logdriver.Entry
+			if err := dec.Decode(&entry); err != nil {
+				if err != io.EOF {
+					select {
+					case <-watcher.WatchClose():
+					default:
+						watcher.Err <- err
+					}
+				}
+				return
+			}
+			msg := &Message{
+				ContainerID: ctx.ContainerID,
+				Line:        append(entry.Line, '\n'),
+				Source:      entry.Source,
+				Timestamp:   time.Unix(0, entry.TimeNano).UTC(),
+			}
+			select {
+			case watcher.Msg <- msg:
+			case <-watcher.WatchClose():
+				return
+			}
+		}
+	}()
+	return watcher
+}
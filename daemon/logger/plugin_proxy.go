@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// pluginClient is the client of a plugin, as implemented by plugins.Client.
+type pluginClient interface {
+	Call(string, interface{}, interface{}) error
+	Stream(string, interface{}) (io.ReadCloser, error)
+}
+
+// logPluginProxy calls the methods of a logging driver plugin.
+type logPluginProxy struct {
+	pluginClient
+}
+
+type logPluginProxyStartLoggingRequest struct {
+	File string
+	Info Context
+}
+
+type logPluginProxyStartLoggingResponse struct {
+	Err string
+}
+
+// StartLogging asks the plugin to read the log messages of the container
+// described by info from the FIFO file.
+func (pp *logPluginProxy) StartLogging(file string, info Context) error {
+	var ret logPluginProxyStartLoggingResponse
+	req := logPluginProxyStartLoggingRequest{File: file, Info: info}
+	if err := pp.Call("LogDriver.StartLogging", req, &ret); err != nil {
+		return err
+	}
+	if ret.Err != "" {
+		return errors.New(ret.Err)
+	}
+	return nil
+}
+
+type logPluginProxyStopLoggingRequest struct {
+	File string
+}
+
+type logPluginProxyStopLoggingResponse struct {
+	Err string
+}
+
+// StopLogging tells the plugin that no message will be written to the FIFO
+// file anymore.
+func (pp *logPluginProxy) StopLogging(file string) error {
+	var ret logPluginProxyStopLoggingResponse
+	req := logPluginProxyStopLoggingRequest{File: file}
+	if err := pp.Call("LogDriver.StopLogging", req, &ret); err != nil {
+		return err
+	}
+	if ret.Err != "" {
+		return errors.New(ret.Err)
+	}
+	return nil
+}
+
+// logPluginCapabilities lists the optional features a plugin supports.
+type logPluginCapabilities struct {
+	ReadLogs bool
+}
+
+type logPluginProxyCapabilitiesResponse struct {
+	Cap logPluginCapabilities
+	Err string
+}
+
+// Capabilities returns the optional features the plugin supports.
+func (pp *logPluginProxy) Capabilities() (logPluginCapabilities, error) {
+	var ret logPluginProxyCapabilitiesResponse
+	if err := pp.Call("LogDriver.Capabilities", nil, &ret); err != nil {
+		return logPluginCapabilities{}, err
+	}
+	if ret.Err != "" {
+		return logPluginCapabilities{}, errors.New(ret.Err)
+	}
+	return ret.Cap, nil
+}
+
+type logPluginProxyReadLogsRequest struct {
+	Info   Context
+	Config ReadConfig
+}
+
+// ReadLogs asks the plugin for the logs of the container described by info,
+// and returns the stream of the entries of the response.
+func (pp *logPluginProxy) ReadLogs(info Context, config ReadConfig) (io.ReadCloser, error) {
+	req := logPluginProxyReadLogsRequest{Info: info, Config: config}
+	return pp.Stream("LogDriver.ReadLogs", req)
+}
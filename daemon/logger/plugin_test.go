@@ -0,0 +1,178 @@
+// +build linux freebsd
+
+package logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/plugins/logdriver"
+	"github.com/docker/docker/pkg/tlsconfig"
+)
+
+func TestPluginLogger(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "logging-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	defer func(root string) { pluginFifoRoot = root }(pluginFifoRoot)
+	pluginFifoRoot = tmp
+
+	received := make(chan *logdriver.Entry, 10)
+	stopped := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/LogDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"Cap": map[string]bool{"ReadLogs": true}})
+	})
+	mux.HandleFunc("/LogDriver.StartLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req logPluginProxyStartLoggingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Info.ContainerID != "abcdef" {
+			t.Fatalf("expected the container ID to be sent, got %q", req.Info.ContainerID)
+		}
+		go func() {
+			f, err := os.Open(req.File)
+			if err != nil {
+				return
+			}
+			defer f.Close()
+			dec := logdriver.NewEntryDecoder(f)
+			for {
+				var entry logdriver.Entry
+				if err := dec.Decode(&entry); err != nil {
+					return
+				}
+				received <- &entry
+			}
+		}()
+		json.NewEncoder(w).Encode(map[string]string{})
+	})
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req logPluginProxyStopLoggingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		stopped <- req.File
+		json.NewEncoder(w).Encode(map[string]string{})
+	})
+	mux.HandleFunc("/LogDriver.ReadLogs", func(w http.ResponseWriter, r *http.Request) {
+		enc := logdriver.NewEntryEncoder(w)
+		enc.Encode(&logdriver.Entry{Source: "stdout", TimeNano: 42, Line: []byte("read back")})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := plugins.NewClient(server.URL, tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := makePluginCreator("test-plugin", c)(Context{ContainerID: "abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name() != "test-plugin" {
+		t.Fatalf("expected the name of the plugin, got %s", l.Name())
+	}
+
+	now := time.Now()
+	if err := l.Log(&Message{Line: []byte("hello"), Source: "stderr", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case entry := <-received:
+		expected := &logdriver.Entry{Source: "stderr", TimeNano: now.UnixNano(), Line: []byte("hello")}
+		if !reflect.DeepEqual(entry, expected) {
+			t.Fatalf("expected %+v, got %+v", expected, entry)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the plugin to receive the message")
+	}
+
+	reader, ok := l.(LogReader)
+	if !ok {
+		t.Fatal("expected the logger to read logs")
+	}
+	watcher := reader.ReadLogs(ReadConfig{Tail: -1})
+	msg := <-watcher.Msg
+	if msg == nil || string(msg.Line) != "read back\n" || msg.Source != "stdout" || msg.Timestamp.UnixNano() != 42 {
+		t.Fatalf("unexpected message read from the plugin: %+v", msg)
+	}
+	if _, ok := <-watcher.Msg; ok {
+		t.Fatal("expected the end of the logs")
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := <-stopped
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("expected the FIFO %s to be removed, got %v", file, err)
+	}
+}
+
+func TestPluginLogReader(t *testing.T) {
+	var readLogs bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/LogDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"Cap": map[string]bool{"ReadLogs": readLogs}})
+	})
+	mux.HandleFunc("/LogDriver.StartLogging", func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("expected the plugin not to be asked to start logging")
+	})
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("expected the plugin not to be asked to stop logging")
+	})
+	mux.HandleFunc("/LogDriver.ReadLogs", func(w http.ResponseWriter, r *http.Request) {
+		enc := logdriver.NewEntryEncoder(w)
+		enc.Encode(&logdriver.Entry{Source: "stdout", TimeNano: 42, Line: []byte("read back")})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := plugins.NewClient(server.URL, tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := makePluginReaderCreator("test-plugin", c)(Context{ContainerID: "abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.(LogReader); ok {
+		t.Fatal("expected the logger not to read logs without the capability")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	readLogs = true
+	l, err = makePluginReaderCreator("test-plugin", c)(Context{ContainerID: "abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, ok := l.(LogReader)
+	if !ok {
+		t.Fatal("expected the logger to read logs")
+	}
+	watcher := reader.ReadLogs(ReadConfig{Tail: -1})
+	msg := <-watcher.Msg
+	if msg == nil || string(msg.Line) != "read back\n" || msg.ContainerID != "abcdef" {
+		t.Fatalf("unexpected message read from the plugin: %+v", msg)
+	}
+	if _, ok := <-watcher.Msg; ok {
+		t.Fatal("expected the end of the logs")
+	}
+	if err := l.Log(&Message{Line: []byte("hello")}); err == nil {
+		t.Fatal("expected the reader not to log messages")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
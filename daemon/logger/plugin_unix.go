@@ -0,0 +1,28 @@
+// +build linux freebsd
+
+package logger
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// pluginFifoRoot is the directory of the FIFOs the logs are streamed to the
+// plugins through. The daemon sets it under its exec root.
+var pluginFifoRoot = "/var/run/docker/logging"
+
+// openPluginFifo creates a FIFO at path and opens it for writing.
+func openPluginFifo(path string) (io.WriteCloser, error) {
+	if err := syscall.Mkfifo(path, 0700); err != nil {
+		return nil, err
+	}
+	// Opened for reading and writing, the FIFO doesn't wait for the plugin
+	// to open it.
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return f, nil
+}
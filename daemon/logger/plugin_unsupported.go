@@ -0,0 +1,14 @@
+// +build !linux,!freebsd
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+var pluginFifoRoot = ""
+
+func openPluginFifo(path string) (io.WriteCloser, error) {
+	return nil, errors.New("logger: logging driver plugins are not supported on this platform")
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/units"
+)
+
+const (
+	// ModeOpt is the logging option that selects how the messages are
+	// delivered to the logging driver, ModeBlocking or ModeNonBlocking.
+	ModeOpt = "mode"
+	// MaxBufferSizeOpt is the logging option that sets the size of the
+	// buffer of the non-blocking mode.
+	MaxBufferSizeOpt = "max-buffer-size"
+
+	// ModeBlocking sends the messages to the driver directly, the output of
+	// the container blocks while the driver is busy. It is the default.
+	ModeBlocking = "blocking"
+	// ModeNonBlocking buffers the messages in memory before sending them to
+	// the driver, and drops the oldest ones when the buffer is full.
+	ModeNonBlocking = "non-blocking"
+
+	defaultMaxBufferSize = 1024 * 1024
+)
+
+var errRingClosed = errors.New("logger: the ring buffer is closed")
+
+func init() {
+	RegisterCommonLogOpts([]string{ModeOpt, MaxBufferSizeOpt}, validateModeOpts)
+}
+
+func validateModeOpts(cfg map[string]string) error {
+	switch cfg[ModeOpt] {
+	case "", ModeBlocking, ModeNonBlocking:
+	default:
+		return fmt.Errorf("logger: invalid value for log opt '%s': %s", ModeOpt, cfg[ModeOpt])
+	}
+	if _, ok := cfg[MaxBufferSizeOpt]; ok {
+		if cfg[ModeOpt] != ModeNonBlocking {
+			return fmt.Errorf("logger: log opt '%s' requires '%s=%s'", MaxBufferSizeOpt, ModeOpt, ModeNonBlocking)
+		}
+		if _, err := MaxBufferSize(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxBufferSize returns the size of the buffer of the non-blocking mode set
+// by the max-buffer-size option of cfg, 1MB by default.
+func MaxBufferSize(cfg map[string]string) (int64, error) {
+	v, ok := cfg[MaxBufferSizeOpt]
+	if !ok {
+		return defaultMaxBufferSize, nil
+	}
+	size, err := units.RAMInBytes(v)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("logger: invalid value for log opt '%s': %s", MaxBufferSizeOpt, v)
+	}
+	return size, nil
+}
+
+// RingLogger is a Logger that buffers the messages in memory and sends them
+// to the wrapped driver from a goroutine, so that Log never blocks. When the
+// size of the buffered messages would exceed the maximum size, the oldest
+// messages are dropped.
+type RingLogger struct {
+	l       Logger
+	maxSize int64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*Message
+	size    int64
+	dropped uint64
+	closed  bool
+	done    chan struct{}
+}
+
+// ringWithReader is a RingLogger whose driver can read the logs.
+type ringWithReader struct {
+	*RingLogger
+}
+
+// NewRingLogger returns a RingLogger sending the messages to driver, with a
+// buffer of maxSize bytes. The returned Logger is a LogReader if driver is.
+func NewRingLogger(driver Logger, maxSize int64) Logger {
+	r := &RingLogger{
+		l:       driver,
+		maxSize: maxSize,
+		done:    make(chan struct{}),
+	}
+	r.cond = sync.NewCond(&r.mu)
+	go r.run()
+
+	if _, ok := driver.(LogReader); ok {
+		return &ringWithReader{r}
+	}
+	return r
+}
+
+// Log adds the message to the buffer, dropping the oldest messages if it is
+// full.
+func (r *RingLogger) Log(msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errRingClosed
+	}
+
+	size := int64(len(msg.Line))
+	for len(r.queue) > 0 && r.size+size > r.maxSize {
+		r.pop()
+		r.dropped++
+	}
+	r.queue = append(r.queue, msg)
+	r.size += size
+	r.cond.Signal()
+	return nil
+}
+
+// pop removes the oldest message of the buffer. r.mu must be held.
+func (r *RingLogger) pop() *Message {
+	msg := r.queue[0]
+	r.queue[0] = nil
+	r.queue = r.queue[1:]
+	r.size -= int64(len(msg.Line))
+	return msg
+}
+
+// run sends the buffered messages to the driver, until the RingLogger is
+// closed and the buffer is empty.
+func (r *RingLogger) run() {
+	defer close(r.done)
+	for {
+		r.mu.Lock()
+		for len(r.queue) == 0 && !r.closed {
+			r.cond.Wait()
+		}
+		if len(r.queue) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		msg := r.pop()
+		r.mu.Unlock()
+
+		if err := r.l.Log(msg); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, r.l.Name(), err)
+		}
+	}
+}
+
+// Dropped returns the number of messages dropped because the buffer was
+// full.
+func (r *RingLogger) Dropped() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+// Name returns the name of the wrapped driver.
+func (r *RingLogger) Name() string {
+	return r.l.Name()
+}
+
+// Close sends the messages left in the buffer to the driver, and closes it.
+func (r *RingLogger) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.cond.Broadcast()
+	r.mu.Unlock()
+
+	<-r.done
+	return r.l.Close()
+}
+
+// ReadLogs reads the logs from the wrapped driver.
+func (r *ringWithReader) ReadLogs(config ReadConfig) *LogWatcher {
+	return r.l.(LogReader).ReadLogs(config)
+}
+
+// DroppedMessages returns the number of messages dropped by l, if it is a
+// non-blocking logger.
+func DroppedMessages(l Logger) uint64 {
+	switch r := l.(type) {
+	case *RingLogger:
+		return r.Dropped()
+	case *ringWithReader:
+		return r.Dropped()
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+// blockingLogger is a Logger that blocks until it is released.
+type blockingLogger struct {
+	release  chan struct{}
+	received chan struct{}
+	lines    []string
+}
+
+func (l *blockingLogger) Log(m *Message) error {
+	l.received <- struct{}{}
+	<-l.release
+	l.lines = append(l.lines, string(m.Line))
+	return nil
+}
+
+func (l *blockingLogger) Close() error { return nil }
+
+func (l *blockingLogger) Name() string { return "blocking" }
+
+func TestRingLoggerDropsOldest(t *testing.T) {
+	driver := &blockingLogger{release: make(chan struct{}), received: make(chan struct{}, 10)}
+	r := NewRingLogger(driver, 6)
+
+	if err := r.Log(&Message{Line: []byte("0")}); err != nil {
+		t.Fatal(err)
+	}
+	// The driver is blocked on the first message, the others are buffered.
+	<-driver.received
+	for _, line := range []string{"11", "22", "33", "44"} {
+		if err := r.Log(&Message{Line: []byte(line)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if dropped := DroppedMessages(r); dropped != 1 {
+		t.Fatalf("expected 1 dropped message, got %d", dropped)
+	}
+
+	close(driver.release)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"0", "22", "33", "44"}
+	if !reflect.DeepEqual(driver.lines, expected) {
+		t.Fatalf("expected the driver to get %v, got %v", expected, driver.lines)
+	}
+	if err := r.Log(&Message{Line: []byte("55")}); err != errRingClosed {
+		t.Fatalf("expected an error after close, got %v", err)
+	}
+}
+
+func TestRingLoggerReader(t *testing.T) {
+	r := NewRingLogger(&TestLoggerText{}, defaultMaxBufferSize)
+	defer r.Close()
+	if _, ok := r.(LogReader); ok {
+		t.Fatal("expected the ring logger not to read logs when the driver doesn't")
+	}
+}
+
+func TestValidateModeOpts(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{},
+		{ModeOpt: ModeBlocking},
+		{ModeOpt: ModeNonBlocking, MaxBufferSizeOpt: "4m"},
+	} {
+		if err := ValidateLogOpts("test", cfg); err != nil {
+			t.Fatalf("expected %v to be valid: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []map[string]string{
+		{ModeOpt: "sometimes"},
+		{MaxBufferSizeOpt: "4m"},
+		{ModeOpt: ModeNonBlocking, MaxBufferSizeOpt: "lots"},
+	} {
+		if err := ValidateLogOpts("test", cfg); err == nil {
+			t.Fatalf("expected %v to be invalid", cfg)
+		}
+	}
+}
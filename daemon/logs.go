@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"io"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -31,8 +32,10 @@ type ContainerLogsConfig struct {
 }
 
 // ContainerLogs hooks up a container's stdout and stderr streams
-// configured with the given struct.
-func (daemon *Daemon) ContainerLogs(containerName string, config *ContainerLogsConfig) error {
+// configured with the given struct. started is closed once the logs can be
+// read and the stream starts, errors returned before are the ones that can
+// still be reported to the client with the appropriate status code.
+func (daemon *Daemon) ContainerLogs(containerName string, config *ContainerLogsConfig, started chan struct{}) error {
 	container, err := daemon.Get(containerName)
 	if err != nil {
 		return derr.ErrorCodeNoSuchContainer.WithArgs(containerName)
@@ -42,23 +45,34 @@ func (daemon *Daemon) ContainerLogs(containerName string, config *ContainerLogsC
 		return derr.ErrorCodeNeedStream
 	}
 
-	outStream := config.OutStream
-	errStream := outStream
-	if !container.Config.Tty {
-		errStream = stdcopy.NewStdWriter(outStream, stdcopy.Stderr)
-		outStream = stdcopy.NewStdWriter(outStream, stdcopy.Stdout)
-	}
-	config.OutStream = outStream
-
 	cLog, err := daemon.getLogger(container)
 	if err != nil {
 		return err
 	}
+	if cLog != container.LogDriver {
+		// The logger was started only to read the logs of a stopped container.
+		defer cLog.Close()
+	}
 	logReader, ok := cLog.(logger.LogReader)
 	if !ok {
 		return logger.ErrReadLogsNotSupported
 	}
 
+	close(started)
+	// Send the response right away, even if the container has not yet
+	// produced any data.
+	if flusher, ok := config.OutStream.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	outStream := config.OutStream
+	errStream := outStream
+	if !container.Config.Tty {
+		errStream = stdcopy.NewStdWriter(outStream, stdcopy.Stderr)
+		outStream = stdcopy.NewStdWriter(outStream, stdcopy.Stdout)
+	}
+	config.OutStream = outStream
+
 	follow := config.Follow && container.IsRunning()
 	tailLines, err := strconv.Atoi(config.Tail)
 	if err != nil {
@@ -104,16 +118,16 @@ func (daemon *Daemon) getLogger(container *container.Container) (logger.Logger,
 	if container.LogDriver != nil && container.IsRunning() {
 		return container.LogDriver, nil
 	}
-	cfg := container.GetLogConfig(daemon.defaultLogConfig)
+	cfg := container.GetLogConfig(daemon.getDefaultLogConfig())
 	if err := logger.ValidateLogOpts(cfg.Type, cfg.Config); err != nil {
 		return nil, err
 	}
-	return container.StartLogger(cfg)
+	return container.StartLogReader(cfg)
 }
 
 // StartLogging initializes and starts the container logging stream.
 func (daemon *Daemon) StartLogging(container *container.Container) error {
-	cfg := container.GetLogConfig(daemon.defaultLogConfig)
+	cfg := container.GetLogConfig(daemon.getDefaultLogConfig())
 	if cfg.Type == "none" {
 		return nil // do not start logging routines
 	}
@@ -126,7 +140,13 @@ func (daemon *Daemon) StartLogging(container *container.Container) error {
 		return derr.ErrorCodeInitLogger.WithArgs(err)
 	}
 
+	multiline, err := logger.ParseMultilineConfig(cfg.Config)
+	if err != nil {
+		l.Close()
+		return derr.ErrorCodeInitLogger.WithArgs(err)
+	}
 	copier := logger.NewCopier(container.ID, map[string]io.Reader{"stdout": container.StdoutPipe(), "stderr": container.StderrPipe()}, l)
+	copier.SetMultiline(multiline)
 	container.LogCopier = copier
 	copier.Run()
 	container.LogDriver = l
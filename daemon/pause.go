@@ -39,6 +39,7 @@ func (daemon *Daemon) containerPause(container *container.Container) error {
 		return err
 	}
 	container.Paused = true
+	daemon.updateHealthMonitor(container)
 	daemon.LogContainerEvent(container, "pause")
 	return nil
 }
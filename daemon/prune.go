@@ -0,0 +1,262 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/timeutils"
+	"github.com/docker/docker/runconfig"
+)
+
+var (
+	acceptedPruneFilters = map[string]bool{
+		"label": true,
+		"until": true,
+	}
+	acceptedImagesPruneFilters = map[string]bool{
+		"dangling": true,
+		"label":    true,
+		"until":    true,
+	}
+)
+
+// ContainersPrune removes the containers that are not running and match the
+// filters, and returns their IDs along with the size of their writable
+// layers.
+func (daemon *Daemon) ContainersPrune(pruneFilters filters.Args) (*types.ContainersPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedPruneFilters); err != nil {
+		return nil, err
+	}
+	until, err := getUntilFromPruneFilters(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &types.ContainersPruneReport{}
+	for _, c := range daemon.List() {
+		if c.IsRunning() {
+			continue
+		}
+		if !until.IsZero() && !c.Created.Before(until) {
+			continue
+		}
+		if !matchPruneLabels(pruneFilters, c.Config.Labels) {
+			continue
+		}
+
+		sizeRw, _ := daemon.getSize(c)
+		if err := daemon.ContainerRm(c.ID, &ContainerRmConfig{}); err != nil {
+			logrus.Warnf("Failed to prune container %s: %v", c.ID, err)
+			continue
+		}
+		if sizeRw > 0 {
+			rep.SpaceReclaimed += uint64(sizeRw)
+		}
+		rep.ContainersDeleted = append(rep.ContainersDeleted, c.ID)
+	}
+	return rep, nil
+}
+
+// VolumesPrune removes the volumes that no container references. Volumes
+// have neither labels nor a creation time, so none of them is removed when
+// the label or until filters are used.
+func (daemon *Daemon) VolumesPrune(pruneFilters filters.Args) (*types.VolumesPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedPruneFilters); err != nil {
+		return nil, err
+	}
+	if _, err := getUntilFromPruneFilters(pruneFilters); err != nil {
+		return nil, err
+	}
+
+	rep := &types.VolumesPruneReport{}
+	if pruneFilters.Include("label") || pruneFilters.Include("until") {
+		return rep, nil
+	}
+
+	for _, v := range daemon.volumes.List() {
+		if daemon.volumes.Count(v) > 0 {
+			continue
+		}
+
+		var size int64
+		if v.DriverName() == "local" {
+			size, _ = directory.Size(v.Path())
+		}
+		if err := daemon.volumes.Remove(v); err != nil {
+			logrus.Warnf("Failed to prune volume %s: %v", v.Name(), err)
+			continue
+		}
+		if size > 0 {
+			rep.SpaceReclaimed += uint64(size)
+		}
+		rep.VolumesDeleted = append(rep.VolumesDeleted, v.Name())
+	}
+	return rep, nil
+}
+
+// ImagesPrune removes the images that no container uses and match the
+// filters. Only dangling images, which have no reference and no child, are
+// removed unless the dangling filter is false, in which case the references
+// of the unused images are removed as well.
+func (daemon *Daemon) ImagesPrune(pruneFilters filters.Args) (*types.ImagesPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedImagesPruneFilters); err != nil {
+		return nil, err
+	}
+	until, err := getUntilFromPruneFilters(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	danglingOnly := true
+	if pruneFilters.Include("dangling") {
+		if pruneFilters.ExactMatch("dangling", "false") || pruneFilters.ExactMatch("dangling", "0") {
+			danglingOnly = false
+		} else if !pruneFilters.ExactMatch("dangling", "true") && !pruneFilters.ExactMatch("dangling", "1") {
+			return nil, fmt.Errorf("Invalid filter 'dangling=%s'", pruneFilters.Get("dangling"))
+		}
+	}
+
+	// Record the size of the layers first, they are gone once deleted.
+	layerSizes := daemon.layerDiffSizes()
+
+	rep := &types.ImagesPruneReport{}
+	// Removing an image may leave its parent without children, so keep
+	// going until there is nothing left to remove.
+	for removed := true; removed; {
+		removed = false
+		for id, img := range daemon.imageStore.Heads() {
+			if daemon.getContainerUsingImage(id) != nil {
+				continue
+			}
+			if !until.IsZero() && !img.Created.Before(until) {
+				continue
+			}
+			var labels map[string]string
+			if img.Config != nil {
+				labels = img.Config.Labels
+			}
+			if !matchPruneLabels(pruneFilters, labels) {
+				continue
+			}
+
+			refs := daemon.tagStore.References(id)
+			if len(refs) > 0 && danglingOnly {
+				continue
+			}
+
+			var records []types.ImageDelete
+			if len(refs) == 0 {
+				records, err = daemon.ImageDelete(id.String(), false, true)
+			} else {
+				for _, ref := range refs {
+					var r []types.ImageDelete
+					r, err = daemon.ImageDelete(ref.String(), false, true)
+					records = append(records, r...)
+					if err != nil {
+						break
+					}
+				}
+			}
+			if err != nil {
+				logrus.Warnf("Failed to prune image %s: %v", id, err)
+			}
+			for _, r := range records {
+				if r.Deleted != "" {
+					removed = true
+					if size := layerSizes[layer.ChainID(r.Deleted)]; size > 0 {
+						rep.SpaceReclaimed += uint64(size)
+					}
+				}
+			}
+			rep.ImagesDeleted = append(rep.ImagesDeleted, records...)
+		}
+	}
+	return rep, nil
+}
+
+// NetworksPrune removes the networks that have no endpoint, except the
+// predefined ones. Networks have neither labels nor a creation time, so none
+// of them is removed when the label or until filters are used.
+func (daemon *Daemon) NetworksPrune(pruneFilters filters.Args) (*types.NetworksPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedPruneFilters); err != nil {
+		return nil, err
+	}
+	if _, err := getUntilFromPruneFilters(pruneFilters); err != nil {
+		return nil, err
+	}
+
+	rep := &types.NetworksPruneReport{}
+	if !daemon.NetworkControllerEnabled() || pruneFilters.Include("label") || pruneFilters.Include("until") {
+		return rep, nil
+	}
+
+	for _, nw := range daemon.GetNetworksByID("") {
+		if runconfig.IsPreDefinedNetwork(nw.Name()) || len(nw.Endpoints()) > 0 {
+			continue
+		}
+		if err := daemon.DeleteNetwork(nw.ID()); err != nil {
+			logrus.Warnf("Failed to prune network %s: %v", nw.Name(), err)
+			continue
+		}
+		rep.NetworksDeleted = append(rep.NetworksDeleted, nw.Name())
+	}
+	return rep, nil
+}
+
+// layerDiffSizes returns the size of each layer of the images, by chain ID.
+func (daemon *Daemon) layerDiffSizes() map[layer.ChainID]int64 {
+	sizes := make(map[layer.ChainID]int64)
+	for _, img := range daemon.imageStore.Map() {
+		chainID := img.RootFS.ChainID()
+		if chainID == "" {
+			continue
+		}
+		l, err := daemon.layerStore.Get(chainID)
+		if err != nil {
+			continue
+		}
+		for p := l; p != nil; p = p.Parent() {
+			if _, ok := sizes[p.ChainID()]; ok {
+				break
+			}
+			size, err := p.DiffSize()
+			if err != nil {
+				break
+			}
+			sizes[p.ChainID()] = size
+		}
+		layer.ReleaseAndLog(daemon.layerStore, l)
+	}
+	return sizes
+}
+
+// getUntilFromPruneFilters returns the time of the until filter, or the zero
+// time if there is none. The filter takes the same values as the since and
+// until options of events.
+func getUntilFromPruneFilters(pruneFilters filters.Args) (time.Time, error) {
+	values := pruneFilters.Get("until")
+	if len(values) == 0 {
+		return time.Time{}, nil
+	}
+	if len(values) > 1 {
+		return time.Time{}, fmt.Errorf("more than one until filter specified")
+	}
+	ts, err := timeutils.GetTimestamp(values[0], time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, nanoseconds, err := timeutils.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, nanoseconds), nil
+}
+
+func matchPruneLabels(pruneFilters filters.Args, labels map[string]string) bool {
+	return !pruneFilters.Include("label") || pruneFilters.MatchKVList("label", labels)
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func TestGetUntilFromPruneFilters(t *testing.T) {
+	f := filters.NewArgs()
+	until, err := getUntilFromPruneFilters(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !until.IsZero() {
+		t.Fatalf("expected no until time, got %v", until)
+	}
+
+	f.Add("until", "1455000000")
+	until, err = getUntilFromPruneFilters(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !until.Equal(time.Unix(1455000000, 0)) {
+		t.Fatalf("expected %v, got %v", time.Unix(1455000000, 0), until)
+	}
+
+	f = filters.NewArgs()
+	f.Add("until", "1h")
+	until, err = getUntilFromPruneFilters(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(until); d < time.Hour || d > time.Hour+time.Minute {
+		t.Fatalf("expected an until time an hour ago, got %v", until)
+	}
+
+	f.Add("until", "2h")
+	if _, err := getUntilFromPruneFilters(f); err == nil {
+		t.Fatal("expected an error with more than one until filter")
+	}
+}
+
+func TestMatchPruneLabels(t *testing.T) {
+	labels := map[string]string{"stage": "test", "owner": ""}
+
+	cases := []struct {
+		labels   []string
+		expected bool
+	}{
+		{nil, true},
+		{[]string{"stage"}, true},
+		{[]string{"stage=test"}, true},
+		{[]string{"stage=prod"}, false},
+		{[]string{"missing"}, false},
+		{[]string{"stage=test", "owner"}, true},
+	}
+	for _, c := range cases {
+		f := filters.NewArgs()
+		for _, l := range c.labels {
+			f.Add("label", l)
+		}
+		if actual := matchPruneLabels(f, labels); actual != c.expected {
+			t.Fatalf("expected %v for labels %v, got %v", c.expected, c.labels, actual)
+		}
+	}
+	if !matchPruneLabels(filters.NewArgs(), nil) {
+		t.Fatal("expected objects without labels to match when there is no label filter")
+	}
+}
@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+)
+
+// containerReattach picks up a container whose process kept running while
+// the daemon was restarted. It sets up what containerStart would have set up
+// around the process, then hands it over to a new container monitor.
+func (daemon *Daemon) containerReattach(container *container.Container) (err error) {
+	container.Lock()
+	defer container.Unlock()
+
+	// the monitor cleans up after itself once it got started
+	monitored := false
+	defer func() {
+		if err != nil && !monitored {
+			daemon.Cleanup(container)
+		}
+	}()
+
+	if err := daemon.conditionalMountOnStart(container); err != nil {
+		return err
+	}
+	// The sandbox of the container was removed by libnetwork on startup, a new
+	// one is joined to the container's network namespace by the driver.
+	if err := daemon.initializeNetworking(container); err != nil {
+		return err
+	}
+	if err := daemon.populateCommand(container, container.CreateDaemonEnvironment(nil)); err != nil {
+		return err
+	}
+	mounts, err := daemon.setupMounts(container)
+	if err != nil {
+		return err
+	}
+	mounts = append(mounts, container.IpcMounts()...)
+	mounts = append(mounts, container.TmpfsMounts()...)
+	container.Command.Mounts = mounts
+
+	monitored = true
+	return container.ReattachMonitor(daemon, container.HostConfig.RestartPolicy)
+}
@@ -0,0 +1,213 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/discovery"
+	"github.com/docker/docker/runconfig"
+)
+
+// Reload applies the options of config that can be changed without
+// restarting the daemon:
+//  - the daemon labels
+//  - the debug mode
+//  - the cluster discovery settings
+//  - the registry mirrors and insecure registries
+//  - the default logging driver and its options
+// Only the options set by ReloadConfiguration are considered. A daemon reload
+// event is logged once they are applied, with the new value of each option
+// that changed as attributes. Request handlers read the reloaded options
+// with configLock held, or through getDefaultLogConfig.
+func (daemon *Daemon) Reload(config *Config) error {
+	daemon.reloadLock.Lock()
+	defer daemon.reloadLock.Unlock()
+
+	current := daemon.configStore
+	attributes := map[string]string{}
+
+	logConfig := daemon.defaultLogConfig
+	if config.isReloadable("log-driver") {
+		logConfig.Type = config.LogConfig.Type
+	}
+	if config.isReloadable("log-opt") {
+		logConfig.Config = config.LogConfig.Config
+	}
+	if logConfig.Type != "none" {
+		if _, err := logger.GetLogDriver(logConfig.Type); err != nil {
+			return fmt.Errorf("error finding the logging driver: %v", err)
+		}
+	}
+	if err := logger.ValidateLogOpts(logConfig.Type, logConfig.Config); err != nil {
+		return fmt.Errorf("failed to set log opts: %v", err)
+	}
+
+	discoveryChanged, err := daemon.reloadClusterDiscovery(config)
+	if err != nil {
+		return err
+	}
+	if discoveryChanged {
+		attributes["cluster-store"] = current.ClusterStore
+		attributes["cluster-advertise"] = current.ClusterAdvertise
+		attributes["cluster-store-opt"] = joinMap(current.ClusterOpts)
+	}
+
+	if logConfig.Type != daemon.defaultLogConfig.Type {
+		attributes["log-driver"] = logConfig.Type
+	}
+	if !stringMapsEqual(logConfig.Config, daemon.defaultLogConfig.Config) {
+		attributes["log-opt"] = joinMap(logConfig.Config)
+	}
+
+	labels := current.Labels
+	if config.isReloadable("label") && !stringSlicesEqual(config.Labels, current.Labels) {
+		labels = config.Labels
+		attributes["label"] = strings.Join(config.Labels, ",")
+	}
+
+	debug := current.Debug
+	if config.isReloadable("debug") && config.Debug != current.Debug {
+		debug = config.Debug
+		if config.Debug {
+			os.Setenv("DEBUG", "1")
+			logrus.SetLevel(logrus.DebugLevel)
+		} else {
+			os.Unsetenv("DEBUG")
+			logrus.SetLevel(logrus.InfoLevel)
+		}
+		attributes["debug"] = fmt.Sprint(config.Debug)
+	}
+
+	registryOptions, registryChanged := current.Registry, false
+	if config.isReloadable("registry-mirror") && !stringSlicesEqual(config.Registry.Mirrors.GetAll(), current.Registry.Mirrors.GetAll()) {
+		registryOptions.Mirrors = config.Registry.Mirrors
+		registryChanged = true
+		attributes["registry-mirror"] = strings.Join(registryOptions.Mirrors.GetAll(), ",")
+	}
+	if config.isReloadable("insecure-registry") && !stringSlicesEqual(config.Registry.InsecureRegistries.GetAll(), current.Registry.InsecureRegistries.GetAll()) {
+		registryOptions.InsecureRegistries = config.Registry.InsecureRegistries
+		registryChanged = true
+		attributes["insecure-registry"] = strings.Join(registryOptions.InsecureRegistries.GetAll(), ",")
+	}
+	if registryChanged {
+		daemon.RegistryService.ReloadConfig(&registryOptions)
+	}
+
+	daemon.configLock.Lock()
+	daemon.defaultLogConfig = logConfig
+	current.LogConfig = logConfig
+	current.Labels = labels
+	current.Debug = debug
+	current.Registry = registryOptions
+	daemon.configLock.Unlock()
+
+	logrus.Infof("Reloaded configuration, changed: %v", attributes)
+	daemon.LogDaemonEventWithAttributes("reload", attributes)
+	return nil
+}
+
+// reloadClusterDiscovery restarts the registration of the daemon against the
+// discovery backend when the cluster store, its options or the advertised
+// address changed. It returns whether the settings changed.
+func (daemon *Daemon) reloadClusterDiscovery(config *Config) (bool, error) {
+	current := daemon.configStore
+	store, advertise, clusterOpts := current.ClusterStore, current.ClusterAdvertise, current.ClusterOpts
+	if config.isReloadable("cluster-store") {
+		store = config.ClusterStore
+	}
+	if config.isReloadable("cluster-advertise") {
+		advertise = config.ClusterAdvertise
+	}
+	if config.isReloadable("cluster-store-opt") {
+		clusterOpts = config.ClusterOpts
+	}
+
+	if store == "" && advertise != "" {
+		return false, fmt.Errorf("invalid cluster configuration. --cluster-advertise must be accompanied by --cluster-store configuration")
+	}
+	if store != "" && advertise != "" {
+		var err error
+		advertise, err = discovery.ParseAdvertise(store, advertise)
+		if err != nil {
+			return false, fmt.Errorf("discovery advertise parsing failed (%v)", err)
+		}
+	}
+
+	if store == current.ClusterStore && advertise == current.ClusterAdvertise && stringMapsEqual(clusterOpts, current.ClusterOpts) {
+		return false, nil
+	}
+
+	var (
+		stop    chan struct{}
+		watcher discovery.Watcher
+	)
+	if store != "" && advertise != "" {
+		stop = make(chan struct{})
+		backend, err := initDiscovery(store, advertise, clusterOpts, stop)
+		if err != nil {
+			return false, fmt.Errorf("discovery initialization failed (%v)", err)
+		}
+		watcher = backend
+	}
+	if daemon.discoveryStop != nil {
+		close(daemon.discoveryStop)
+	}
+	if store != current.ClusterStore {
+		logrus.Warnf("The cluster store changed to %q, multi-host networking keeps using the previous store until the daemon restarts", store)
+	}
+
+	daemon.discoveryStop = stop
+	daemon.discoveryWatcher = watcher
+	daemon.configLock.Lock()
+	current.ClusterStore = store
+	current.ClusterAdvertise = advertise
+	current.ClusterOpts = clusterOpts
+	daemon.configLock.Unlock()
+	return true, nil
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// joinMap formats m as a sorted list of comma separated key=value pairs.
+func joinMap(m map[string]string) string {
+	var pairs []string
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func stringMapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// getDefaultLogConfig returns the logging configuration of the containers
+// that don't set one, which is changed when the daemon is reloaded.
+func (daemon *Daemon) getDefaultLogConfig() runconfig.LogConfig {
+	daemon.configLock.RLock()
+	defer daemon.configLock.RUnlock()
+	return daemon.defaultLogConfig
+}
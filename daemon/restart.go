@@ -38,7 +38,7 @@ func (daemon *Daemon) containerRestart(container *container.Container, seconds i
 		return err
 	}
 
-	if err := daemon.containerStart(container); err != nil {
+	if err := daemon.containerStart(container, ""); err != nil {
 		return err
 	}
 
@@ -0,0 +1,6 @@
+// +build linux,cgo,seccomp
+
+package daemon
+
+// supportsSeccomp is true when the daemon was built with seccomp support.
+const supportsSeccomp = true
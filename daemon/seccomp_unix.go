@@ -0,0 +1,29 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+)
+
+// verifySeccompProfile checks that a seccomp profile passed through
+// --security-opt can be used by this daemon.
+func verifySeccompProfile(securityOpt []string) error {
+	for _, opt := range securityOpt {
+		con := splitSecurityOpt(opt)
+		if len(con) != 2 || con[0] != "seccomp" || con[1] == "unconfined" {
+			continue
+		}
+		if !supportsSeccomp {
+			return fmt.Errorf("Seccomp is not supported by this daemon, you cannot specify a custom seccomp profile")
+		}
+		var profile types.Seccomp
+		if err := json.Unmarshal([]byte(con[1]), &profile); err != nil {
+			return fmt.Errorf("Decoding seccomp profile failed: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+// +build !linux !cgo !seccomp
+
+package daemon
+
+const supportsSeccomp = false
@@ -9,8 +9,9 @@ import (
 	"github.com/docker/docker/runconfig"
 )
 
-// ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConfig) error {
+// ContainerStart starts a container. If checkpoint is not empty the
+// container's process is restored from that checkpoint.
+func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConfig, checkpoint string) error {
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
@@ -56,7 +57,14 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConf
 		return err
 	}
 
-	if err := daemon.containerStart(container); err != nil {
+	var checkpointDir string
+	if checkpoint != "" {
+		if checkpointDir, err = existingCheckpointPath(container, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	if err := daemon.containerStart(container, checkpointDir); err != nil {
 		return err
 	}
 
@@ -65,14 +73,15 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConf
 
 // Start starts a container
 func (daemon *Daemon) Start(container *container.Container) error {
-	return daemon.containerStart(container)
+	return daemon.containerStart(container, "")
 }
 
 // containerStart prepares the container to run by setting up everything the
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
-// begin running.
-func (daemon *Daemon) containerStart(container *container.Container) (err error) {
+// begin running. If checkpointDir is not empty the container's process is
+// restored from the checkpoint stored there.
+func (daemon *Daemon) containerStart(container *container.Container, checkpointDir string) (err error) {
 	container.Lock()
 	defer container.Unlock()
 
@@ -136,15 +145,15 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 	mounts = append(mounts, container.TmpfsMounts()...)
 
 	container.Command.Mounts = mounts
-	if err := daemon.waitForStart(container); err != nil {
+	if err := daemon.waitForStart(container, checkpointDir); err != nil {
 		return err
 	}
 	container.HasBeenStartedBefore = true
 	return nil
 }
 
-func (daemon *Daemon) waitForStart(container *container.Container) error {
-	return container.StartMonitor(daemon, container.HostConfig.RestartPolicy)
+func (daemon *Daemon) waitForStart(container *container.Container, checkpointDir string) error {
+	return container.StartMonitor(daemon, container.HostConfig.RestartPolicy, checkpointDir)
 }
 
 // Cleanup releases any network resources allocated to the container along with any rules
@@ -39,6 +39,7 @@ func (daemon *Daemon) containerUnpause(container *container.Container) error {
 	}
 
 	container.Paused = false
+	daemon.updateHealthMonitor(container)
 	daemon.LogContainerEvent(container, "unpause")
 	return nil
 }
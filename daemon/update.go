@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+)
+
+// ContainerUpdate updates resources of the container
+func (daemon *Daemon) ContainerUpdate(name string, hostConfig *runconfig.HostConfig) ([]string, error) {
+	warnings, err := daemon.verifyContainerSettings(hostConfig, nil)
+	if err != nil {
+		return warnings, err
+	}
+
+	if err := daemon.update(name, hostConfig); err != nil {
+		return warnings, err
+	}
+
+	return warnings, nil
+}
+
+func (daemon *Daemon) update(name string, hostConfig *runconfig.HostConfig) error {
+	if hostConfig == nil {
+		return nil
+	}
+
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	if container.RemovalInProgress || container.Dead {
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, "Container is marked for removal and cannot be updated.")
+	}
+
+	if err := checkUpdatableResources(hostConfig.Resources); err != nil {
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, err.Error())
+	}
+
+	if container.Running && hostConfig.KernelMemory != 0 {
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, "Can not update kernel memory to a running container, please stop it first.")
+	}
+
+	// The previous resources are restored if the running container can't
+	// be updated, so that the configuration matches the real world.
+	previous := container.HostConfig.Resources
+	container.UpdateContainer(hostConfig)
+
+	// If container is not running, update hostConfig struct is enough,
+	// resources will be updated when the container is started again.
+	// If container is running (including paused), we need to update
+	// the command so we can update configs to the real world.
+	if container.Running {
+		if err := daemon.updateRunningContainer(container); err != nil {
+			container.HostConfig.Resources = previous
+			if err := updateCommandResources(container); err != nil {
+				logrus.Errorf("Error restoring the resources of container %s: %v", container.ID, err)
+			}
+			return derr.ErrorCodeCantUpdate.WithArgs(container.ID, err.Error())
+		}
+	}
+
+	if err := container.ToDisk(); err != nil {
+		logrus.Errorf("Error saving updated container: %v", err)
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, err.Error())
+	}
+
+	daemon.LogContainerEvent(container, "update")
+
+	return nil
+}
+
+// updateRunningContainer applies the resources of the host config of a
+// running container to its process.
+func (daemon *Daemon) updateRunningContainer(container *container.Container) error {
+	if err := updateCommandResources(container); err != nil {
+		return err
+	}
+	return daemon.execDriver.Update(container.Command)
+}
+
+// checkUpdatableResources returns an error if resources contains settings
+// that can only be chosen when the container is created.
+func checkUpdatableResources(resources runconfig.Resources) error {
+	switch {
+	case resources.CgroupParent != "":
+		return fmt.Errorf("Cgroup parent cannot be updated")
+	case len(resources.Devices) > 0:
+		return fmt.Errorf("Devices cannot be updated")
+	case len(resources.Ulimits) > 0:
+		return fmt.Errorf("Ulimits cannot be updated")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
+)
+
+// updateTestDriver is an execution driver whose Update returns err.
+type updateTestDriver struct {
+	execdriver.Driver
+	updated *execdriver.Command
+	err     error
+}
+
+func (d *updateTestDriver) Update(c *execdriver.Command) error {
+	d.updated = c
+	return d.err
+}
+
+func newUpdateTestDaemon(t *testing.T, running bool, driver execdriver.Driver) (*Daemon, *container.Container) {
+	c := newTestContainer(t, "update-test")
+	c.HostConfig.CPUShares = 512
+	c.Command = &execdriver.Command{Resources: &execdriver.Resources{}}
+	if running {
+		c.SetRunning(42)
+	}
+	daemon := &Daemon{
+		containers:    &contStore{s: map[string]*container.Container{c.ID: c}},
+		execDriver:    driver,
+		EventsService: events.New(),
+	}
+	return daemon, c
+}
+
+func readSavedHostConfig(t *testing.T, c *container.Container) *runconfig.HostConfig {
+	b, err := ioutil.ReadFile(filepath.Join(c.Root, "hostconfig.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hostConfig runconfig.HostConfig
+	if err := json.Unmarshal(b, &hostConfig); err != nil {
+		t.Fatal(err)
+	}
+	return &hostConfig
+}
+
+func TestUpdateStoppedContainer(t *testing.T) {
+	daemon, c := newUpdateTestDaemon(t, false, nil)
+	defer os.RemoveAll(c.Root)
+
+	if err := daemon.update(c.ID, &runconfig.HostConfig{Resources: runconfig.Resources{CPUShares: 1024}}); err != nil {
+		t.Fatal(err)
+	}
+	if c.HostConfig.CPUShares != 1024 {
+		t.Fatalf("Expected the CPU shares to be updated, got %d", c.HostConfig.CPUShares)
+	}
+	if saved := readSavedHostConfig(t, c); saved.CPUShares != 1024 {
+		t.Fatalf("Expected the CPU shares to be saved, got %d", saved.CPUShares)
+	}
+}
+
+func TestUpdateRunningContainer(t *testing.T) {
+	driver := &updateTestDriver{}
+	daemon, c := newUpdateTestDaemon(t, true, driver)
+	defer os.RemoveAll(c.Root)
+
+	if err := daemon.update(c.ID, &runconfig.HostConfig{Resources: runconfig.Resources{CPUShares: 1024}}); err != nil {
+		t.Fatal(err)
+	}
+	if driver.updated == nil || driver.updated.Resources.CPUShares != 1024 {
+		t.Fatalf("Expected the driver to update the CPU shares, got %+v", driver.updated)
+	}
+	if saved := readSavedHostConfig(t, c); saved.CPUShares != 1024 {
+		t.Fatalf("Expected the CPU shares to be saved, got %d", saved.CPUShares)
+	}
+}
+
+func TestUpdateRunningContainerDriverError(t *testing.T) {
+	driver := &updateTestDriver{err: errors.New("cgroup error")}
+	daemon, c := newUpdateTestDaemon(t, true, driver)
+	defer os.RemoveAll(c.Root)
+
+	if err := daemon.update(c.ID, &runconfig.HostConfig{Resources: runconfig.Resources{CPUShares: 1024}}); err == nil {
+		t.Fatal("Expected the update to fail")
+	}
+	if c.HostConfig.CPUShares != 512 {
+		t.Fatalf("Expected the CPU shares to be rolled back, got %d", c.HostConfig.CPUShares)
+	}
+	if c.Command.Resources.CPUShares != 512 {
+		t.Fatalf("Expected the CPU shares of the command to be rolled back, got %d", c.Command.Resources.CPUShares)
+	}
+	if _, err := os.Stat(filepath.Join(c.Root, "hostconfig.json")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the host config not to be saved, got %v", err)
+	}
+}
+
+func TestUpdateRejectsCreateOnlyResources(t *testing.T) {
+	daemon, c := newUpdateTestDaemon(t, false, nil)
+	defer os.RemoveAll(c.Root)
+
+	if err := daemon.update(c.ID, &runconfig.HostConfig{Resources: runconfig.Resources{CgroupParent: "/foo"}}); err == nil {
+		t.Fatal("Expected updating the cgroup parent to fail")
+	}
+}
@@ -0,0 +1,33 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+)
+
+// updateCommandResources copies the resources stored in the container's
+// HostConfig to the execdriver command of a running container.
+func updateCommandResources(c *container.Container) error {
+	weightDevices, err := getBlkioWeightDevices(c.HostConfig)
+	if err != nil {
+		return err
+	}
+
+	resources := c.Command.Resources
+	resources.Memory = c.HostConfig.Memory
+	resources.MemoryReservation = c.HostConfig.MemoryReservation
+	resources.CPUShares = c.HostConfig.CPUShares
+	resources.BlkioWeight = c.HostConfig.BlkioWeight
+	resources.BlkioWeightDevice = weightDevices
+	resources.MemorySwap = c.HostConfig.MemorySwap
+	resources.KernelMemory = c.HostConfig.KernelMemory
+	resources.CpusetCpus = c.HostConfig.CpusetCpus
+	resources.CpusetMems = c.HostConfig.CpusetMems
+	resources.CPUPeriod = c.HostConfig.CPUPeriod
+	resources.CPUQuota = c.HostConfig.CPUQuota
+	if c.HostConfig.MemorySwappiness != nil {
+		resources.MemorySwappiness = *c.HostConfig.MemorySwappiness
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// +build windows
+
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+)
+
+// updateCommandResources copies the resources stored in the container's
+// HostConfig to the execdriver command of a running container.
+func updateCommandResources(c *container.Container) error {
+	c.Command.Resources.CPUShares = c.HostConfig.CPUShares
+	return nil
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/cliconfig"
-	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
@@ -31,8 +30,8 @@ type ImagePullConfig struct {
 	// RegistryService is the registry service to use for TLS configuration
 	// and endpoint lookup.
 	RegistryService *registry.Service
-	// EventsService is the events service to use for logging.
-	EventsService *events.Events
+	// ImageEventLogger notifies events for a given image
+	ImageEventLogger func(id, name, action string)
 	// MetadataStore is the storage backend for distribution-specific
 	// metadata.
 	MetadataStore metadata.Store
@@ -147,7 +146,7 @@ func Pull(ref reference.Named, imagePullConfig *ImagePullConfig) error {
 			}
 		}
 
-		imagePullConfig.EventsService.Log("pull", logName.String(), "")
+		imagePullConfig.ImageEventLogger(logName.String(), logName.String(), "pull")
 		return nil
 	}
 
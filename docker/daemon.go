@@ -49,14 +49,12 @@ func NewDaemonCli() *DaemonCli {
 	daemonConfig.ClusterOpts = make(map[string]string)
 	daemonConfig.InstallFlags(daemonFlags, presentInHelp)
 	daemonConfig.InstallFlags(flag.CommandLine, absentFromHelp)
-	registryOptions := new(registry.Options)
-	registryOptions.InstallFlags(daemonFlags, presentInHelp)
-	registryOptions.InstallFlags(flag.CommandLine, absentFromHelp)
+	daemonConfig.Registry.InstallFlags(daemonFlags, presentInHelp)
+	daemonConfig.Registry.InstallFlags(flag.CommandLine, absentFromHelp)
 	daemonFlags.Require(flag.Exact, 0)
 
 	return &DaemonCli{
-		Config:          daemonConfig,
-		registryOptions: registryOptions,
+		Config: daemonConfig,
 	}
 }
 
@@ -104,7 +102,6 @@ func migrateKey() (err error) {
 // DaemonCli represents the daemon CLI.
 type DaemonCli struct {
 	*daemon.Config
-	registryOptions *registry.Options
 }
 
 func getGlobalFlag() (globalFlag *flag.Flag) {
@@ -140,7 +137,12 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	daemonFlags.ParseFlags(args, true)
+	if err := daemon.MergeConfigurationFile(cli.ConfigFile, daemonFlags); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to configure the Docker daemon with file %s: %v\n", cli.ConfigFile, err)
+		os.Exit(1)
+	}
 	commonFlags.PostParse()
+	cli.Config.Debug = commonFlags.Debug
 
 	if commonFlags.TrustKey == "" {
 		commonFlags.TrustKey = filepath.Join(getDaemonConfDir(), defaultTrustKeyFile)
@@ -177,8 +179,9 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	serverConfig := &apiserver.Config{
-		Logging: true,
-		Version: dockerversion.Version,
+		AuthZPluginNames: cli.Config.AuthZPlugins,
+		Logging:          true,
+		Version:          dockerversion.Version,
 	}
 	serverConfig = setPlatformServerConfig(serverConfig, cli.Config)
 
@@ -222,7 +225,7 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 	cli.TrustKeyPath = commonFlags.TrustKey
 
-	registryService := registry.NewService(cli.registryOptions)
+	registryService := registry.NewService(&cli.Config.Registry)
 	d, err := daemon.NewDaemon(cli.Config, registryService)
 	if err != nil {
 		if pfile != nil {
@@ -244,6 +247,13 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 
 	api.InitRouters(d)
 
+	reload := func(config *daemon.Config) {
+		if err := d.Reload(config); err != nil {
+			logrus.Errorf("Error reconfiguring the daemon: %v", err)
+		}
+	}
+	setupConfigReloadTrap(cli.ConfigFile, daemonFlags, reload)
+
 	// The serve API routine never exits unless an error occurs
 	// We need to start it as a goroutine and wait on it so
 	// daemon doesn't exit
@@ -5,10 +5,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"syscall"
 
+	"github.com/Sirupsen/logrus"
 	apiserver "github.com/docker/docker/api/server"
 	"github.com/docker/docker/daemon"
+	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/system"
 
 	_ "github.com/docker/docker/daemon/execdriver/native"
@@ -48,3 +51,18 @@ func setDefaultUmask() error {
 func getDaemonConfDir() string {
 	return "/etc/docker"
 }
+
+// setupConfigReloadTrap reloads the daemon configuration file each time the
+// daemon receives a SIGHUP.
+func setupConfigReloadTrap(configFile string, flags *flag.FlagSet, reload func(*daemon.Config)) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			logrus.Infof("Got signal to reload configuration, reloading from: %s", configFile)
+			if err := daemon.ReloadConfiguration(configFile, flags, reload); err != nil {
+				logrus.Error(err)
+			}
+		}
+	}()
+}
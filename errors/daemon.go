@@ -939,4 +939,49 @@ var (
 		Description:    "There was an error while trying to start a container",
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
+
+	// ErrorCodeCantUpdate is generated when resources of a container can't
+	// be updated
+	ErrorCodeCantUpdate = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTUPDATE",
+		Message:        "Cannot update container %s: %s",
+		Description:    "There was an error while trying to update a container",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeCheckpointName is generated when the name of a checkpoint
+	// is invalid.
+	ErrorCodeCheckpointName = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CHECKPOINTNAME",
+		Message:        "Invalid checkpoint name (%s), only %s are allowed",
+		Description:    "The name of checkpoint is invalid",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeCheckpointExists is generated when a checkpoint with the
+	// same name already exists for the container.
+	ErrorCodeCheckpointExists = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CHECKPOINTEXISTS",
+		Message:        "Checkpoint %s already exists for container %s",
+		Description:    "A checkpoint with the same name already exists for the container",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeNoSuchCheckpoint is generated when the requested checkpoint
+	// does not exist.
+	ErrorCodeNoSuchCheckpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOSUCHCHECKPOINT",
+		Message:        "No such checkpoint: %s",
+		Description:    "The specified checkpoint does not exist",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeCantCheckpoint is generated when a container can't be
+	// checkpointed.
+	ErrorCodeCantCheckpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTCHECKPOINT",
+		Message:        "Cannot checkpoint container %s: %s",
+		Description:    "There was an error while trying to checkpoint a container",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
 )
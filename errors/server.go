@@ -33,4 +33,22 @@ var (
 		Description:    "Docker's networking stack is disabled for this platform",
 		HTTPStatusCode: http.StatusNotFound,
 	})
+
+	// ErrorCodeAuthorizationDenied is generated when an authorization plugin
+	// denies a request or its response.
+	ErrorCodeAuthorizationDenied = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "AUTHORIZATIONDENIED",
+		Message:        "%s",
+		Description:    "An authorization plugin denied the request",
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
+	// ErrorCodeAuthorizationPlugin is generated when an authorization plugin
+	// could not be reached or failed to process a request.
+	ErrorCodeAuthorizationPlugin = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "AUTHORIZATIONPLUGIN",
+		Message:        "%s",
+		Description:    "An authorization plugin failed to process the request",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
 )
@@ -109,6 +109,9 @@ if [ -z "$DOCKER_CLIENTONLY" ]; then
 	if pkg-config libsystemd-journal 2> /dev/null ; then
 		DOCKER_BUILDTAGS+=" journald"
 	fi
+	if pkg-config --atleast-version=2.2.1 libseccomp 2> /dev/null ; then
+		DOCKER_BUILDTAGS+=" seccomp"
+	fi
 fi
 
 # test whether "btrfs/version.h" exists and apply btrfs_noversion appropriately
@@ -174,11 +174,12 @@ func (s *DockerSuite) TestHelpTextVerify(c *check.C) {
 
 			// These commands will never print a short-usage so don't test
 			noShortUsage := map[string]string{
-				"images":  "",
-				"login":   "",
-				"logout":  "",
-				"network": "",
-				"stats":   "",
+				"checkpoint": "",
+				"images":     "",
+				"login":      "",
+				"logout":     "",
+				"network":    "",
+				"stats":      "",
 			}
 
 			if _, ok := noShortUsage[cmd]; !ok {
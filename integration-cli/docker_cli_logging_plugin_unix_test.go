@@ -0,0 +1,177 @@
+// +build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/docker/pkg/plugins/logdriver"
+	"github.com/go-check/check"
+)
+
+func init() {
+	check.Suite(&DockerLoggingPluginSuite{
+		ds: &DockerSuite{},
+	})
+}
+
+// DockerLoggingPluginSuite runs a logging driver plugin keeping the logs of
+// the containers in memory.
+type DockerLoggingPluginSuite struct {
+	server *httptest.Server
+	ds     *DockerSuite
+	d      *Daemon
+
+	mu      sync.Mutex
+	entries map[string][]logdriver.Entry
+	done    map[string]chan struct{}
+}
+
+func (s *DockerLoggingPluginSuite) SetUpTest(c *check.C) {
+	s.d = NewDaemon(c)
+	s.entries = make(map[string][]logdriver.Entry)
+	s.done = make(map[string]chan struct{})
+}
+
+func (s *DockerLoggingPluginSuite) TearDownTest(c *check.C) {
+	s.d.Stop()
+	s.ds.TearDownTest(c)
+}
+
+func (s *DockerLoggingPluginSuite) SetUpSuite(c *check.C) {
+	mux := http.NewServeMux()
+	s.server = httptest.NewServer(mux)
+
+	type pluginRequest struct {
+		File string
+		Info struct {
+			ContainerID string
+		}
+	}
+
+	read := func(r *http.Request) (pluginRequest, error) {
+		defer r.Body.Close()
+		var pr pluginRequest
+		err := json.NewDecoder(r.Body).Decode(&pr)
+		return pr, err
+	}
+
+	send := func(w http.ResponseWriter, data interface{}) {
+		if err, ok := data.(error); ok {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, data)
+	}
+
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		send(w, `{"Implements": ["LogDriver"]}`)
+	})
+
+	mux.HandleFunc("/LogDriver.StartLogging", func(w http.ResponseWriter, r *http.Request) {
+		pr, err := read(r)
+		if err != nil {
+			send(w, err)
+			return
+		}
+		f, err := os.Open(pr.File)
+		if err != nil {
+			send(w, fmt.Sprintf(`{"Err": %q}`, err))
+			return
+		}
+
+		id := pr.Info.ContainerID
+		done := make(chan struct{})
+		s.mu.Lock()
+		s.done[id] = done
+		s.mu.Unlock()
+		go func() {
+			defer close(done)
+			defer f.Close()
+			dec := logdriver.NewEntryDecoder(f)
+			for {
+				var entry logdriver.Entry
+				if err := dec.Decode(&entry); err != nil {
+					return
+				}
+				s.mu.Lock()
+				s.entries[id] = append(s.entries[id], entry)
+				s.mu.Unlock()
+			}
+		}()
+		send(w, `{}`)
+	})
+
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		send(w, `{}`)
+	})
+
+	mux.HandleFunc("/LogDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		send(w, `{"Cap": {"ReadLogs": true}}`)
+	})
+
+	mux.HandleFunc("/LogDriver.ReadLogs", func(w http.ResponseWriter, r *http.Request) {
+		pr, err := read(r)
+		if err != nil {
+			send(w, err)
+			return
+		}
+
+		// Only the logs of stopped containers are read, wait for all of
+		// them to be received.
+		s.mu.Lock()
+		done := s.done[pr.Info.ContainerID]
+		s.mu.Unlock()
+		if done != nil {
+			<-done
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		enc := logdriver.NewEntryEncoder(w)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, entry := range s.entries[pr.Info.ContainerID] {
+			enc.Encode(&entry)
+		}
+	})
+
+	err := os.MkdirAll("/etc/docker/plugins", 0755)
+	c.Assert(err, checker.IsNil)
+
+	err = ioutil.WriteFile("/etc/docker/plugins/test-logging-driver.spec", []byte(s.server.URL), 0644)
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *DockerLoggingPluginSuite) TearDownSuite(c *check.C) {
+	s.server.Close()
+
+	err := os.Remove("/etc/docker/plugins/test-logging-driver.spec")
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *DockerLoggingPluginSuite) TestLoggingPluginReadLogs(c *check.C) {
+	testRequires(c, SameHostDaemon)
+	c.Assert(s.d.StartWithBusybox(), checker.IsNil)
+
+	// The cache is disabled, so that the logs can only be read from the
+	// plugin.
+	out, err := s.d.Cmd("run", "-d", "--log-driver=test-logging-driver", "--log-opt", "cache-disabled=true", "busybox", "sh", "-c", "echo line1; echo line2")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	id := strings.TrimSpace(out)
+
+	out, err = s.d.Cmd("wait", id)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+
+	out, err = s.d.Cmd("logs", id)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Equals, "line1\nline2\n")
+}
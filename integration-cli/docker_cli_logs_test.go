@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -351,3 +352,29 @@ func (s *DockerSuite) TestLogsCLIContainerNotFound(c *check.C) {
 	message := fmt.Sprintf(".*no such id: %s.*\n", name)
 	c.Assert(out, checker.Matches, message)
 }
+
+func (s *DockerSuite) TestLogsSyslogDriver(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon)
+	// The logs are read back from the cache of the daemon, nothing needs to
+	// read what is sent to syslog.
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	c.Assert(err, checker.IsNil)
+	defer l.Close()
+
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=syslog", "--log-opt", "syslog-address=udp://"+l.LocalAddr().String(), "busybox", "echo", "testline")
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", id)
+	c.Assert(out, checker.Equals, "testline\n")
+}
+
+func (s *DockerSuite) TestLogsLocalDriver(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=local", "busybox", "sh", "-c", "echo line1; echo line2 >&2")
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", id)
+	c.Assert(out, checker.Equals, "line1\nline2\n")
+}
@@ -456,3 +456,43 @@ func (s *DockerSuite) TestRunTmpfsMounts(c *check.C) {
 		c.Fatalf("Should have generated an error saying Duplicate mount  points")
 	}
 }
+
+// seccompMode returns the seccomp mode of a process run in a container with
+// the given options, 0 when disabled and 2 when filtering syscalls.
+func seccompMode(c *check.C, args ...string) string {
+	args = append([]string{"run", "--rm"}, args...)
+	args = append(args, "busybox", "grep", "Seccomp:", "/proc/self/status")
+	out, _ := dockerCmd(c, args...)
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(out), "Seccomp:"))
+}
+
+func (s *DockerSuite) TestRunSeccompDefaultProfile(c *check.C) {
+	testRequires(c, SameHostDaemon, seccompEnabled)
+
+	c.Assert(seccompMode(c), checker.Equals, "2", check.Commentf("the default profile should filter syscalls"))
+	c.Assert(seccompMode(c, "--security-opt", "seccomp=unconfined"), checker.Equals, "0")
+	c.Assert(seccompMode(c, "--privileged"), checker.Equals, "0")
+}
+
+func (s *DockerSuite) TestRunSeccompProfileDenyChmod(c *check.C) {
+	testRequires(c, SameHostDaemon, seccompEnabled)
+
+	profile := `{
+	"defaultAction": "SCMP_ACT_ALLOW",
+	"syscalls": [
+		{"name": "chmod", "action": "SCMP_ACT_ERRNO"},
+		{"name": "fchmod", "action": "SCMP_ACT_ERRNO"},
+		{"name": "fchmodat", "action": "SCMP_ACT_ERRNO"}
+	]
+}`
+	tmpFile, err := ioutil.TempFile("", "profile.json")
+	c.Assert(err, checker.IsNil)
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write([]byte(profile))
+	tmpFile.Close()
+	c.Assert(err, checker.IsNil)
+
+	out, _, err := dockerCmdWithError("run", "--rm", "--security-opt", "seccomp="+tmpFile.Name(), "busybox", "chmod", "400", "/etc/hostname")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Operation not permitted")
+}
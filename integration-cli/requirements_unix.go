@@ -3,6 +3,8 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/docker/docker/pkg/sysinfo"
 )
 
@@ -75,6 +77,25 @@ var (
 		},
 		"Test requires an environment that supports cgroup cpuset.",
 	}
+	seccompEnabled = testRequirement{
+		func() bool {
+			if !SysInfo.Seccomp {
+				return false
+			}
+			// The daemon must also have been built with seccomp support.
+			out, _, err := dockerCmdWithError("info")
+			if err != nil {
+				return false
+			}
+			for _, line := range strings.Split(out, "\n") {
+				if strings.HasPrefix(line, "Security Options:") {
+					return strings.Contains(line+" ", " seccomp ")
+				}
+			}
+			return false
+		},
+		"Test requires an environment that supports seccomp and a daemon built with it.",
+	}
 )
 
 func init() {
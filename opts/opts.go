@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ import (
 
 var (
 	alphaRegexp  = regexp.MustCompile(`[a-zA-Z]`)
+	secretRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
 	domainRegexp = regexp.MustCompile(`^(:?(:?[a-zA-Z0-9]|(:?[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9]))(:?\.(:?[a-zA-Z0-9]|(:?[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])))*)\.?\s*$`)
 	// DefaultHTTPPort Default HTTP Port used if only the protocol is provided to -H flag e.g. docker daemon -H tcp://
 	// TODO Windows. DefaultHTTPPort is only used on Windows if a -H parameter
@@ -364,6 +366,54 @@ func ValidateLabel(val string) (string, error) {
 	return val, nil
 }
 
+// ValidateSecret validates that the specified string is a valid build secret,
+// and returns it. Build secrets are in the form of id=<id>,src=<path>.
+func ValidateSecret(val string) (string, error) {
+	if _, _, err := ParseSecret(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+// ParseSecret parses a build secret in the form of id=<id>,src=<path>, and
+// returns the ID of the secret and the path of the file holding it.
+func ParseSecret(val string) (string, string, error) {
+	var id, src string
+	for _, field := range strings.Split(val, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return "", "", fmt.Errorf("bad format for secret: %q", val)
+		}
+		switch kv[0] {
+		case "id":
+			id = kv[1]
+		case "src", "source":
+			src = kv[1]
+		default:
+			return "", "", fmt.Errorf("unknown field %q in secret: %q", kv[0], val)
+		}
+	}
+	if src == "" {
+		return "", "", fmt.Errorf("missing source file in secret: %q", val)
+	}
+	if id == "" {
+		id = filepath.Base(src)
+	}
+	if err := ValidateSecretID(id); err != nil {
+		return "", "", err
+	}
+	return id, src, nil
+}
+
+// ValidateSecretID validates that the specified string can be used as the ID
+// of a build secret, which is also the name of its file in the containers.
+func ValidateSecretID(id string) error {
+	if !secretRegexp.MatchString(id) {
+		return fmt.Errorf("invalid secret ID %q, only [a-zA-Z0-9_.-] are allowed and it must start with a letter or a digit", id)
+	}
+	return nil
+}
+
 // ValidateHost validates that the specified string is a valid host and returns it.
 func ValidateHost(val string) (string, error) {
 	_, err := parsers.ParseDockerDaemonHost(DefaultTCPHost, DefaultTLSHost, DefaultUnixSocket, "", val)
@@ -372,6 +372,40 @@ func TestValidateLabel(t *testing.T) {
 	}
 }
 
+func TestParseSecret(t *testing.T) {
+	valid := map[string][2]string{
+		"id=mysecret,src=/run/key":    {"mysecret", "/run/key"},
+		"src=/run/key,id=mysecret":    {"mysecret", "/run/key"},
+		"id=my.secret_1,source=./key": {"my.secret_1", "./key"},
+		"src=/home/user/.aws/creds":   {"creds", "/home/user/.aws/creds"},
+	}
+	for val, expected := range valid {
+		id, src, err := ParseSecret(val)
+		if err != nil {
+			t.Fatalf("Expected %q to be a valid secret, got %v", val, err)
+		}
+		if id != expected[0] || src != expected[1] {
+			t.Fatalf("Expected [%s,%s] for %q, got [%s,%s]", expected[0], expected[1], val, id, src)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"mysecret",
+		"id=mysecret",
+		"id=mysecret,src=",
+		"id=my/secret,src=/run/key",
+		"id=..,src=/run/key",
+		"id=.hidden,src=/run/key",
+		"id=mysecret,src=/run/key,mode=0400",
+	}
+	for _, val := range invalid {
+		if _, err := ValidateSecret(val); err == nil {
+			t.Fatalf("Expected %q to be an invalid secret", val)
+		}
+	}
+}
+
 func TestParseHost(t *testing.T) {
 	invalid := map[string]string{
 		"anything":              "Invalid bind address format: anything",
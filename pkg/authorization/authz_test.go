@@ -0,0 +1,255 @@
+package authorization
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/tlsconfig"
+)
+
+// authZPluginServer is a fake authorization plugin served over HTTP.
+type authZPluginServer struct {
+	server *httptest.Server
+	// recordedRequest is the last request sent to the plugin
+	recordedRequest Request
+	// res is the response the plugin will return
+	res Response
+}
+
+func newAuthZPluginServer(t *testing.T) *authZPluginServer {
+	s := &authZPluginServer{}
+	mux := http.NewServeMux()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(body, &s.recordedRequest); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(s.res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}
+	mux.HandleFunc("/"+AuthZApiRequest, handler)
+	mux.HandleFunc("/"+AuthZApiResponse, handler)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *authZPluginServer) plugin(t *testing.T) Plugin {
+	client, err := plugins.NewClient(s.server.URL, tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &authorizationPlugin{name: "authz-test", plugin: &plugins.Plugin{Name: "authz-test", Client: client}}
+}
+
+func TestAuthZRequestPlugin(t *testing.T) {
+	server := newAuthZPluginServer(t)
+	defer server.server.Close()
+
+	server.res = Response{Allow: true, Msg: "Sample message"}
+	authZPlugin := server.plugin(t)
+
+	request := Request{
+		User:           "user",
+		RequestBody:    []byte("sample body"),
+		RequestMethod:  "POST",
+		RequestURI:     "http://127.0.0.1/v1.21/containers/json",
+		RequestHeaders: map[string]string{"header": "value"},
+	}
+
+	actualResponse, err := authZPlugin.AuthZRequest(&request)
+	if err != nil {
+		t.Fatalf("Failed to authorize request %v", err)
+	}
+
+	if !reflect.DeepEqual(server.res, *actualResponse) {
+		t.Fatalf("Response must be equal")
+	}
+	if !reflect.DeepEqual(request, server.recordedRequest) {
+		t.Fatalf("Requests must be equal")
+	}
+}
+
+func TestAuthZResponsePlugin(t *testing.T) {
+	server := newAuthZPluginServer(t)
+	defer server.server.Close()
+
+	server.res = Response{Allow: true, Msg: "Sample message"}
+	authZPlugin := server.plugin(t)
+
+	request := Request{
+		User:               "user",
+		RequestURI:         "someting.com/auth",
+		RequestBody:        []byte("sample body"),
+		ResponseStatusCode: http.StatusOK,
+	}
+
+	actualResponse, err := authZPlugin.AuthZResponse(&request)
+	if err != nil {
+		t.Fatalf("Failed to authorize request %v", err)
+	}
+
+	if !reflect.DeepEqual(server.res, *actualResponse) {
+		t.Fatalf("Response must be equal")
+	}
+	if !reflect.DeepEqual(request, server.recordedRequest) {
+		t.Fatalf("Requests must be equal")
+	}
+}
+
+func TestAuthZRequestDenied(t *testing.T) {
+	server := newAuthZPluginServer(t)
+	defer server.server.Close()
+
+	server.res = Response{Allow: false, Msg: "no containers for you"}
+	ctx := NewCtx([]Plugin{server.plugin(t)}, "user", "TLS", "GET", "/containers/json")
+
+	r, err := http.NewRequest("GET", "/containers/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.AuthZRequest(httptest.NewRecorder(), r)
+	if err == nil {
+		t.Fatal("Expected request to be denied")
+	}
+	if !IsDenied(err) {
+		t.Fatalf("Expected a deny error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "no containers for you") {
+		t.Fatalf("Expected plugin message in error, got %v", err)
+	}
+	if server.recordedRequest.User != "user" || server.recordedRequest.UserAuthNMethod != "TLS" {
+		t.Fatalf("Expected user identity to be forwarded, got %+v", server.recordedRequest)
+	}
+}
+
+func TestAuthZRequestPluginError(t *testing.T) {
+	server := newAuthZPluginServer(t)
+	defer server.server.Close()
+
+	server.res = Response{Err: "plugin is broken"}
+	ctx := NewCtx([]Plugin{server.plugin(t)}, "", "", "GET", "/info")
+
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.AuthZRequest(httptest.NewRecorder(), r)
+	if err == nil {
+		t.Fatal("Expected request to fail")
+	}
+	if IsDenied(err) {
+		t.Fatalf("Plugin errors must not be reported as denials: %v", err)
+	}
+}
+
+func TestAuthZRequestForwardsJSONBody(t *testing.T) {
+	server := newAuthZPluginServer(t)
+	defer server.server.Close()
+
+	server.res = Response{Allow: true}
+	ctx := NewCtx([]Plugin{server.plugin(t)}, "", "", "POST", "/containers/create")
+
+	body := []byte(`{"Image":"busybox"}`)
+	r, err := http.NewRequest("POST", "/containers/create", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if err := ctx.AuthZRequest(httptest.NewRecorder(), r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(server.recordedRequest.RequestBody, body) {
+		t.Fatalf("Expected body %q, got %q", body, server.recordedRequest.RequestBody)
+	}
+
+	// The handler must still be able to read the body.
+	remaining, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(remaining, body) {
+		t.Fatalf("Expected body %q to still be readable, got %q", body, remaining)
+	}
+}
+
+func TestAuthZRequestSkipsSecretHeaders(t *testing.T) {
+	server := newAuthZPluginServer(t)
+	defer server.server.Close()
+
+	server.res = Response{Allow: true}
+	ctx := NewCtx([]Plugin{server.plugin(t)}, "", "", "POST", "/build")
+
+	r, err := http.NewRequest("POST", "/build", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	r.Header.Set("X-Registry-Config", "e30=")
+	r.Header.Set("X-Build-Secrets", "eyJucG1yYyI6ImMyVmpjbVYwIn0=")
+	r.Header.Set("X-Custom", "value")
+	if err := ctx.AuthZRequest(httptest.NewRecorder(), r); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"X-Custom": "value"}
+	if !reflect.DeepEqual(server.recordedRequest.RequestHeaders, expected) {
+		t.Fatalf("Expected only the headers %v to be sent to the plugin, got %v", expected, server.recordedRequest.RequestHeaders)
+	}
+}
+
+func TestResponseModifier(t *testing.T) {
+	r := httptest.NewRecorder()
+	m := NewResponseModifier(r)
+	m.Header().Set("h1", "v1")
+	m.Write([]byte("body"))
+	m.WriteHeader(http.StatusInternalServerError)
+
+	if r.Body.Len() != 0 || r.Header().Get("h1") != "" {
+		t.Fatal("Response must be buffered until flushed")
+	}
+	if m.StatusCode() != http.StatusInternalServerError {
+		t.Fatalf("Status code must be buffered, got %d", m.StatusCode())
+	}
+	if string(m.RawBody()) != "body" {
+		t.Fatalf("Body must be buffered, got %q", m.RawBody())
+	}
+
+	if err := m.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Header().Get("h1") != "v1" {
+		t.Fatalf("Header value must exists %s", r.Header().Get("h1"))
+	}
+	if r.Code != http.StatusInternalServerError {
+		t.Fatalf("Status code must be correct %d", r.Code)
+	}
+	if r.Body.String() != "body" {
+		t.Fatalf("Body value must exists %s", r.Body.String())
+	}
+}
+
+func TestResponseModifierStreaming(t *testing.T) {
+	r := httptest.NewRecorder()
+	m := NewResponseModifier(r)
+	m.Write([]byte("first"))
+	m.Flush()
+	if !m.Streamed() {
+		t.Fatal("Response must be marked as streamed after Flush")
+	}
+	m.Write([]byte(" second"))
+	if r.Body.String() != "first second" {
+		t.Fatalf("Writes after Flush must pass through, got %q", r.Body.String())
+	}
+}
@@ -0,0 +1,159 @@
+package authorization
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ResponseModifier allows authorization plugins to read and modify the content of the http.response
+type ResponseModifier interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.CloseNotifier
+
+	// RawBody returns the current http content
+	RawBody() []byte
+
+	// RawHeaders returns the current content of the http headers
+	RawHeaders() ([]byte, error)
+
+	// StatusCode returns the current status code
+	StatusCode() int
+
+	// Streamed reports whether the response has already been sent to the
+	// client, either because the handler flushed it or hijacked the connection.
+	Streamed() bool
+
+	// FlushAll flushes all data to the HTTP response
+	FlushAll() error
+}
+
+// NewResponseModifier creates a wrapper to an http.ResponseWriter to allow inspecting and modifying the content
+func NewResponseModifier(rw http.ResponseWriter) ResponseModifier {
+	return &responseModifier{rw: rw, header: make(http.Header)}
+}
+
+// responseModifier is used as an adapter to http.ResponseWriter in order to manipulate and explore
+// the http request/response from docker daemon
+type responseModifier struct {
+	// The original response writer
+	rw http.ResponseWriter
+	// body holds the response body
+	body bytes.Buffer
+	// header holds the response header
+	header http.Header
+	// statusCode holds the response status code
+	statusCode int
+	// streamed is set once data has been passed through to rw
+	streamed bool
+	// hijacked is set once the connection has been taken over by the handler
+	hijacked bool
+}
+
+// WriteHeader stores the http status code
+func (rm *responseModifier) WriteHeader(s int) {
+	if rm.streamed {
+		rm.rw.WriteHeader(s)
+	}
+	rm.statusCode = s
+}
+
+// Header returns the internal http header
+func (rm *responseModifier) Header() http.Header {
+	if rm.streamed {
+		return rm.rw.Header()
+	}
+	return rm.header
+}
+
+// Write stores the byte array inside content
+func (rm *responseModifier) Write(b []byte) (int, error) {
+	if rm.streamed {
+		return rm.rw.Write(b)
+	}
+	return rm.body.Write(b)
+}
+
+// RawBody returns the body buffered so far
+func (rm *responseModifier) RawBody() []byte {
+	return rm.body.Bytes()
+}
+
+// RawHeaders returns the response headers in wire format
+func (rm *responseModifier) RawHeaders() ([]byte, error) {
+	var b bytes.Buffer
+	if err := rm.Header().Write(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// StatusCode returns the http status code, defaulting to 200 when the
+// handler never set one explicitly.
+func (rm *responseModifier) StatusCode() int {
+	if rm.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rm.statusCode
+}
+
+// Streamed reports whether the response has already been sent to the client
+func (rm *responseModifier) Streamed() bool {
+	return rm.streamed || rm.hijacked
+}
+
+// Hijack returns the internal connection of the wrapped http.ResponseWriter
+func (rm *responseModifier) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rm.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Internal response writer doesn't support the Hijacker interface")
+	}
+	rm.hijacked = true
+	return hijacker.Hijack()
+}
+
+// CloseNotify uses the internal close notify API of the wrapped http.ResponseWriter
+func (rm *responseModifier) CloseNotify() <-chan bool {
+	closeNotifier, ok := rm.rw.(http.CloseNotifier)
+	if !ok {
+		// Never fires; the handler keeps running until it completes.
+		return make(chan bool)
+	}
+	return closeNotifier.CloseNotify()
+}
+
+// Flush sends any buffered data to the client and switches the modifier to
+// pass-through mode, so that streaming endpoints keep working.
+func (rm *responseModifier) Flush() {
+	if err := rm.FlushAll(); err != nil {
+		return
+	}
+	if flusher, ok := rm.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// FlushAll writes the buffered headers, status code and body to the
+// wrapped http.ResponseWriter
+func (rm *responseModifier) FlushAll() error {
+	if rm.hijacked || rm.streamed {
+		return nil
+	}
+	rm.streamed = true
+
+	for k, v := range rm.header {
+		for _, val := range v {
+			rm.rw.Header().Add(k, val)
+		}
+	}
+	if rm.statusCode != 0 {
+		rm.rw.WriteHeader(rm.statusCode)
+	}
+	_, err := rm.rw.Write(rm.body.Bytes())
+	rm.body.Reset()
+	return err
+}
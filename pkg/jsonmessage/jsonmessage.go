@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/term"
 	"github.com/docker/docker/pkg/timeutils"
 	"github.com/docker/docker/pkg/units"
@@ -88,20 +89,36 @@ func (p *JSONProgress) String() string {
 	return pbBox + numbersBox + timeLeftBox
 }
 
+// JSONBuildStep describes a step of a build. A record is sent when the step
+// starts, and another one with Done set when it ends.
+type JSONBuildStep struct {
+	Step        int    `json:"step"`                  // index of the step in the Dockerfile, from 1
+	Instruction string `json:"instruction"`           // instruction as written in the Dockerfile
+	Message     string `json:"message,omitempty"`     // instruction as printed by the builder
+	Done        bool   `json:"done,omitempty"`        // whether the step ended
+	Cache       string `json:"cache,omitempty"`       // "hit" or "miss" if the step looked up the build cache
+	ImageID     string `json:"imageId,omitempty"`     // image at the end of the step
+	ContainerID string `json:"containerId,omitempty"` // intermediate container of the step, if any
+	Duration    int64  `json:"duration,omitempty"`    // duration of the step, in nanoseconds
+	ExitCode    *int   `json:"exitCode,omitempty"`    // exit code of the command of a RUN step
+	Error       string `json:"error,omitempty"`       // error the step failed with
+}
+
 // JSONMessage defines a message struct. It describes
 // the created time, where it from, status, ID of the
 // message. It's used for docker events.
 type JSONMessage struct {
-	Stream          string        `json:"stream,omitempty"`
-	Status          string        `json:"status,omitempty"`
-	Progress        *JSONProgress `json:"progressDetail,omitempty"`
-	ProgressMessage string        `json:"progress,omitempty"` //deprecated
-	ID              string        `json:"id,omitempty"`
-	From            string        `json:"from,omitempty"`
-	Time            int64         `json:"time,omitempty"`
-	TimeNano        int64         `json:"timeNano,omitempty"`
-	Error           *JSONError    `json:"errorDetail,omitempty"`
-	ErrorMessage    string        `json:"error,omitempty"` //deprecated
+	Stream          string         `json:"stream,omitempty"`
+	Status          string         `json:"status,omitempty"`
+	Progress        *JSONProgress  `json:"progressDetail,omitempty"`
+	ProgressMessage string         `json:"progress,omitempty"` //deprecated
+	ID              string         `json:"id,omitempty"`
+	From            string         `json:"from,omitempty"`
+	Time            int64          `json:"time,omitempty"`
+	TimeNano        int64          `json:"timeNano,omitempty"`
+	Error           *JSONError     `json:"errorDetail,omitempty"`
+	ErrorMessage    string         `json:"error,omitempty"` //deprecated
+	BuildStep       *JSONBuildStep `json:"buildStep,omitempty"`
 }
 
 // Display displays the JSONMessage to `out`. `isTerminal` describes if `out`
@@ -114,6 +131,10 @@ func (jm *JSONMessage) Display(out io.Writer, isTerminal bool) error {
 		}
 		return jm.Error
 	}
+	if jm.BuildStep != nil {
+		jm.BuildStep.Display(out)
+		return nil
+	}
 	var endl string
 	if isTerminal && jm.Stream == "" && jm.Progress != nil {
 		// <ESC>[2K = erase entire current line
@@ -145,6 +166,17 @@ func (jm *JSONMessage) Display(out io.Writer, isTerminal bool) error {
 	return nil
 }
 
+// Display displays the build step to `out` the way the builder prints it
+// without progress records: the instruction when the step starts, and the
+// resulting image when it ends successfully.
+func (s *JSONBuildStep) Display(out io.Writer) {
+	if !s.Done {
+		fmt.Fprintf(out, "Step %d : %s\n", s.Step, s.Message)
+	} else if s.Error == "" && s.ImageID != "" {
+		fmt.Fprintf(out, " ---> %s\n", stringid.TruncateID(s.ImageID))
+	}
+}
+
 // DisplayJSONMessagesStream displays a json message stream from `in` to `out`, `isTerminal`
 // describes if `out` is a terminal. If this is the case, it will print `\n` at the end of
 // each line and move the cursor while displaying.
@@ -203,6 +203,21 @@ func TestDisplayJSONMessagesStream(t *testing.T) {
 			"", // progressbar is disabled in non-terminal
 			fmt.Sprintf("\n%c[%dA%c[2K\rID: status      1 B\r%c[%dB", 27, 0, 27, 27, 0),
 		},
+		// Start of a build step
+		"{ \"buildStep\": { \"step\": 2, \"instruction\": \"run echo hi\", \"message\": \"RUN echo hi\" } }": {
+			"Step 2 : RUN echo hi\n",
+			"Step 2 : RUN echo hi\n",
+		},
+		// End of a build step
+		"{ \"buildStep\": { \"step\": 2, \"done\": true, \"imageId\": \"sha256:0123456789abcdef\" } }": {
+			" ---> 0123456789ab\n",
+			" ---> 0123456789ab\n",
+		},
+		// End of a failed build step
+		"{ \"buildStep\": { \"step\": 2, \"done\": true, \"error\": \"failed\" } }": {
+			"",
+			"",
+		},
 	}
 	for jsonMessage, expectedMessages := range messages {
 		data := bytes.NewBuffer([]byte{})
@@ -0,0 +1,65 @@
+// Package logdriver defines the messages exchanged with the logging driver
+// plugins.
+//
+// The daemon creates a FIFO for each container, and streams the log messages
+// of the container to the plugin through it, as Entry records encoded in
+// JSON, one per line. When the plugin can read the logs back, it streams the
+// same records in the response of /LogDriver.ReadLogs.
+package logdriver
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Type is the subsystem implemented by the logging driver plugins, as listed
+// in their manifest.
+const Type = "LogDriver"
+
+// Entry is a log message of a container.
+type Entry struct {
+	// Source is the stream the message was written to, "stdout" or "stderr".
+	Source string
+	// TimeNano is the time of the message, in nanoseconds since the epoch.
+	TimeNano int64
+	// Line is the message, without the trailing newline.
+	Line []byte
+}
+
+// Encoder writes entries to a stream.
+type Encoder interface {
+	Encode(*Entry) error
+}
+
+// Decoder reads entries from a stream.
+type Decoder interface {
+	Decode(*Entry) error
+}
+
+type entryEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *entryEncoder) Encode(entry *Entry) error {
+	return e.enc.Encode(entry)
+}
+
+type entryDecoder struct {
+	dec *json.Decoder
+}
+
+func (d *entryDecoder) Decode(entry *Entry) error {
+	*entry = Entry{}
+	return d.dec.Decode(entry)
+}
+
+// NewEntryEncoder returns an Encoder writing the entries to w.
+func NewEntryEncoder(w io.Writer) Encoder {
+	return &entryEncoder{json.NewEncoder(w)}
+}
+
+// NewEntryDecoder returns a Decoder reading the entries from r. Decode
+// returns io.EOF at the end of the stream.
+func NewEntryDecoder(r io.Reader) Decoder {
+	return &entryDecoder{json.NewDecoder(r)}
+}
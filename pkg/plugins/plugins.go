@@ -42,8 +42,9 @@ type plugins struct {
 }
 
 var (
-	storage          = plugins{plugins: make(map[string]*Plugin)}
-	extpointHandlers = make(map[string]func(string, *Client))
+	storage            = plugins{plugins: make(map[string]*Plugin)}
+	extpointHandlers   = make(map[string]func(string, *Client))
+	activationHandlers []func(string, *Manifest)
 )
 
 // Manifest lists what a plugin implements.
@@ -106,11 +107,11 @@ func (p *Plugin) activateWithLock() error {
 		}
 		handler(p.Name, p.Client)
 	}
-	return nil
-}
 
-func load(name string) (*Plugin, error) {
-	return loadWithRetry(name, true)
+	for _, handler := range activationHandlers {
+		handler(p.Name, m)
+	}
+	return nil
 }
 
 func loadWithRetry(name string, retry bool) (*Plugin, error) {
@@ -151,19 +152,30 @@ func loadWithRetry(name string, retry bool) (*Plugin, error) {
 	}
 }
 
-func get(name string) (*Plugin, error) {
+func get(name string, retry bool) (*Plugin, error) {
 	storage.Lock()
 	pl, ok := storage.plugins[name]
 	storage.Unlock()
 	if ok {
 		return pl, pl.activate()
 	}
-	return load(name)
+	return loadWithRetry(name, retry)
 }
 
 // Get returns the plugin given the specified name and requested implementation.
 func Get(name, imp string) (*Plugin, error) {
-	pl, err := get(name)
+	return getImplementing(name, imp, true)
+}
+
+// Lookup returns the plugin given the specified name and requested
+// implementation, like Get, but fails right away if the plugin can't be
+// found instead of waiting for it to appear.
+func Lookup(name, imp string) (*Plugin, error) {
+	return getImplementing(name, imp, false)
+}
+
+func getImplementing(name, imp string, retry bool) (*Plugin, error) {
+	pl, err := get(name, retry)
 	if err != nil {
 		return nil, err
 	}
@@ -180,3 +192,9 @@ func Get(name, imp string) (*Plugin, error) {
 func Handle(iface string, fn func(string, *Client)) {
 	extpointHandlers[iface] = fn
 }
+
+// HandleActivation adds a function called with the name and the manifest of
+// each plugin once it's activated.
+func HandleActivation(fn func(string, *Manifest)) {
+	activationHandlers = append(activationHandlers, fn)
+}
@@ -2,6 +2,7 @@
 package streamformatter
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -92,6 +93,21 @@ func (sf *StreamFormatter) FormatProgress(id, action string, progress *jsonmessa
 	return []byte(action + " " + progress.String() + endl)
 }
 
+// FormatBuildStep formats the record of a build step. Without JSON, the step
+// is formatted the way the builder prints it.
+func (sf *StreamFormatter) FormatBuildStep(step *jsonmessage.JSONBuildStep) []byte {
+	if sf.json {
+		b, err := json.Marshal(&jsonmessage.JSONMessage{BuildStep: step})
+		if err != nil {
+			return sf.FormatError(err)
+		}
+		return append(b, streamNewlineBytes...)
+	}
+	var buf bytes.Buffer
+	step.Display(&buf)
+	return buf.Bytes()
+}
+
 // StdoutFormatter is a streamFormatter that writes to the standard output.
 type StdoutFormatter struct {
 	io.Writer
@@ -91,3 +91,37 @@ func TestJSONFormatProgress(t *testing.T) {
 		t.Fatal("Original progress not equals progress from FormatProgress")
 	}
 }
+
+func TestFormatBuildStep(t *testing.T) {
+	sf := NewStreamFormatter()
+	step := &jsonmessage.JSONBuildStep{Step: 2, Instruction: "run  echo hi", Message: "RUN echo hi"}
+	if res := sf.FormatBuildStep(step); string(res) != "Step 2 : RUN echo hi\n" {
+		t.Fatalf("%q", res)
+	}
+	step.Done = true
+	step.ImageID = "sha256:0123456789abcdef0123"
+	if res := sf.FormatBuildStep(step); string(res) != " ---> 0123456789ab\n" {
+		t.Fatalf("%q", res)
+	}
+}
+
+func TestJSONFormatBuildStep(t *testing.T) {
+	sf := NewJSONStreamFormatter()
+	exitCode := 0
+	step := &jsonmessage.JSONBuildStep{
+		Step:        2,
+		Instruction: "RUN echo hi",
+		Message:     "RUN echo hi",
+		Done:        true,
+		Cache:       "miss",
+		ImageID:     "sha256:0123",
+		ContainerID: "4567",
+		Duration:    1000,
+		ExitCode:    &exitCode,
+	}
+	res := sf.FormatBuildStep(step)
+	expected := `{"buildStep":{"step":2,"instruction":"RUN echo hi","message":"RUN echo hi","done":true,"cache":"miss","imageId":"sha256:0123","containerId":"4567","duration":1000,"exitCode":0}}`
+	if string(res) != expected+"\r\n" {
+		t.Fatalf("%q", res)
+	}
+}
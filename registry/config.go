@@ -98,7 +98,7 @@ func NewServiceConfig(options *Options) *ServiceConfig {
 	//
 	// TODO: should we deprecate this once it is easier for people to set up a TLS registry or change
 	// daemon flags on boot2docker?
-	options.InsecureRegistries.Set("127.0.0.0/8")
+	insecureRegistries := append(options.InsecureRegistries.GetAll(), "127.0.0.0/8")
 
 	config := &ServiceConfig{
 		InsecureRegistryCIDRs: make([]*netIPNet, 0),
@@ -108,7 +108,7 @@ func NewServiceConfig(options *Options) *ServiceConfig {
 		Mirrors: options.Mirrors.GetAll(),
 	}
 	// Split --insecure-registry into CIDR and registry-specific settings.
-	for _, r := range options.InsecureRegistries.GetAll() {
+	for _, r := range insecureRegistries {
 		// Check if CIDR was passed to --insecure-registry
 		_, ipnet, err := net.ParseCIDR(r)
 		if err == nil {
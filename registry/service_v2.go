@@ -15,7 +15,7 @@ func (s *Service) lookupV2Endpoints(repoName reference.Named) (endpoints []APIEn
 	nameString := repoName.Name()
 	if strings.HasPrefix(nameString, DefaultNamespace+"/") {
 		// v2 mirrors
-		for _, mirror := range s.Config.Mirrors {
+		for _, mirror := range s.ServiceConfig().Mirrors {
 			mirrorTLSConfig, err := s.tlsConfigForMirror(mirror)
 			if err != nil {
 				return nil, err
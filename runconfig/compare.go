@@ -20,6 +20,7 @@ func Compare(a, b *Config) bool {
 		len(a.Labels) != len(b.Labels) ||
 		len(a.ExposedPorts) != len(b.ExposedPorts) ||
 		a.Entrypoint.Len() != b.Entrypoint.Len() ||
+		a.Shell.Len() != b.Shell.Len() ||
 		len(a.Volumes) != len(b.Volumes) {
 		return false
 	}
@@ -54,10 +55,37 @@ func Compare(a, b *Config) bool {
 			return false
 		}
 	}
+	aShell := a.Shell.Slice()
+	bShell := b.Shell.Slice()
+	for i := 0; i < len(aShell); i++ {
+		if aShell[i] != bShell[i] {
+			return false
+		}
+	}
 	for key := range a.Volumes {
 		if _, exists := b.Volumes[key]; !exists {
 			return false
 		}
 	}
+	return compareHealthConfig(a.Healthcheck, b.Healthcheck)
+}
+
+// compareHealthConfig returns true if both health configurations are
+// equivalent, including when neither is set.
+func compareHealthConfig(a, b *HealthConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Interval != b.Interval ||
+		a.Timeout != b.Timeout ||
+		a.Retries != b.Retries ||
+		len(a.Test) != len(b.Test) {
+		return false
+	}
+	for i := range a.Test {
+		if a.Test[i] != b.Test[i] {
+			return false
+		}
+	}
 	return true
 }
@@ -2,8 +2,10 @@ package runconfig
 
 import (
 	"testing"
+	"time"
 
 	"github.com/docker/docker/pkg/nat"
+	"github.com/docker/docker/pkg/stringutils"
 )
 
 func TestMerge(t *testing.T) {
@@ -81,3 +83,61 @@ func TestMerge(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeHealthcheck(t *testing.T) {
+	configImage := &Config{
+		Healthcheck: &HealthConfig{
+			Test:     []string{"CMD-SHELL", "true"},
+			Interval: 5 * time.Second,
+			Retries:  2,
+		},
+	}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Healthcheck != configImage.Healthcheck {
+		t.Fatalf("Expected the image healthcheck to be inherited, got %v", configUser.Healthcheck)
+	}
+
+	configUser = &Config{
+		Healthcheck: &HealthConfig{
+			Timeout: 3 * time.Second,
+		},
+	}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	hc := configUser.Healthcheck
+	if len(hc.Test) != 2 || hc.Test[1] != "true" {
+		t.Fatalf("Expected the image test to be inherited, got %v", hc.Test)
+	}
+	if hc.Interval != 5*time.Second || hc.Timeout != 3*time.Second || hc.Retries != 2 {
+		t.Fatalf("Unexpected merged healthcheck: %+v", hc)
+	}
+}
+
+func TestMergeShell(t *testing.T) {
+	configImage := &Config{
+		Shell: stringutils.NewStrSlice("/bin/bash", "-c"),
+	}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Shell != configImage.Shell {
+		t.Fatalf("Expected the image shell to be inherited, got %v", configUser.Shell)
+	}
+
+	configUser = &Config{
+		Shell: stringutils.NewStrSlice("/bin/zsh", "-c"),
+	}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if shell := configUser.Shell.Slice(); len(shell) != 2 || shell[0] != "/bin/zsh" {
+		t.Fatalf("Expected the user shell to be kept, got %v", shell)
+	}
+}
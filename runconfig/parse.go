@@ -1,7 +1,10 @@
 package runconfig
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"strconv"
 	"strings"
 
@@ -337,6 +340,11 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		return nil, nil, cmd, err
 	}
 
+	securityOpts, err := parseSecurityOpts(flSecurityOpt.GetAll())
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
 	resources := Resources{
 		CgroupParent:      *flCgroupParent,
 		Memory:            flMemory,
@@ -407,7 +415,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		CapDrop:        stringutils.NewStrSlice(flCapDrop.GetAll()...),
 		GroupAdd:       flGroupAdd.GetAll(),
 		RestartPolicy:  restartPolicy,
-		SecurityOpt:    flSecurityOpt.GetAll(),
+		SecurityOpt:    securityOpts,
 		ReadonlyRootfs: *flReadonlyRootfs,
 		LogConfig:      LogConfig{Type: *flLoggingDriver, Config: loggingOpts},
 		VolumeDriver:   *flVolumeDriver,
@@ -463,6 +471,36 @@ func parseLoggingOpts(loggingDriver string, loggingOpts []string) (map[string]st
 	return loggingOptsMap, nil
 }
 
+// parseSecurityOpts takes the --security-opt values and replaces the path of
+// a seccomp profile with the profile itself, so the daemon receives its content.
+func parseSecurityOpts(securityOpts []string) ([]string, error) {
+	for key, opt := range securityOpts {
+		var path string
+		switch {
+		case strings.HasPrefix(opt, "seccomp="):
+			path = strings.TrimPrefix(opt, "seccomp=")
+		case strings.HasPrefix(opt, "seccomp:"):
+			path = strings.TrimPrefix(opt, "seccomp:")
+		default:
+			continue
+		}
+		if path == "unconfined" {
+			continue
+		}
+		f, err := ioutil.ReadFile(path)
+		if err != nil {
+			return securityOpts, fmt.Errorf("Opening seccomp profile (%s) failed: %v", path, err)
+		}
+		b := bytes.NewBuffer(nil)
+		if err := json.Compact(b, f); err != nil {
+			return securityOpts, fmt.Errorf("Compacting json for seccomp profile (%s) failed: %v", path, err)
+		}
+		securityOpts[key] = fmt.Sprintf("seccomp=%s", b.Bytes())
+	}
+
+	return securityOpts, nil
+}
+
 // ParseRestartPolicy returns the parsed policy or an error indicating what is incorrect
 func ParseRestartPolicy(policy string) (RestartPolicy, error) {
 	p := RestartPolicy{}
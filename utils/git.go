@@ -14,30 +14,62 @@ import (
 	"github.com/docker/docker/pkg/urlutil"
 )
 
+// GitCommitLabel is the label of the images built from a git repository that
+// records the commit they were built from.
+const GitCommitLabel = "com.docker.build.git.commit"
+
+// GitRepo is a git repository cloned for a build.
+type GitRepo struct {
+	Root       string // directory of the clone
+	ContextDir string // directory of the build context, Root or one of its subdirectories
+	Commit     string // commit checked out
+}
+
+// Remove removes the clone of the repository.
+func (r *GitRepo) Remove() error {
+	return os.RemoveAll(r.Root)
+}
+
 // GitClone clones a repository into a newly created directory which
-// will be under "docker-build-git"
-func GitClone(remoteURL string) (string, error) {
+// will be under "docker-build-git". The fragment of the URL selects the
+// ref to check out and the subdirectory to use as build context, as in
+// `https://host/repo.git#ref:subdir`.
+func GitClone(remoteURL string) (*GitRepo, error) {
 	if !urlutil.IsGitTransport(remoteURL) {
 		remoteURL = "https://" + remoteURL
 	}
-	root, err := ioutil.TempDir("", "docker-build-git")
+	u, err := url.Parse(remoteURL)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	u, err := url.Parse(remoteURL)
+	root, err := ioutil.TempDir("", "docker-build-git")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	repo := &GitRepo{Root: root}
 
 	fragment := u.Fragment
 	clone := cloneArgs(u, root)
 
 	if output, err := git(clone...); err != nil {
-		return "", fmt.Errorf("Error trying to use git: %s (%s)", err, output)
+		repo.Remove()
+		return nil, fmt.Errorf("Error trying to use git: %s (%s)", err, output)
 	}
 
-	return checkoutGit(fragment, root)
+	if repo.ContextDir, err = checkoutGit(fragment, root); err != nil {
+		repo.Remove()
+		return nil, err
+	}
+
+	output, err := gitWithinDir(root, "rev-parse", "HEAD")
+	if err != nil {
+		repo.Remove()
+		return nil, fmt.Errorf("Error trying to use git: %s (%s)", err, output)
+	}
+	repo.Commit = strings.TrimSpace(string(output))
+
+	return repo, nil
 }
 
 func cloneArgs(remoteURL *url.URL, root string) []string {
@@ -65,8 +97,23 @@ func cloneArgs(remoteURL *url.URL, root string) []string {
 func checkoutGit(fragment, root string) (string, error) {
 	refAndDir := strings.SplitN(fragment, ":", 2)
 
-	if len(refAndDir[0]) != 0 {
-		if output, err := gitWithinDir(root, "checkout", refAndDir[0]); err != nil {
+	if ref := refAndDir[0]; len(ref) != 0 {
+		// Refs can't start with a dash, which git would take for an option.
+		if strings.HasPrefix(ref, "-") {
+			return "", fmt.Errorf("Error trying to use git: invalid ref %q", ref)
+		}
+		if _, err := gitWithinDir(root, "checkout", ref); err != nil {
+			// The ref may not have been cloned, like a commit that no
+			// branch or tag of the remote points to.
+			if output, err := gitWithinDir(root, "fetch", "origin", "--", ref); err != nil {
+				return "", fmt.Errorf("Error trying to use git: %s (%s)", err, output)
+			}
+			if output, err := gitWithinDir(root, "checkout", "FETCH_HEAD"); err != nil {
+				return "", fmt.Errorf("Error trying to use git: %s (%s)", err, output)
+			}
+		}
+		// The submodules were cloned for the default branch.
+		if output, err := gitInDir(root, "submodule", "update", "--init", "--recursive"); err != nil {
 			return "", fmt.Errorf("Error trying to use git: %s (%s)", err, output)
 		}
 	}
@@ -95,6 +142,14 @@ func gitWithinDir(dir string, args ...string) ([]byte, error) {
 	return git(append(a, args...)...)
 }
 
+// gitInDir runs git from dir, for the commands that must run in the work
+// tree.
+func gitInDir(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
 func git(args ...string) ([]byte, error) {
 	return exec.Command("git", args...).CombinedOutput()
 }
@@ -161,6 +161,8 @@ func TestCheckoutGit(t *testing.T) {
 		{"test", "FROM scratch\nEXPOSE 3000", false},
 		{"test:", "FROM scratch\nEXPOSE 3000", false},
 		{"test:subdir", "FROM busybox\nEXPOSE 5000", false},
+		{"--upload-pack=touch", "", true},
+		{"-b:subdir", "", true},
 	}
 
 	for _, c := range cases {
@@ -184,3 +186,66 @@ func TestCheckoutGit(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckoutGitFetch(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-build-git-fetch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	origin := filepath.Join(root, "origin")
+	if _, err := git("init", origin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "config", "user.email", "test@docker.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "config", "user.name", "Docker test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(origin, "Dockerfile"), []byte("FROM scratch"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "commit", "-m", "First commit"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A ref outside of the branches and tags isn't cloned.
+	if err := ioutil.WriteFile(filepath.Join(origin, "Dockerfile"), []byte("FROM scratch\nEXPOSE 8080"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "commit", "-am", "Unadvertised commit"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "update-ref", "refs/pull/1/head", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitWithinDir(origin, "reset", "--hard", "HEAD~1"); err != nil {
+		t.Fatal(err)
+	}
+
+	clone := filepath.Join(root, "clone")
+	if output, err := git("clone", origin, clone); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+
+	r, err := checkoutGit("refs/pull/1/head", clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(r, "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "FROM scratch\nEXPOSE 8080" {
+		t.Fatalf("Expected the Dockerfile of the fetched ref, got %q", b)
+	}
+
+	if _, err := checkoutGit("refs/pull/2/head", clone); err == nil {
+		t.Fatal("Expected an error for a missing ref")
+	}
+}
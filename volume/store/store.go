@@ -39,9 +39,26 @@ func (s *VolumeStore) remove(name string) {
 
 // VolumeStore is a struct that stores the list of volumes available and keeps track of their usage counts
 type VolumeStore struct {
-	vols       map[string]*volumeCounter
-	locks      *locker.Locker
-	globalLock sync.Mutex
+	vols        map[string]*volumeCounter
+	locks       *locker.Locker
+	globalLock  sync.Mutex
+	eventLogger EventLogger
+}
+
+// EventLogger is called with the name of a volume, the action performed on
+// it and the attributes of the volume.
+type EventLogger func(name, action string, attributes map[string]string)
+
+// SetEventLogger sets the function called when the store creates or removes
+// a volume.
+func (s *VolumeStore) SetEventLogger(fn EventLogger) {
+	s.eventLogger = fn
+}
+
+func (s *VolumeStore) logEvent(v volume.Volume, action string) {
+	if s.eventLogger != nil {
+		s.eventLogger(v.Name(), action, map[string]string{"driver": v.DriverName()})
+	}
 }
 
 // volumeCounter keeps track of references to a volume
@@ -89,6 +106,7 @@ func (s *VolumeStore) Create(name, driverName string, opts map[string]string) (v
 	}
 
 	s.set(name, &volumeCounter{v, 0})
+	s.logEvent(v, "create")
 	return v, nil
 }
 
@@ -130,6 +148,7 @@ func (s *VolumeStore) Remove(v volume.Volume) error {
 	}
 
 	s.remove(name)
+	s.logEvent(vc.Volume, "destroy")
 	return nil
 }
 
@@ -152,3 +152,30 @@ func TestFilterByDriver(t *testing.T) {
 		t.Fatalf("Expected 1 volume, got %v, %v", len(l), l)
 	}
 }
+
+func TestEventLogger(t *testing.T) {
+	volumedrivers.Register(vt.FakeDriver{}, "fake")
+	s := New()
+
+	var logged []string
+	s.SetEventLogger(func(name, action string, attributes map[string]string) {
+		logged = append(logged, action+" "+name+" "+attributes["driver"])
+	})
+
+	v, err := s.Create("fake1", "fake", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// getting an existing volume doesn't create it again
+	if _, err := s.Create("fake1", "fake", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove(v); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"create fake1 fake", "destroy fake1 fake"}
+	if len(logged) != len(expected) || logged[0] != expected[0] || logged[1] != expected[1] {
+		t.Fatalf("Expected events %v, got %v", expected, logged)
+	}
+}